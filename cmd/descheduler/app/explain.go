@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/cmd/descheduler/app/options"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/client"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+)
+
+// NewExplainCommand creates a *cobra.Command which tells why a pod is or is not evictable
+func NewExplainCommand(out io.Writer) *cobra.Command {
+	s, err := options.NewDeschedulerServer()
+	if err != nil {
+		klog.ErrorS(err, "unable to initialize server")
+	}
+
+	var namespace, podName string
+
+	cmd := &cobra.Command{
+		Use:   "explain",
+		Short: "Explain why a pod is or is not evictable",
+		Long: `Builds every profile of a descheduler policy against the cluster and runs all the Filter
+and PreEvictionFilter plugins of each profile against a pod, without evicting anything.
+The verdict of every plugin is printed, together with each named check of the plugins
+reporting them, e.g. the ownerRefs, localStorage, priorityThreshold, minReplicas and nodeFit
+checks of the DefaultEvictor. Whether a strategy selects the pod in the first place is not covered.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if s.PolicyConfigFile == "" {
+				return fmt.Errorf("--policy-config-file needs to be set")
+			}
+			if podName == "" {
+				return fmt.Errorf("--pod needs to be set")
+			}
+			descheduler.SetupPlugins()
+
+			rsclient, err := client.CreateClient(s.ClientConnection, "descheduler")
+			if err != nil {
+				return err
+			}
+			deschedulerPolicy, err := descheduler.LoadPolicyConfig(s.PolicyConfigFile, rsclient, pluginregistry.PluginRegistry)
+			if err != nil {
+				return err
+			}
+			if deschedulerPolicy == nil {
+				return fmt.Errorf("deschedulerPolicy is nil")
+			}
+
+			pod, explanations, err := descheduler.ExplainPod(cmd.Context(), rsclient, deschedulerPolicy, namespace, podName)
+			if err != nil {
+				return err
+			}
+			return descheduler.WriteExplanation(out, pod, explanations)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&s.ClientConnection.Kubeconfig, "kubeconfig", s.ClientConnection.Kubeconfig, "File with kube configuration. Deprecated, use client-connection-kubeconfig instead.")
+	flags.StringVar(&s.ClientConnection.Kubeconfig, "client-connection-kubeconfig", s.ClientConnection.Kubeconfig, "File path to kube configuration for interacting with kubernetes apiserver.")
+	flags.Float32Var(&s.ClientConnection.QPS, "client-connection-qps", s.ClientConnection.QPS, "QPS to use for interacting with kubernetes apiserver.")
+	flags.Int32Var(&s.ClientConnection.Burst, "client-connection-burst", s.ClientConnection.Burst, "Burst to use for interacting with kubernetes apiserver.")
+	flags.StringVar(&s.PolicyConfigFile, "policy-config-file", s.PolicyConfigFile, "File with descheduler policy configuration.")
+	flags.StringVar(&namespace, "namespace", "default", "Namespace of the pod.")
+	flags.StringVar(&podName, "pod", podName, "Name of the pod.")
+	return cmd
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler"
+)
+
+// NewMigratePolicyCommand creates a *cobra.Command which converts a v1alpha1 policy into a v1alpha2 policy
+func NewMigratePolicyCommand(out io.Writer) *cobra.Command {
+	var inFile, outFile string
+
+	cmd := &cobra.Command{
+		Use:   "migrate-policy",
+		Short: "Convert a v1alpha1 descheduler policy into a v1alpha2 policy",
+		Long: `Converts the strategies of a deprecated v1alpha1 descheduler policy into the profiles
+of an equivalent v1alpha2 policy, the same way the descheduler does when loading a v1alpha1 policy.
+Every profile gets an explicit DefaultEvictor configuration carrying the top level options
+of the v1alpha1 policy, e.g. evictLocalStoragePods, and the priority threshold of the strategy.
+Fields set to their default values are left out.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if inFile == "" {
+				return fmt.Errorf("--in needs to be set")
+			}
+			descheduler.SetupPlugins()
+
+			policy, err := descheduler.MigratePolicyFile(inFile)
+			if err != nil {
+				return err
+			}
+
+			if outFile == "" {
+				return descheduler.WritePolicy(out, policy)
+			}
+			f, err := os.Create(outFile)
+			if err != nil {
+				return fmt.Errorf("failed to create policy file %q: %v", outFile, err)
+			}
+			defer f.Close()
+			if err := descheduler.WritePolicy(f, policy); err != nil {
+				return err
+			}
+			return f.Close()
+		},
+	}
+
+	cmd.Flags().StringVar(&inFile, "in", inFile, "File with a v1alpha1 descheduler policy configuration.")
+	cmd.Flags().StringVar(&outFile, "out", outFile, "File to write the v1alpha2 policy to. Defaults to the standard output.")
+	return cmd
+}
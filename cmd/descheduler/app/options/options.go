@@ -26,6 +26,7 @@ import (
 	clientset "k8s.io/client-go/kubernetes"
 	componentbaseconfig "k8s.io/component-base/config"
 	componentbaseoptions "k8s.io/component-base/config/options"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 	"github.com/amit3512/descheduler_policy_master/pkg/apis/componentconfig"
 	"github.com/amit3512/descheduler_policy_master/pkg/apis/componentconfig/v1alpha1"
 	deschedulerscheme "github.com/amit3512/descheduler_policy_master/pkg/descheduler/scheme"
@@ -43,11 +44,24 @@ const (
 type DeschedulerServer struct {
 	componentconfig.DeschedulerConfiguration
 
-	Client         clientset.Interface
-	EventClient    clientset.Interface
+	Client      clientset.Interface
+	EventClient clientset.Interface
+	// MetricsClient reads the actual resource usage of nodes and pods
+	MetricsClient  metricsclient.Interface
 	SecureServing  *apiserveroptions.SecureServingOptionsWithLoopback
 	DisableMetrics bool
 	EnableHTTP2    bool
+	// EvictionPlanning ranks the pods proposed by all plugins before any eviction
+	EvictionPlanning bool
+	// DryRunReport is a file the report of each dry run cycle is written to
+	DryRunReport       string
+	DryRunReportFormat string
+	// DryRunReschedule recreates the pods evicted in the dry run mode on other nodes
+	DryRunReschedule bool
+	// DryRunCycles is the number of consecutive cycles simulated in every dry run
+	DryRunCycles int
+	// EvictionSteeringTTL is how long the nodes pods are evicted from with a target node hint stay tainted
+	EvictionSteeringTTL time.Duration
 }
 
 // NewDeschedulerServer creates a new DeschedulerServer with default parameters
@@ -63,6 +77,8 @@ func NewDeschedulerServer() (*DeschedulerServer, error) {
 	return &DeschedulerServer{
 		DeschedulerConfiguration: *cfg,
 		SecureServing:            secureServing,
+		DryRunReportFormat:       "json",
+		DryRunCycles:             1,
 	}, nil
 }
 
@@ -97,6 +113,12 @@ func (rs *DeschedulerServer) AddFlags(fs *pflag.FlagSet) {
 	fs.Int32Var(&rs.ClientConnection.Burst, "client-connection-burst", rs.ClientConnection.Burst, "Burst to use for interacting with kubernetes apiserver.")
 	fs.StringVar(&rs.PolicyConfigFile, "policy-config-file", rs.PolicyConfigFile, "File with descheduler policy configuration.")
 	fs.BoolVar(&rs.DryRun, "dry-run", rs.DryRun, "Execute descheduler in dry run mode.")
+	fs.StringVar(&rs.DryRunReport, "dry-run-report", rs.DryRunReport, "File to write a report of the pods evicted in the dry run mode and of the node utilization before and after the evictions to. Overwritten in every descheduling cycle.")
+	fs.StringVar(&rs.DryRunReportFormat, "dry-run-report-format", rs.DryRunReportFormat, "Format of the dry run report, one of json, markdown.")
+	fs.BoolVar(&rs.DryRunReschedule, "dry-run-reschedule", rs.DryRunReschedule, "Simulate the rescheduling of the pods evicted in the dry run mode. A replacement of each evicted pod managed by a controller is placed on the least allocated node it fits, so plugins running later in the same cycle see where the pod lands.")
+	fs.IntVar(&rs.DryRunCycles, "dry-run-cycles", rs.DryRunCycles, "Number of consecutive descheduling cycles simulated in the dry run mode against the same evolving cluster. With more than one cycle the rescheduling of evicted pods is simulated, and pods or owners evicted repeatedly and nodes flapping between under and over utilization are reported.")
+	fs.DurationVar(&rs.EvictionSteeringTTL, "eviction-steering-ttl", rs.EvictionSteeringTTL, "Duration the node a pod is evicted from is tainted PreferNoSchedule for, when the plugin evicting the pod chose a target node for its replacement. The taint keeps the replacement from being scheduled back to the node and is removed by the first descheduling cycle after it expired. Disabled with 0, must be used with --descheduling-interval. Requires the permission to update the nodes.")
+	fs.BoolVar(&rs.EvictionPlanning, "eviction-planning", rs.EvictionPlanning, "Collect the pods proposed for eviction by all plugins of all profiles and evict them by their rank within the eviction limits, instead of evicting in the order plugins run.")
 	fs.BoolVar(&rs.DisableMetrics, "disable-metrics", rs.DisableMetrics, "Disables metrics. The metrics are by default served through https://localhost:10258/metrics. Secure address, resp. port can be changed through --bind-address, resp. --secure-port flags.")
 	fs.StringVar(&rs.Tracing.CollectorEndpoint, "otel-collector-endpoint", "", "Set this flag to the OpenTelemetry Collector Service Address")
 	fs.StringVar(&rs.Tracing.TransportCert, "otel-transport-ca-cert", "", "Path of the CA Cert that can be used to generate the client Certificate for establishing secure connection to the OTEL in gRPC mode")
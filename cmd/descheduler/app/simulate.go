@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler"
+)
+
+// NewSimulateCommand creates a *cobra.Command which runs the descheduler against a cluster snapshot
+func NewSimulateCommand(out io.Writer) *cobra.Command {
+	var snapshotFile, policyConfigFile string
+	var failOnOscillation bool
+	cycles := 1
+
+	cmd := &cobra.Command{
+		Use:   "simulate",
+		Short: "Simulate a descheduling cycle against a cluster snapshot",
+		Long: `Runs the profiles of a descheduler policy against a snapshot of a cluster
+and prints the pods which would be evicted. The snapshot is either a file exported by
+"descheduler snapshot" or a YAML or JSON List of Nodes, Pods, Namespaces, PriorityClasses
+and PodDisruptionBudgets. No API server is contacted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if snapshotFile == "" || policyConfigFile == "" {
+				return fmt.Errorf("both --snapshot and --policy need to be set")
+			}
+			descheduler.SetupPlugins()
+
+			objects, err := descheduler.LoadSnapshot(snapshotFile)
+			if err != nil {
+				return err
+			}
+			result, err := descheduler.Simulate(cmd.Context(), policyConfigFile, objects, cycles)
+			if err != nil {
+				return err
+			}
+			if err := printSimulatedEvictions(out, result.Evictions, cycles > 1); err != nil {
+				return err
+			}
+			if result.Oscillations == nil {
+				return nil
+			}
+			if err := printOscillations(out, result.Oscillations); err != nil {
+				return err
+			}
+			if failOnOscillation && result.Oscillations.Oscillating() {
+				return fmt.Errorf("the policy oscillates over %d cycles", cycles)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&snapshotFile, "snapshot", snapshotFile, "File with a List of cluster objects to simulate against.")
+	cmd.Flags().StringVar(&policyConfigFile, "policy", policyConfigFile, "File with descheduler policy configuration.")
+	cmd.Flags().IntVar(&cycles, "cycles", cycles, "Number of consecutive descheduling cycles to simulate. With more than one cycle evicted pods are rescheduled on other nodes, and pods or owners evicted repeatedly and nodes flapping between under and over utilization are reported.")
+	cmd.Flags().BoolVar(&failOnOscillation, "fail-on-oscillation", failOnOscillation, "Exit with an error when a repeated eviction or a flapping node is found.")
+	return cmd
+}
+
+func printSimulatedEvictions(out io.Writer, simulatedEvictions []descheduler.SimulatedEviction, multipleCycles bool) error {
+	if len(simulatedEvictions) == 0 {
+		_, err := fmt.Fprintln(out, "No pods would be evicted")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	if multipleCycles {
+		fmt.Fprintln(w, "CYCLE\tPROFILE\tPLUGIN\tNAMESPACE\tPOD\tNODE\tDESTINATION")
+	} else {
+		fmt.Fprintln(w, "PROFILE\tPLUGIN\tNAMESPACE\tPOD\tNODE")
+	}
+	for _, eviction := range simulatedEvictions {
+		if multipleCycles {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", eviction.Cycle, eviction.Profile, eviction.Plugin, eviction.Pod.Namespace, eviction.Pod.Name, eviction.Pod.Spec.NodeName, eviction.Destination)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", eviction.Profile, eviction.Plugin, eviction.Pod.Namespace, eviction.Pod.Name, eviction.Pod.Spec.NodeName)
+		}
+	}
+	fmt.Fprintf(w, "\n%d pod(s) would be evicted\n", len(simulatedEvictions))
+	return w.Flush()
+}
+
+func printOscillations(out io.Writer, oscillations *descheduler.OscillationReport) error {
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w)
+	if !oscillations.Oscillating() {
+		fmt.Fprintf(w, "No oscillation detected over %d cycles\n", oscillations.Cycles)
+		return w.Flush()
+	}
+	for _, eviction := range oscillations.RepeatedPodEvictions {
+		fmt.Fprintf(w, "Pod %s evicted in cycles %v\n", eviction.Name, eviction.Cycles)
+	}
+	for _, eviction := range oscillations.RepeatedOwnerEvictions {
+		fmt.Fprintf(w, "Pods of %s evicted in cycles %v\n", eviction.Name, eviction.Cycles)
+	}
+	for _, node := range oscillations.FlappingNodes {
+		fmt.Fprintf(w, "Node %s flaps in %s/%s: %s\n", node.Node, node.Profile, node.Plugin, strings.Join(node.Classes, " -> "))
+	}
+	return w.Flush()
+}
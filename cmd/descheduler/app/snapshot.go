@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/cmd/descheduler/app/options"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/client"
+)
+
+// NewSnapshotCommand creates a *cobra.Command which exports the cluster objects the descheduler works with
+func NewSnapshotCommand(out io.Writer) *cobra.Command {
+	s, err := options.NewDeschedulerServer()
+	if err != nil {
+		klog.ErrorS(err, "unable to initialize server")
+	}
+
+	var outFile string
+	var anonymize bool
+
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Export a snapshot of the cluster",
+		Long: `Exports the Nodes, Pods, Namespaces, PriorityClasses and PodDisruptionBudgets of a cluster
+into a single versioned file which can be passed to "descheduler simulate" to reproduce
+descheduling decisions offline. Managed fields are always dropped. With --anonymize the names,
+labels, images and other identifying fields are replaced consistently across all the objects.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rsclient, err := client.CreateClient(s.ClientConnection, "descheduler")
+			if err != nil {
+				return err
+			}
+
+			snapshot, err := descheduler.TakeSnapshot(cmd.Context(), rsclient, anonymize)
+			if err != nil {
+				return err
+			}
+
+			if outFile == "" {
+				return descheduler.WriteSnapshot(out, snapshot)
+			}
+			f, err := os.Create(outFile)
+			if err != nil {
+				return fmt.Errorf("failed to create snapshot file %q: %v", outFile, err)
+			}
+			defer f.Close()
+			if err := descheduler.WriteSnapshot(f, snapshot); err != nil {
+				return err
+			}
+			return f.Close()
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&s.ClientConnection.Kubeconfig, "kubeconfig", s.ClientConnection.Kubeconfig, "File with kube configuration. Deprecated, use client-connection-kubeconfig instead.")
+	flags.StringVar(&s.ClientConnection.Kubeconfig, "client-connection-kubeconfig", s.ClientConnection.Kubeconfig, "File path to kube configuration for interacting with kubernetes apiserver.")
+	flags.Float32Var(&s.ClientConnection.QPS, "client-connection-qps", s.ClientConnection.QPS, "QPS to use for interacting with kubernetes apiserver.")
+	flags.Int32Var(&s.ClientConnection.Burst, "client-connection-burst", s.ClientConnection.Burst, "Burst to use for interacting with kubernetes apiserver.")
+	flags.StringVar(&outFile, "out", outFile, "File to write the snapshot to. Defaults to the standard output.")
+	flags.BoolVar(&anonymize, "anonymize", anonymize, "Replace names, labels and other identifying fields of the exported objects.")
+	return cmd
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler"
+)
+
+// NewValidateCommand creates a *cobra.Command which reports the problems of a descheduler policy
+func NewValidateCommand(out io.Writer) *cobra.Command {
+	var policyConfigFile string
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate a descheduler policy",
+		Long: `Decodes and validates a descheduler policy and builds all its plugins the same way
+the descheduler does, without contacting any API server. Every problem is printed with
+the line, the path, the profile and the plugin it was found in, including unknown fields
+and plugins enabled without a pluginConfig. The command fails when any problem is found.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if policyConfigFile == "" {
+				return fmt.Errorf("--policy-config-file needs to be set")
+			}
+			descheduler.SetupPlugins()
+
+			problems, err := descheduler.ValidatePolicyFile(policyConfigFile)
+			if err != nil {
+				return err
+			}
+			if len(problems) == 0 {
+				_, err := fmt.Fprintf(out, "%s is valid\n", policyConfigFile)
+				return err
+			}
+			for _, problem := range problems {
+				fmt.Fprintf(out, "%s: %s\n", policyConfigFile, problem)
+			}
+			return fmt.Errorf("found %d problem(s) in %s", len(problems), policyConfigFile)
+		},
+	}
+
+	cmd.Flags().StringVar(&policyConfigFile, "policy-config-file", policyConfigFile, "File with descheduler policy configuration.")
+	return cmd
+}
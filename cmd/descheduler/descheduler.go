@@ -27,6 +27,11 @@ func main() {
 	out := os.Stdout
 	cmd := app.NewDeschedulerCommand(out)
 	cmd.AddCommand(app.NewVersionCommand())
+	cmd.AddCommand(app.NewSimulateCommand(out))
+	cmd.AddCommand(app.NewSnapshotCommand(out))
+	cmd.AddCommand(app.NewValidateCommand(out))
+	cmd.AddCommand(app.NewMigratePolicyCommand(out))
+	cmd.AddCommand(app.NewExplainCommand(out))
 
 	code := cli.Run(cmd)
 	os.Exit(code)
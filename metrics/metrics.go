@@ -66,11 +66,20 @@ var (
 			Buckets:        []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100},
 		}, []string{"strategy", "profile"})
 
+	PolicyReloads = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Subsystem:      DeschedulerSubsystem,
+			Name:           "policy_reloads",
+			Help:           "Number of reloads of the policy file, by the result. 'error' result means the policy could not be read or validated and the previous policy is kept",
+			StabilityLevel: metrics.ALPHA,
+		}, []string{"result"})
+
 	metricsList = []metrics.Registerable{
 		PodsEvicted,
 		buildInfo,
 		DeschedulerLoopDuration,
 		DeschedulerStrategyDuration,
+		PolicyReloads,
 	}
 )
 
@@ -27,6 +27,7 @@ import (
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/klog/v2"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 	"github.com/amit3512/descheduler_policy_master/pkg/api"
 	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
 	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
@@ -68,12 +69,35 @@ func (ei *evictorImpl) Evict(ctx context.Context, pod *v1.Pod, opts evictions.Ev
 	return ei.podEvictor.EvictPod(ctx, pod, opts)
 }
 
+// CanEvict checks the pods can be evicted within the limits
+func (ei *evictorImpl) CanEvict(pods []*v1.Pod) error {
+	return ei.podEvictor.CanEvict(pods)
+}
+
+// Planning tells whether the pods are only proposed for eviction, never with the conversion
+func (ei *evictorImpl) Planning() bool {
+	return false
+}
+
+// sorterImpl implements the Sorter interface. v1alpha1 has no sort
+// plugins so the order of pods is kept as is.
+type sorterImpl struct{}
+
+var _ frameworktypes.Sorter = &sorterImpl{}
+
+// PreSort keeps the order of pods
+func (si *sorterImpl) PreSort(pods []*v1.Pod) {}
+
+// Sort keeps the order of pods
+func (si *sorterImpl) Sort(pods []*v1.Pod) {}
+
 // handleImpl implements the framework handle which gets passed to plugins
 type handleImpl struct {
 	clientSet                 clientset.Interface
 	getPodsAssignedToNodeFunc podutil.GetPodsAssignedToNodeFunc
 	sharedInformerFactory     informers.SharedInformerFactory
 	evictor                   *evictorImpl
+	sorter                    *sorterImpl
 }
 
 var _ frameworktypes.Handle = &handleImpl{}
@@ -83,6 +107,11 @@ func (hi *handleImpl) ClientSet() clientset.Interface {
 	return hi.clientSet
 }
 
+// MetricsClientSet retrieves the metrics.k8s.io client set, none is used for the conversion
+func (hi *handleImpl) MetricsClientSet() metricsclient.Interface {
+	return nil
+}
+
 // GetPodsAssignedToNodeFunc retrieves GetPodsAssignedToNodeFunc implementation
 func (hi *handleImpl) GetPodsAssignedToNodeFunc() podutil.GetPodsAssignedToNodeFunc {
 	return hi.getPodsAssignedToNodeFunc
@@ -98,6 +127,21 @@ func (hi *handleImpl) Evictor() frameworktypes.Evictor {
 	return hi.evictor
 }
 
+// Sorter retrieves sorter so plugins can order pods
+func (hi *handleImpl) Sorter() frameworktypes.Sorter {
+	return hi.sorter
+}
+
+// PluginState retrieves the state kept across cycles, none is kept for the conversion
+func (hi *handleImpl) PluginState() *frameworktypes.PluginState {
+	return nil
+}
+
+// Context retrieves the background context, no plugin runs for the conversion
+func (hi *handleImpl) Context() context.Context {
+	return context.Background()
+}
+
 func Convert_v1alpha1_DeschedulerPolicy_To_api_DeschedulerPolicy(in *DeschedulerPolicy, out *api.DeschedulerPolicy, s conversion.Scope) error {
 	klog.V(1).Info("Warning: v1alpha1 API is deprecated and will be removed in a future release. Use v1alpha2 API instead.")
 
@@ -239,6 +283,7 @@ func V1alpha1ToInternal(
 	out.NodeSelector = deschedulerPolicy.NodeSelector
 	out.MaxNoOfPodsToEvictPerNamespace = deschedulerPolicy.MaxNoOfPodsToEvictPerNamespace
 	out.MaxNoOfPodsToEvictPerNode = deschedulerPolicy.MaxNoOfPodsToEvictPerNode
+	out.MaxNoOfPodsToEvictTotal = deschedulerPolicy.MaxNoOfPodsToEvictTotal
 
 	return nil
 }
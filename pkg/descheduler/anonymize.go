@@ -0,0 +1,350 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// preservedNames are names the descheduler or the kubelet give a meaning to
+var preservedNames = map[string]bool{
+	"default":                 true,
+	"kube-system":             true,
+	"kube-public":             true,
+	"kube-node-lease":         true,
+	"system-cluster-critical": true,
+	"system-node-critical":    true,
+}
+
+// anonymizer replaces names, label keys and label values with generated ones.
+// The same input is always replaced with the same output so references between
+// objects, e.g. a pod's node name or a PodDisruptionBudget's selector, are kept.
+type anonymizer struct {
+	names map[string]string
+}
+
+func newAnonymizer() *anonymizer {
+	return &anonymizer{names: map[string]string{}}
+}
+
+func (a *anonymizer) name(name string) string {
+	if name == "" || preservedNames[name] {
+		return name
+	}
+	if anonymized, ok := a.names[name]; ok {
+		return anonymized
+	}
+	anonymized := fmt.Sprintf("anon-%d", len(a.names)+1)
+	a.names[name] = anonymized
+	return anonymized
+}
+
+func (a *anonymizer) nameList(names []string) []string {
+	if names == nil {
+		return nil
+	}
+	anonymized := make([]string, 0, len(names))
+	for _, name := range names {
+		anonymized = append(anonymized, a.name(name))
+	}
+	return anonymized
+}
+
+// key keeps the well-known keys of kubernetes and the descheduler, e.g. kubernetes.io/hostname
+// or descheduler.alpha.kubernetes.io/evict, and replaces all the others.
+func (a *anonymizer) key(key string) string {
+	if isWellKnownKey(key) {
+		return key
+	}
+	return a.name(key)
+}
+
+func isWellKnownKey(key string) bool {
+	prefix, _, found := strings.Cut(key, "/")
+	if !found {
+		return false
+	}
+	return prefix == "kubernetes.io" || prefix == "k8s.io" ||
+		strings.HasSuffix(prefix, ".kubernetes.io") || strings.HasSuffix(prefix, ".k8s.io")
+}
+
+func (a *anonymizer) labels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	anonymized := make(map[string]string, len(labels))
+	for key, value := range labels {
+		anonymized[a.key(key)] = a.name(value)
+	}
+	return anonymized
+}
+
+// annotations drops all the annotations except the well-known ones since their
+// values are free form, e.g. kubectl.kubernetes.io/last-applied-configuration.
+func (a *anonymizer) annotations(annotations map[string]string) map[string]string {
+	if annotations == nil {
+		return nil
+	}
+	anonymized := map[string]string{}
+	for key, value := range annotations {
+		if isWellKnownKey(key) && key != v1.LastAppliedConfigAnnotation {
+			anonymized[key] = value
+		}
+	}
+	return anonymized
+}
+
+func (a *anonymizer) objectMeta(meta *metav1.ObjectMeta) {
+	meta.Name = a.name(meta.Name)
+	meta.GenerateName = a.name(meta.GenerateName)
+	meta.Namespace = a.name(meta.Namespace)
+	meta.Labels = a.labels(meta.Labels)
+	meta.Annotations = a.annotations(meta.Annotations)
+	for i := range meta.OwnerReferences {
+		meta.OwnerReferences[i].Name = a.name(meta.OwnerReferences[i].Name)
+	}
+}
+
+func (a *anonymizer) labelSelector(selector *metav1.LabelSelector) {
+	if selector == nil {
+		return
+	}
+	selector.MatchLabels = a.labels(selector.MatchLabels)
+	for i := range selector.MatchExpressions {
+		selector.MatchExpressions[i].Key = a.key(selector.MatchExpressions[i].Key)
+		selector.MatchExpressions[i].Values = a.nameList(selector.MatchExpressions[i].Values)
+	}
+}
+
+func (a *anonymizer) nodeSelectorTerm(term *v1.NodeSelectorTerm) {
+	for i := range term.MatchExpressions {
+		term.MatchExpressions[i].Key = a.key(term.MatchExpressions[i].Key)
+		term.MatchExpressions[i].Values = a.nameList(term.MatchExpressions[i].Values)
+	}
+	// the only supported field is metadata.name
+	for i := range term.MatchFields {
+		term.MatchFields[i].Values = a.nameList(term.MatchFields[i].Values)
+	}
+}
+
+func (a *anonymizer) podAffinityTerm(term *v1.PodAffinityTerm) {
+	a.labelSelector(term.LabelSelector)
+	a.labelSelector(term.NamespaceSelector)
+	term.Namespaces = a.nameList(term.Namespaces)
+	term.TopologyKey = a.key(term.TopologyKey)
+	for i := range term.MatchLabelKeys {
+		term.MatchLabelKeys[i] = a.key(term.MatchLabelKeys[i])
+	}
+	for i := range term.MismatchLabelKeys {
+		term.MismatchLabelKeys[i] = a.key(term.MismatchLabelKeys[i])
+	}
+}
+
+func (a *anonymizer) affinity(affinity *v1.Affinity) {
+	if affinity == nil {
+		return
+	}
+	if nodeAffinity := affinity.NodeAffinity; nodeAffinity != nil {
+		if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil {
+			for i := range required.NodeSelectorTerms {
+				a.nodeSelectorTerm(&required.NodeSelectorTerms[i])
+			}
+		}
+		for i := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			a.nodeSelectorTerm(&nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].Preference)
+		}
+	}
+	if podAffinity := affinity.PodAffinity; podAffinity != nil {
+		for i := range podAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			a.podAffinityTerm(&podAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i])
+		}
+		for i := range podAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			a.podAffinityTerm(&podAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm)
+		}
+	}
+	if podAntiAffinity := affinity.PodAntiAffinity; podAntiAffinity != nil {
+		for i := range podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution {
+			a.podAffinityTerm(&podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[i])
+		}
+		for i := range podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+			a.podAffinityTerm(&podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution[i].PodAffinityTerm)
+		}
+	}
+}
+
+func (a *anonymizer) containers(containers []v1.Container) {
+	for i := range containers {
+		containers[i].Name = a.name(containers[i].Name)
+		containers[i].Image = a.name(containers[i].Image)
+		containers[i].Command = nil
+		containers[i].Args = nil
+		containers[i].WorkingDir = ""
+		containers[i].Env = nil
+		containers[i].EnvFrom = nil
+		containers[i].VolumeMounts = nil
+		containers[i].LivenessProbe = nil
+		containers[i].ReadinessProbe = nil
+		containers[i].StartupProbe = nil
+		containers[i].Lifecycle = nil
+	}
+}
+
+func (a *anonymizer) containerStatuses(statuses []v1.ContainerStatus) {
+	for i := range statuses {
+		statuses[i].Name = a.name(statuses[i].Name)
+		statuses[i].Image = a.name(statuses[i].Image)
+		statuses[i].ImageID = ""
+		statuses[i].ContainerID = ""
+		if terminated := statuses[i].State.Terminated; terminated != nil {
+			terminated.Message = ""
+		}
+		if terminated := statuses[i].LastTerminationState.Terminated; terminated != nil {
+			terminated.Message = ""
+		}
+		if waiting := statuses[i].State.Waiting; waiting != nil {
+			waiting.Message = ""
+		}
+	}
+}
+
+// volumes keeps the volume sources the descheduler checks, e.g. local storage,
+// and replaces the names of the objects they refer to.
+func (a *anonymizer) volumes(volumes []v1.Volume) {
+	for i := range volumes {
+		volume := &volumes[i]
+		volume.Name = a.name(volume.Name)
+		if volume.HostPath != nil {
+			volume.HostPath.Path = "/" + a.name(volume.HostPath.Path)
+		}
+		if volume.Secret != nil {
+			volume.Secret.SecretName = a.name(volume.Secret.SecretName)
+			volume.Secret.Items = nil
+		}
+		if volume.ConfigMap != nil {
+			volume.ConfigMap.Name = a.name(volume.ConfigMap.Name)
+			volume.ConfigMap.Items = nil
+		}
+		if volume.PersistentVolumeClaim != nil {
+			volume.PersistentVolumeClaim.ClaimName = a.name(volume.PersistentVolumeClaim.ClaimName)
+		}
+		if volume.Projected != nil {
+			volume.Projected.Sources = nil
+		}
+	}
+}
+
+func (a *anonymizer) taints(taints []v1.Taint) {
+	for i := range taints {
+		taints[i].Key = a.key(taints[i].Key)
+		taints[i].Value = a.name(taints[i].Value)
+	}
+}
+
+func (a *anonymizer) tolerations(tolerations []v1.Toleration) {
+	for i := range tolerations {
+		tolerations[i].Key = a.key(tolerations[i].Key)
+		tolerations[i].Value = a.name(tolerations[i].Value)
+	}
+}
+
+func (a *anonymizer) pod(pod *v1.Pod) {
+	a.objectMeta(&pod.ObjectMeta)
+	pod.Spec.NodeName = a.name(pod.Spec.NodeName)
+	pod.Spec.NodeSelector = a.labels(pod.Spec.NodeSelector)
+	pod.Spec.PriorityClassName = a.name(pod.Spec.PriorityClassName)
+	pod.Spec.ServiceAccountName = a.name(pod.Spec.ServiceAccountName)
+	pod.Spec.DeprecatedServiceAccount = a.name(pod.Spec.DeprecatedServiceAccount)
+	pod.Spec.Hostname = a.name(pod.Spec.Hostname)
+	pod.Spec.Subdomain = a.name(pod.Spec.Subdomain)
+	pod.Spec.ImagePullSecrets = nil
+	pod.Spec.HostAliases = nil
+	pod.Spec.DNSConfig = nil
+	a.affinity(pod.Spec.Affinity)
+	a.tolerations(pod.Spec.Tolerations)
+	for i := range pod.Spec.TopologySpreadConstraints {
+		constraint := &pod.Spec.TopologySpreadConstraints[i]
+		constraint.TopologyKey = a.key(constraint.TopologyKey)
+		a.labelSelector(constraint.LabelSelector)
+		for j := range constraint.MatchLabelKeys {
+			constraint.MatchLabelKeys[j] = a.key(constraint.MatchLabelKeys[j])
+		}
+	}
+	a.containers(pod.Spec.InitContainers)
+	a.containers(pod.Spec.Containers)
+	pod.Spec.EphemeralContainers = nil
+	a.volumes(pod.Spec.Volumes)
+
+	pod.Status.Message = ""
+	pod.Status.NominatedNodeName = a.name(pod.Status.NominatedNodeName)
+	pod.Status.HostIP = ""
+	pod.Status.HostIPs = nil
+	pod.Status.PodIP = ""
+	pod.Status.PodIPs = nil
+	for i := range pod.Status.Conditions {
+		pod.Status.Conditions[i].Message = ""
+	}
+	a.containerStatuses(pod.Status.InitContainerStatuses)
+	a.containerStatuses(pod.Status.ContainerStatuses)
+	pod.Status.EphemeralContainerStatuses = nil
+}
+
+func (a *anonymizer) node(node *v1.Node) {
+	a.objectMeta(&node.ObjectMeta)
+	node.Spec.PodCIDR = ""
+	node.Spec.PodCIDRs = nil
+	node.Spec.ProviderID = ""
+	node.Spec.ConfigSource = nil
+	a.taints(node.Spec.Taints)
+	node.Status.Addresses = nil
+	node.Status.Images = nil
+	node.Status.VolumesInUse = nil
+	node.Status.VolumesAttached = nil
+	node.Status.Config = nil
+	node.Status.NodeInfo = v1.NodeSystemInfo{
+		OperatingSystem: node.Status.NodeInfo.OperatingSystem,
+		Architecture:    node.Status.NodeInfo.Architecture,
+	}
+	for i := range node.Status.Conditions {
+		node.Status.Conditions[i].Message = ""
+	}
+}
+
+// anonymize replaces the identifying fields of a snapshot object in place
+func (a *anonymizer) anonymize(obj runtime.Object) {
+	switch t := obj.(type) {
+	case *v1.Pod:
+		a.pod(t)
+	case *v1.Node:
+		a.node(t)
+	case *v1.Namespace:
+		a.objectMeta(&t.ObjectMeta)
+	case *schedulingv1.PriorityClass:
+		a.objectMeta(&t.ObjectMeta)
+		t.Description = ""
+	case *policyv1.PodDisruptionBudget:
+		a.objectMeta(&t.ObjectMeta)
+		a.labelSelector(t.Spec.Selector)
+		t.Status.DisruptedPods = nil
+	}
+}
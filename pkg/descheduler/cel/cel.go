@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker"
+	"github.com/google/cel-go/ext"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	PodVariable   = "pod"
+	NodeVariable  = "node"
+	OwnerVariable = "owner"
+	NowVariable   = "now"
+)
+
+const (
+	// PerCallCostLimit bounds the cost of evaluating an expression, the estimated cost of the
+	// expression included, as the API server bounds the cost of a validation rule
+	PerCallCostLimit uint64 = 1000000
+	// interruptCheckFrequency is the number of comprehension iterations after which
+	// the evaluation checks whether it was cancelled
+	interruptCheckFrequency uint = 100
+	// evalTimeout bounds the time an evaluation takes on top of its cost
+	evalTimeout = time.Second
+	// estimatedMaxSize is the size of the lists, maps and strings of the variables when
+	// estimating the cost of an expression, as their size is not known before the evaluation
+	estimatedMaxSize uint64 = 100
+)
+
+// Expression is a compiled CEL expression evaluating to a bool
+type Expression struct {
+	Source  string
+	program cel.Program
+}
+
+// NewEnvironment declares the variables the expressions are evaluated over: the pod, its node
+// and its owner reference, all in their JSON representation, and the current time
+func NewEnvironment() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable(PodVariable, cel.DynType),
+		cel.Variable(NodeVariable, cel.DynType),
+		cel.Variable(OwnerVariable, cel.DynType),
+		cel.Variable(NowVariable, cel.TimestampType),
+		ext.Strings(),
+	)
+}
+
+// Compile parses and type checks the expressions, all of them have to evaluate to a bool
+// and have an estimated cost within PerCallCostLimit
+func Compile(env *cel.Env, sources []string) ([]Expression, error) {
+	expressions := make([]Expression, 0, len(sources))
+	for _, source := range sources {
+		ast, issues := env.Compile(source)
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("unable to compile expression %q: %v", source, issues.Err())
+		}
+		if !ast.OutputType().IsExactType(cel.BoolType) {
+			return nil, fmt.Errorf("expression %q must evaluate to bool, got %v", source, ast.OutputType())
+		}
+		cost, err := env.EstimateCost(ast, sizeEstimator{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to estimate the cost of expression %q: %v", source, err)
+		}
+		if cost.Max > PerCallCostLimit {
+			return nil, fmt.Errorf("expression %q has an estimated cost of %v exceeding the limit of %v", source, cost.Max, PerCallCostLimit)
+		}
+		program, err := env.Program(ast, cel.CostLimit(PerCallCostLimit), cel.InterruptCheckFrequency(interruptCheckFrequency))
+		if err != nil {
+			return nil, fmt.Errorf("unable to build a program from expression %q: %v", source, err)
+		}
+		expressions = append(expressions, Expression{Source: source, program: program})
+	}
+	return expressions, nil
+}
+
+// Eval evaluates the expression, an expression failing to evaluate, e.g. reading
+// a missing key or exceeding the cost limit, is reported as an error
+func (e Expression) Eval(ctx context.Context, activation map[string]interface{}) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, evalTimeout)
+	defer cancel()
+	out, _, err := e.program.ContextEval(ctx, activation)
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %v instead of a bool", out.Value())
+	}
+	return result, nil
+}
+
+// sizeEstimator estimates every list, map and string of the variables to have at most estimatedMaxSize
+// elements, so nesting comprehensions over the pod and the node exceeds the cost limit
+type sizeEstimator struct{}
+
+func (sizeEstimator) EstimateSize(element checker.AstNode) *checker.SizeEstimate {
+	return &checker.SizeEstimate{Min: 0, Max: estimatedMaxSize}
+}
+
+func (sizeEstimator) EstimateCallCost(function, overloadID string, target *checker.AstNode, args []checker.AstNode) *checker.CallEstimate {
+	return nil
+}
+
+// ActivationCache builds the variables of the expressions and converts every node only once.
+// A cache lives as long as the plugin using it, i.e. a descheduling cycle, so the nodes do not change.
+type ActivationCache struct {
+	mu    sync.Mutex
+	nodes map[string]map[string]interface{}
+}
+
+// NewActivationCache returns an empty activation cache
+func NewActivationCache() *ActivationCache {
+	return &ActivationCache{nodes: map[string]map[string]interface{}{}}
+}
+
+// NewActivation converts the pod, its node and its owner reference to the variables of the expressions.
+// The labels and annotations are always set so the expressions can index them without checking
+// they exist, the node and the owner are empty when not known.
+func (c *ActivationCache) NewActivation(pod *v1.Pod, node *v1.Node, now time.Time) (map[string]interface{}, error) {
+	podObject, err := toObject(pod)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert pod: %v", err)
+	}
+
+	nodeObject, err := c.node(node)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert node: %v", err)
+	}
+
+	ownerObject := map[string]interface{}{}
+	if ownerRef := owner(pod); ownerRef != nil {
+		ownerObject = map[string]interface{}{
+			"apiVersion": ownerRef.APIVersion,
+			"kind":       ownerRef.Kind,
+			"name":       ownerRef.Name,
+			"uid":        string(ownerRef.UID),
+			"controller": ownerRef.Controller != nil && *ownerRef.Controller,
+		}
+	}
+
+	return map[string]interface{}{
+		PodVariable:   podObject,
+		NodeVariable:  nodeObject,
+		OwnerVariable: ownerObject,
+		NowVariable:   now,
+	}, nil
+}
+
+// node returns the converted node, the expressions only read the variables so the node is shared
+func (c *ActivationCache) node(node *v1.Node) (map[string]interface{}, error) {
+	if node == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if nodeObject, ok := c.nodes[node.Name]; ok {
+		return nodeObject, nil
+	}
+	nodeObject, err := toObject(node)
+	if err != nil {
+		return nil, err
+	}
+	c.nodes[node.Name] = nodeObject
+	return nodeObject, nil
+}
+
+// owner returns the owner reference of the controller of the pod, or its first owner reference
+// when none is marked as the controller
+func owner(pod *v1.Pod) *metav1.OwnerReference {
+	if ownerRef := metav1.GetControllerOf(pod); ownerRef != nil {
+		return ownerRef
+	}
+	if len(pod.OwnerReferences) > 0 {
+		return &pod.OwnerReferences[0]
+	}
+	return nil
+}
+
+func toObject(obj interface{}) (map[string]interface{}, error) {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, err
+	}
+	metadata, ok := object["metadata"].(map[string]interface{})
+	if !ok {
+		metadata = map[string]interface{}{}
+		object["metadata"] = metadata
+	}
+	for _, key := range []string{"labels", "annotations"} {
+		if _, ok := metadata[key]; !ok {
+			metadata[key] = map[string]interface{}{}
+		}
+	}
+	return object, nil
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestCompileCostLimit(t *testing.T) {
+	env, err := NewEnvironment()
+	if err != nil {
+		t.Fatalf("Unable to create the CEL environment: %v", err)
+	}
+
+	tests := []struct {
+		description string
+		expression  string
+		expectError bool
+	}{
+		{
+			description: "expression within the estimated cost limit",
+			expression:  "pod.spec.containers.exists(c, c.image.startsWith('nginx'))",
+		},
+		{
+			description: "nested comprehensions exceeding the estimated cost limit",
+			expression:  "pod.spec.containers.all(c, pod.spec.containers.all(d, pod.spec.containers.all(e, c.name != e.name)))",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := Compile(env, []string{tc.expression})
+			if hasError := err != nil; hasError != tc.expectError {
+				t.Errorf("Expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
+
+func TestEvalCostLimit(t *testing.T) {
+	env, err := NewEnvironment()
+	if err != nil {
+		t.Fatalf("Unable to create the CEL environment: %v", err)
+	}
+	expressions, err := Compile(env, []string{"pod.spec.containers.all(c, pod.spec.containers.all(d, c.name != d.name || c == d))"})
+	if err != nil {
+		t.Fatalf("Unable to compile the expression: %v", err)
+	}
+
+	pod := test.BuildTestPod("p1", 100, 0, "n1", func(pod *v1.Pod) {
+		for i := 0; i < 500; i++ {
+			pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: fmt.Sprintf("c%d", i)})
+		}
+	})
+	activation, err := NewActivationCache().NewActivation(pod, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Unable to build the activation: %v", err)
+	}
+	if _, err := expressions[0].Eval(context.Background(), activation); err == nil {
+		t.Errorf("Expected the evaluation to exceed the cost limit")
+	}
+}
+
+func TestActivationCache(t *testing.T) {
+	node := test.BuildTestNode("n1", 1000, 2000, 10, nil)
+	p1 := test.BuildTestPod("p1", 100, 0, node.Name, nil)
+	p2 := test.BuildTestPod("p2", 100, 0, node.Name, nil)
+
+	activations := NewActivationCache()
+	first, err := activations.NewActivation(p1, node, time.Now())
+	if err != nil {
+		t.Fatalf("Unable to build the activation: %v", err)
+	}
+	second, err := activations.NewActivation(p2, node, time.Now())
+	if err != nil {
+		t.Fatalf("Unable to build the activation: %v", err)
+	}
+
+	if len(activations.nodes) != 1 {
+		t.Errorf("Expected the node to be converted once, got %v converted nodes", len(activations.nodes))
+	}
+	if fmt.Sprintf("%p", first[NodeVariable]) != fmt.Sprintf("%p", second[NodeVariable]) {
+		t.Errorf("Expected the activations to share the converted node")
+	}
+	if name := second[PodVariable].(map[string]interface{})["metadata"].(map[string]interface{})["name"]; name != p2.Name {
+		t.Errorf("Expected the activation of pod %v, got %v", p2.Name, name)
+	}
+}
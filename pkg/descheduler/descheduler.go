@@ -69,57 +69,138 @@ type profileRunner struct {
 	descheduleEPs, balanceEPs eprunner
 }
 
+// listers are the listers of the cluster objects the descheduler makes its decisions on
+type listers struct {
+	podLister           listersv1.PodLister
+	nodeLister          listersv1.NodeLister
+	namespaceLister     listersv1.NamespaceLister
+	priorityClassLister schedulingv1.PriorityClassLister
+}
+
+// newListers registers the informers of the listers with the factory
+func newListers(sharedInformerFactory informers.SharedInformerFactory) listers {
+	return listers{
+		podLister:           sharedInformerFactory.Core().V1().Pods().Lister(),
+		nodeLister:          sharedInformerFactory.Core().V1().Nodes().Lister(),
+		namespaceLister:     sharedInformerFactory.Core().V1().Namespaces().Lister(),
+		priorityClassLister: sharedInformerFactory.Scheduling().V1().PriorityClasses().Lister(),
+	}
+}
+
+// newSharedInformerFactory returns the factory of the informers the descheduler lists the cluster objects with
+func newSharedInformerFactory(client clientset.Interface) informers.SharedInformerFactory {
+	return informers.NewSharedInformerFactoryWithOptions(client, 0, informers.WithTransform(trimManagedFields))
+}
+
+// waitForCacheSync waits for the caches of all the started informers of the factory to sync
+func waitForCacheSync(ctx context.Context, sharedInformerFactory informers.SharedInformerFactory) error {
+	for informerType, synced := range sharedInformerFactory.WaitForCacheSync(ctx.Done()) {
+		if !synced {
+			return fmt.Errorf("unable to sync the cache of the %v informer", informerType)
+		}
+	}
+	return nil
+}
+
 type descheduler struct {
+	listers
 	rs                     *options.DeschedulerServer
-	podLister              listersv1.PodLister
-	nodeLister             listersv1.NodeLister
-	namespaceLister        listersv1.NamespaceLister
-	priorityClassLister    schedulingv1.PriorityClassLister
 	getPodsAssignedToNode  podutil.GetPodsAssignedToNodeFunc
 	sharedInformerFactory  informers.SharedInformerFactory
 	deschedulerPolicy      *api.DeschedulerPolicy
 	eventRecorder          events.EventRecorder
 	podEvictor             *evictions.PodEvictor
 	podEvictionReactionFnc func(*fakeclientset.Clientset) func(action core.Action) (bool, runtime.Object, error)
+	dryRunReporter         *dryRunReporter
+	placementSimulator     *placementSimulator
+	oscillationDetector    *oscillationDetector
+	policyReloader         *policyReloader
+	// pluginStates holds the state the plugins of every profile keep across cycles, by profile name
+	pluginStates map[string]*frameworktypes.PluginState
 }
 
 func newDescheduler(rs *options.DeschedulerServer, deschedulerPolicy *api.DeschedulerPolicy, evictionPolicyGroupVersion string, eventRecorder events.EventRecorder, sharedInformerFactory informers.SharedInformerFactory) (*descheduler, error) {
 	podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
-	podLister := sharedInformerFactory.Core().V1().Pods().Lister()
-	nodeLister := sharedInformerFactory.Core().V1().Nodes().Lister()
-	namespaceLister := sharedInformerFactory.Core().V1().Namespaces().Lister()
-	priorityClassLister := sharedInformerFactory.Scheduling().V1().PriorityClasses().Lister()
 
 	getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(podInformer)
 	if err != nil {
 		return nil, fmt.Errorf("build get pods assigned to node function error: %v", err)
 	}
 
-	podEvictor := evictions.NewPodEvictor(
-		nil,
-		eventRecorder,
-		evictions.NewOptions().
-			WithPolicyGroupVersion(evictionPolicyGroupVersion).
-			WithMaxPodsToEvictPerNode(deschedulerPolicy.MaxNoOfPodsToEvictPerNode).
-			WithMaxPodsToEvictPerNamespace(deschedulerPolicy.MaxNoOfPodsToEvictPerNamespace).
-			WithMaxPodsToEvictTotal(deschedulerPolicy.MaxNoOfPodsToEvictTotal).
-			WithDryRun(rs.DryRun).
-			WithMetricsEnabled(!rs.DisableMetrics),
-	)
-
-	return &descheduler{
+	d := &descheduler{
+		listers:                newListers(sharedInformerFactory),
 		rs:                     rs,
-		podLister:              podLister,
-		nodeLister:             nodeLister,
-		namespaceLister:        namespaceLister,
-		priorityClassLister:    priorityClassLister,
 		getPodsAssignedToNode:  getPodsAssignedToNode,
 		sharedInformerFactory:  sharedInformerFactory,
 		deschedulerPolicy:      deschedulerPolicy,
 		eventRecorder:          eventRecorder,
-		podEvictor:             podEvictor,
 		podEvictionReactionFnc: podEvictionReactionFnc,
-	}, nil
+		pluginStates:           make(map[string]*frameworktypes.PluginState),
+	}
+	d.podEvictor = evictions.NewPodEvictor(
+		nil,
+		eventRecorder,
+		podEvictorOptions(rs, deschedulerPolicy, evictionPolicyGroupVersion).
+			WithEvictionHandler(d.recordEviction),
+	)
+
+	return d, nil
+}
+
+// recordEviction adds the evicted pod to the dry run report of the current cycle, if any
+func (d *descheduler) recordEviction(pod *v1.Pod, opts evictions.EvictOptions) {
+	var replacement *v1.Pod
+	identity := pod.Namespace + "/" + pod.Name
+	if d.placementSimulator != nil {
+		replacement = d.placementSimulator.replacement(pod.UID)
+		identity = d.placementSimulator.identity(pod)
+	}
+	var cycle int
+	if d.oscillationDetector != nil {
+		cycle = d.oscillationDetector.currentCycle()
+		d.oscillationDetector.recordEviction(identity, pod)
+	}
+	if d.dryRunReporter != nil {
+		d.dryRunReporter.recordEviction(pod, opts, replacement, cycle)
+	}
+}
+
+// startSimulatedCycle records the classes the utilization plugins assign to the nodes
+// at the start of every cycle when multiple cycles are simulated
+func (d *descheduler) startSimulatedCycle(nodes []*v1.Node) {
+	if d.oscillationDetector == nil {
+		return
+	}
+	cycle := d.oscillationDetector.startCycle()
+	klog.V(1).InfoS("Starting a simulated descheduling cycle", "cycle", cycle)
+	d.oscillationDetector.recordNodeClasses(d.deschedulerPolicy, nodes, d.getPodsAssignedToNode)
+}
+
+// reportOscillations logs the repeated evictions and flapping nodes found over the simulated cycles
+func reportOscillations(report *OscillationReport) {
+	for _, eviction := range report.RepeatedPodEvictions {
+		klog.InfoS("Pod evicted repeatedly", "pod", eviction.Name, "cycles", eviction.Cycles)
+	}
+	for _, eviction := range report.RepeatedOwnerEvictions {
+		klog.InfoS("Pods of an owner evicted repeatedly", "owner", eviction.Name, "cycles", eviction.Cycles)
+	}
+	for _, node := range report.FlappingNodes {
+		klog.InfoS("Node flaps between under and over utilization", "node", node.Node, "profile", node.Profile, "plugin", node.Plugin, "classes", node.Classes)
+	}
+	if !report.Oscillating() {
+		klog.V(1).InfoS("No oscillation detected", "cycles", report.Cycles)
+	}
+}
+
+func podEvictorOptions(rs *options.DeschedulerServer, deschedulerPolicy *api.DeschedulerPolicy, evictionPolicyGroupVersion string) *evictions.Options {
+	return evictions.NewOptions().
+		WithPolicyGroupVersion(evictionPolicyGroupVersion).
+		WithMaxPodsToEvictPerNode(deschedulerPolicy.MaxNoOfPodsToEvictPerNode).
+		WithMaxPodsToEvictPerNamespace(deschedulerPolicy.MaxNoOfPodsToEvictPerNamespace).
+		WithMaxPodsToEvictTotal(deschedulerPolicy.MaxNoOfPodsToEvictTotal).
+		WithDryRun(rs.DryRun).
+		WithMetricsEnabled(!rs.DisableMetrics).
+		WithSteeringTTL(rs.EvictionSteeringTTL)
 }
 
 func (d *descheduler) runDeschedulerLoop(ctx context.Context, nodes []*v1.Node) error {
@@ -166,21 +247,74 @@ func (d *descheduler) runDeschedulerLoop(ctx context.Context, nodes []*v1.Node)
 
 		client = fakeClient
 		d.sharedInformerFactory = fakeSharedInformerFactory
+
+		if d.rs.DryRunReport != "" {
+			d.dryRunReporter = newDryRunReporter(nodes, d.getPodsAssignedToNode)
+			defer func() { d.dryRunReporter = nil }()
+		}
+
+		// consecutive cycles only evolve the cluster when the evicted pods land somewhere
+		if d.rs.DryRunReschedule || d.rs.DryRunCycles > 1 {
+			// recreate the evicted pods on other nodes so the plugins see where the replacements land
+			d.placementSimulator = newPlacementSimulator(fakeClient, nodes, d.getPodsAssignedToNode)
+			fakeClient.PrependReactor("create", "pods", d.placementSimulator.evictionReactionFnc())
+			d.getPodsAssignedToNode = d.placementSimulator.GetPodsAssignedToNode
+			defer func() { d.placementSimulator = nil }()
+		}
+
+		if d.rs.DryRunCycles > 1 {
+			d.oscillationDetector = newOscillationDetector()
+			defer func() { d.oscillationDetector = nil }()
+		}
 	} else {
 		client = d.rs.Client
 	}
 
 	klog.V(3).Infof("Setting up the pod evictor")
 	d.podEvictor.SetClient(client)
-	d.podEvictor.ResetCounters()
+	d.podEvictor.RevertExpiredSteering(ctx, nodes)
+
+	cycles := 1
+	if d.rs.DryRun && d.rs.DryRunCycles > 1 {
+		cycles = d.rs.DryRunCycles
+	}
+	for i := 0; i < cycles; i++ {
+		d.startSimulatedCycle(nodes)
+		d.podEvictor.ResetCounters()
+
+		d.runProfiles(ctx, client, nodes)
 
-	d.runProfiles(ctx, client, nodes)
+		klog.V(1).InfoS("Number of evicted pods", "totalEvicted", d.podEvictor.TotalEvicted())
+	}
+
+	var oscillations *OscillationReport
+	if d.oscillationDetector != nil {
+		oscillations = d.oscillationDetector.report()
+		reportOscillations(oscillations)
+	}
 
-	klog.V(1).InfoS("Number of evicted pods", "totalEvicted", d.podEvictor.TotalEvicted())
+	if d.dryRunReporter != nil {
+		report := d.dryRunReporter.finish()
+		report.Oscillations = oscillations
+		klog.V(3).InfoS("Writing the dry run report", "file", d.rs.DryRunReport, "format", d.rs.DryRunReportFormat)
+		if err := writeDryRunReportFile(d.rs.DryRunReport, d.rs.DryRunReportFormat, report); err != nil {
+			klog.ErrorS(err, "unable to write the dry run report")
+		}
+	}
 
 	return nil
 }
 
+// pluginState returns the state the plugins of the profile keep across cycles
+func (d *descheduler) pluginState(profileName string) *frameworktypes.PluginState {
+	pluginState, ok := d.pluginStates[profileName]
+	if !ok {
+		pluginState = frameworktypes.NewPluginState()
+		d.pluginStates[profileName] = pluginState
+	}
+	return pluginState
+}
+
 // runProfiles runs all the deschedule plugins of all profiles and
 // later runs through all balance plugins of all profiles. (All Balance plugins should come after all Deschedule plugins)
 // see https://github.com/kubernetes-sigs/descheduler/issues/979
@@ -188,15 +322,27 @@ func (d *descheduler) runProfiles(ctx context.Context, client clientset.Interfac
 	var span trace.Span
 	ctx, span = tracing.Tracer().Start(ctx, "runProfiles")
 	defer span.End()
+
+	// In the planning mode plugins only propose pods for eviction. The proposals
+	// are ranked across all profiles and executed once all plugins finished.
+	var evictionPlan *evictions.EvictionPlan
+	if d.rs.EvictionPlanning {
+		evictionPlan = evictions.NewEvictionPlan(d.deschedulerPolicy.MaxNoOfPodsToEvictPerNode, d.deschedulerPolicy.MaxNoOfPodsToEvictPerNamespace)
+	}
+
 	var profileRunners []profileRunner
 	for _, profile := range d.deschedulerPolicy.Profiles {
 		currProfile, err := frameworkprofile.NewProfile(
 			profile,
 			pluginregistry.PluginRegistry,
 			frameworkprofile.WithClientSet(client),
+			frameworkprofile.WithMetricsClientSet(d.rs.MetricsClient),
 			frameworkprofile.WithSharedInformerFactory(d.sharedInformerFactory),
 			frameworkprofile.WithPodEvictor(d.podEvictor),
+			frameworkprofile.WithEvictionPlan(evictionPlan),
 			frameworkprofile.WithGetPodsAssignedToNodeFnc(d.getPodsAssignedToNode),
+			frameworkprofile.WithPluginState(d.pluginState(profile.Name)),
+			frameworkprofile.WithContext(ctx),
 		)
 		if err != nil {
 			klog.ErrorS(err, "unable to create a profile", "profile", profile.Name)
@@ -224,6 +370,11 @@ func (d *descheduler) runProfiles(ctx context.Context, client clientset.Interfac
 			continue
 		}
 	}
+
+	if evictionPlan != nil {
+		klog.V(1).InfoS("Executing the eviction plan", "candidates", len(evictionPlan.Candidates()))
+		evictionPlan.Execute(ctx, d.podEvictor)
+	}
 }
 
 func Run(ctx context.Context, rs *options.DeschedulerServer) error {
@@ -242,6 +393,11 @@ func Run(ctx context.Context, rs *options.DeschedulerServer) error {
 	}
 	rs.Client = rsclient
 	rs.EventClient = eventClient
+	// no request is sent until a plugin reads the actual resource usage
+	rs.MetricsClient, err = client.CreateMetricsClient(clientConnection, "descheduler")
+	if err != nil {
+		return err
+	}
 
 	deschedulerPolicy, err := LoadPolicyConfig(rs.PolicyConfigFile, rs.Client, pluginregistry.PluginRegistry)
 	if err != nil {
@@ -251,6 +407,27 @@ func Run(ctx context.Context, rs *options.DeschedulerServer) error {
 		return fmt.Errorf("deschedulerPolicy is nil")
 	}
 
+	if rs.DryRunReschedule && !rs.DryRun {
+		return fmt.Errorf("rescheduling of evicted pods can be simulated only in the dry run mode")
+	}
+
+	if rs.DryRunCycles > 1 && !rs.DryRun {
+		return fmt.Errorf("multiple descheduling cycles can be simulated only in the dry run mode")
+	}
+
+	if rs.EvictionSteeringTTL > 0 && rs.DeschedulingInterval.Seconds() == 0 {
+		return fmt.Errorf("eviction steering must be used with deschedulingInterval")
+	}
+
+	if rs.DryRunReport != "" {
+		if !rs.DryRun {
+			return fmt.Errorf("dry run report can be written only in the dry run mode")
+		}
+		if err := ValidateDryRunReportFormat(rs.DryRunReportFormat); err != nil {
+			return err
+		}
+	}
+
 	// Add k8s compatibility warnings to logs
 	if err := validateVersionCompatibility(rs.Client.Discovery(), version.Get()); err != nil {
 		klog.Warning(err.Error())
@@ -397,12 +574,7 @@ func RunDeschedulerStrategies(ctx context.Context, rs *options.DeschedulerServer
 	ctx, span = tracing.Tracer().Start(ctx, "RunDeschedulerStrategies")
 	defer span.End()
 
-	sharedInformerFactory := informers.NewSharedInformerFactoryWithOptions(rs.Client, 0, informers.WithTransform(trimManagedFields))
-
-	var nodeSelector string
-	if deschedulerPolicy.NodeSelector != nil {
-		nodeSelector = *deschedulerPolicy.NodeSelector
-	}
+	sharedInformerFactory := newSharedInformerFactory(rs.Client)
 
 	var eventClient clientset.Interface
 	if rs.DryRun {
@@ -421,6 +593,15 @@ func RunDeschedulerStrategies(ctx context.Context, rs *options.DeschedulerServer
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// the policy can only change between cycles
+	if rs.PolicyConfigFile != "" && rs.DeschedulingInterval.Seconds() != 0 {
+		descheduler.policyReloader, err = newPolicyReloader(rs.PolicyConfigFile, rs.Client, pluginregistry.PluginRegistry)
+		if err != nil {
+			return err
+		}
+		descheduler.policyReloader.watch(ctx)
+	}
+
 	sharedInformerFactory.Start(ctx.Done())
 	sharedInformerFactory.WaitForCacheSync(ctx.Done())
 
@@ -428,6 +609,11 @@ func RunDeschedulerStrategies(ctx context.Context, rs *options.DeschedulerServer
 		// A next context is created here intentionally to avoid nesting the spans via context.
 		sCtx, sSpan := tracing.Tracer().Start(ctx, "NonSlidingUntil")
 		defer sSpan.End()
+		descheduler.reloadPolicy()
+		var nodeSelector string
+		if descheduler.deschedulerPolicy.NodeSelector != nil {
+			nodeSelector = *descheduler.deschedulerPolicy.NodeSelector
+		}
 		nodes, err := nodeutil.ReadyNodes(sCtx, rs.Client, descheduler.nodeLister, nodeSelector)
 		if err != nil {
 			sSpan.AddEvent("Failed to detect ready nodes", trace.WithAttributes(attribute.String("err", err.Error())))
@@ -450,3 +450,70 @@ func TestPodEvictorReset(t *testing.T) {
 		t.Fatalf("Expected (2,0,4) pods evicted, got (%v, %v, %v) instead", descheduler.podEvictor.TotalEvicted(), len(evictedPods), len(fakeEvictedPods))
 	}
 }
+
+func TestEvictionPlanning(t *testing.T) {
+	initPluginRegistry()
+
+	ctx := context.Background()
+	node1 := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+		node.Spec.Taints = []v1.Taint{
+			{
+				Key:    "key",
+				Value:  "value",
+				Effect: v1.TaintEffectNoSchedule,
+			},
+		}
+	})
+	node2 := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+
+	p1 := test.BuildTestPod("p1", 100, 0, node1.Name, nil)
+	p1.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+	p2 := test.BuildTestPod("p2", 100, 0, node1.Name, nil)
+	p2.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+
+	// two profiles proposing the same pods
+	policy := removePodsViolatingNodeTaintsPolicy()
+	secondProfile := *policy.Profiles[0].DeepCopy()
+	secondProfile.Name = "SecondProfile"
+	policy.Profiles = append(policy.Profiles, secondProfile)
+
+	tests := []struct {
+		description       string
+		evictionPlanning  bool
+		expectedEvictions int
+	}{
+		{
+			description:       "each profile evicts on its own",
+			expectedEvictions: 4,
+		},
+		{
+			description:       "pods proposed by both profiles are evicted once",
+			evictionPlanning:  true,
+			expectedEvictions: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			ctxCancel, cancel := context.WithCancel(ctx)
+			defer cancel()
+			rs, descheduler, client := initDescheduler(t, ctxCancel, policy, node1, node2, p1, p2)
+			rs.EvictionPlanning = tc.evictionPlanning
+
+			var evictedPods []string
+			client.PrependReactor("create", "pods", podEvictionReactionTestingFnc(&evictedPods))
+
+			nodes, err := nodeutil.ReadyNodes(ctx, rs.Client, descheduler.nodeLister, "")
+			if err != nil {
+				t.Fatalf("Unable to get ready nodes: %v", err)
+			}
+
+			if err := descheduler.runDeschedulerLoop(ctx, nodes); err != nil {
+				t.Fatalf("Unable to run a descheduling loop: %v", err)
+			}
+			if len(evictedPods) != tc.expectedEvictions {
+				t.Errorf("Expected %v pods evicted, got %v instead: %v", tc.expectedEvictions, len(evictedPods), evictedPods)
+			}
+		})
+	}
+}
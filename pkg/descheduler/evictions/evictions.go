@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -54,7 +55,11 @@ type PodEvictor struct {
 	namespacePodCount          namespacePodEvictCount
 	totalPodCount              uint
 	metricsEnabled             bool
+	evictionHandler            EvictionHandler
 	eventRecorder              events.EventRecorder
+	steeringTTL                time.Duration
+	// steeredNodes are the nodes tainted in the current cycle
+	steeredNodes map[string]bool
 }
 
 func NewPodEvictor(
@@ -75,8 +80,11 @@ func NewPodEvictor(
 		maxPodsToEvictPerNamespace: options.maxPodsToEvictPerNamespace,
 		maxPodsToEvictTotal:        options.maxPodsToEvictTotal,
 		metricsEnabled:             options.metricsEnabled,
+		evictionHandler:            options.evictionHandler,
+		steeringTTL:                options.steeringTTL,
 		nodePodCount:               make(nodePodEvictedCount),
 		namespacePodCount:          make(namespacePodEvictCount),
+		steeredNodes:               make(map[string]bool),
 	}
 }
 
@@ -100,6 +108,16 @@ func (pe *PodEvictor) ResetCounters() {
 	pe.nodePodCount = make(nodePodEvictedCount)
 	pe.namespacePodCount = make(namespacePodEvictCount)
 	pe.totalPodCount = 0
+	pe.steeredNodes = make(map[string]bool)
+}
+
+// SetLimits replaces the eviction limits, e.g. when the policy is reloaded
+func (pe *PodEvictor) SetLimits(maxPodsToEvictPerNode, maxPodsToEvictPerNamespace, maxPodsToEvictTotal *uint) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	pe.maxPodsToEvictPerNode = maxPodsToEvictPerNode
+	pe.maxPodsToEvictPerNamespace = maxPodsToEvictPerNamespace
+	pe.maxPodsToEvictTotal = maxPodsToEvictTotal
 }
 
 func (pe *PodEvictor) SetClient(client clientset.Interface) {
@@ -108,6 +126,9 @@ func (pe *PodEvictor) SetClient(client clientset.Interface) {
 	pe.client = client
 }
 
+// MaxScore is the score of an eviction resolving a violation of a constraint
+const MaxScore = 1.0
+
 // EvictOptions provides a handle for passing additional info to EvictPod
 type EvictOptions struct {
 	// Reason allows for passing details about the specific eviction for logging.
@@ -116,17 +137,75 @@ type EvictOptions struct {
 	ProfileName string
 	// StrategyName allows for passing details about strategy for observability.
 	StrategyName string
+	// Score ranks the pod among the candidates of an eviction plan, from 0 to MaxScore.
+	// Candidates with a higher score are evicted first. Plugins evicting pods that violate
+	// a constraint use MaxScore, the other plugins score how much an eviction is worth.
+	Score float64
+	// TargetNode is the node the replacement of the pod is expected to be scheduled to.
+	// With a steering TTL the node the pod is evicted from is tainted PreferNoSchedule
+	// before the eviction so the replacement does not land back on it. It is a hint only,
+	// the replacement is created by the controller of the pod and the scheduler may place
+	// it on any other node than the target node.
+	TargetNode string
+}
+
+// CanEvict returns the error of the first eviction limit evicting all the pods would exceed,
+// nil when the pods can all be evicted within the limits
+func (pe *PodEvictor) CanEvict(pods []*v1.Pod) error {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	return pe.exceededLimits(pods)
+}
+
+// exceededLimits returns the error of the first eviction limit evicting all the pods would exceed,
+// the caller holds the lock
+func (pe *PodEvictor) exceededLimits(pods []*v1.Pod) error {
+	if pe.maxPodsToEvictTotal != nil && pe.totalPodCount+uint(len(pods)) > *pe.maxPodsToEvictTotal {
+		return NewEvictionTotalLimitError()
+	}
+	return exceededLimit(pods, pe.nodePodCount, pe.namespacePodCount, pe.maxPodsToEvictPerNode, pe.maxPodsToEvictPerNamespace)
+}
+
+// exceededLimit returns the error of the per node or per namespace limit adding the pods to the counts would exceed
+func exceededLimit(pods []*v1.Pod, nodePodCount nodePodEvictedCount, namespacePodCount namespacePodEvictCount, maxPodsToEvictPerNode, maxPodsToEvictPerNamespace *uint) error {
+	nodeCount := make(nodePodEvictedCount)
+	namespaceCount := make(namespacePodEvictCount)
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			nodeCount[pod.Spec.NodeName]++
+			if maxPodsToEvictPerNode != nil && nodePodCount[pod.Spec.NodeName]+nodeCount[pod.Spec.NodeName] > *maxPodsToEvictPerNode {
+				return NewEvictionNodeLimitError(pod.Spec.NodeName)
+			}
+		}
+		namespaceCount[pod.Namespace]++
+		if maxPodsToEvictPerNamespace != nil && namespacePodCount[pod.Namespace]+namespaceCount[pod.Namespace] > *maxPodsToEvictPerNamespace {
+			return NewEvictionNamespaceLimitError(pod.Namespace)
+		}
+	}
+	return nil
 }
 
 // EvictPod evicts a pod while exercising eviction limits.
 // Returns true when the pod is evicted on the server side.
 func (pe *PodEvictor) EvictPod(ctx context.Context, pod *v1.Pod, opts EvictOptions) error {
-	pe.mu.Lock()
-	defer pe.mu.Unlock()
 	var span trace.Span
 	ctx, span = tracing.Tracer().Start(ctx, "EvictPod", trace.WithAttributes(attribute.String("podName", pod.Name), attribute.String("podNamespace", pod.Namespace), attribute.String("reason", opts.Reason), attribute.String("operation", tracing.EvictOperation)))
 	defer span.End()
 
+	// the node is steered before the eviction, and reverted when the eviction fails,
+	// outside the lock as both update the node
+	evicted := false
+	if steered, tainted := pe.steer(ctx, pod, opts); steered {
+		defer func() {
+			if !evicted {
+				pe.unsteer(ctx, pod, tainted)
+			}
+		}()
+	}
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
 	if pe.maxPodsToEvictTotal != nil && pe.totalPodCount+1 > *pe.maxPodsToEvictTotal {
 		err := NewEvictionTotalLimitError()
 		if pe.metricsEnabled {
@@ -169,6 +248,7 @@ func (pe *PodEvictor) EvictPod(ctx context.Context, pod *v1.Pod, opts EvictOptio
 		}
 		return err
 	}
+	evicted = true
 
 	if pod.Spec.NodeName != "" {
 		pe.nodePodCount[pod.Spec.NodeName]++
@@ -176,6 +256,10 @@ func (pe *PodEvictor) EvictPod(ctx context.Context, pod *v1.Pod, opts EvictOptio
 	pe.namespacePodCount[pod.Namespace]++
 	pe.totalPodCount++
 
+	if pe.evictionHandler != nil {
+		pe.evictionHandler(pod, opts)
+	}
+
 	if pe.metricsEnabled {
 		metrics.PodsEvicted.With(map[string]string{"result": "success", "strategy": opts.StrategyName, "namespace": pod.Namespace, "node": pod.Spec.NodeName, "profile": opts.ProfileName}).Inc()
 	}
@@ -1,6 +1,9 @@
 package evictions
 
 import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
 	policy "k8s.io/api/policy/v1"
 )
 
@@ -11,8 +14,13 @@ type Options struct {
 	maxPodsToEvictPerNamespace *uint
 	maxPodsToEvictTotal        *uint
 	metricsEnabled             bool
+	evictionHandler            EvictionHandler
+	steeringTTL                time.Duration
 }
 
+// EvictionHandler is invoked for every pod evicted by the PodEvictor
+type EvictionHandler func(pod *v1.Pod, opts EvictOptions)
+
 // NewOptions returns an Options with default values.
 func NewOptions() *Options {
 	return &Options{
@@ -49,3 +57,15 @@ func (o *Options) WithMetricsEnabled(metricsEnabled bool) *Options {
 	o.metricsEnabled = metricsEnabled
 	return o
 }
+
+func (o *Options) WithEvictionHandler(evictionHandler EvictionHandler) *Options {
+	o.evictionHandler = evictionHandler
+	return o
+}
+
+// WithSteeringTTL enables the steering of the replacements of the pods evicted with a target
+// node hint, the node a pod is evicted from is tainted PreferNoSchedule for the given duration
+func (o *Options) WithSteeringTTL(steeringTTL time.Duration) *Options {
+	o.steeringTTL = steeringTTL
+	return o
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// Candidate is a pod proposed for eviction by one or more plugins
+type Candidate struct {
+	Pod *v1.Pod
+	// Options of the proposal with the highest score
+	Options EvictOptions
+	// Proposals counts how many times the pod was proposed
+	Proposals int
+
+	// order in which the pod was first proposed
+	order int
+}
+
+// EvictionPlan collects the pods plugins propose for eviction during a descheduling
+// cycle so they can be ranked across all profiles before any pod gets evicted.
+// The per node and per namespace limits are enforced when the pods are proposed so the plugins
+// stop proposing pods of a node or a namespace that would not be evicted anyway.
+// The total limit is only enforced when the plan is executed, on the highest ranked candidates.
+type EvictionPlan struct {
+	mu                         sync.Mutex
+	candidates                 map[types.UID]*Candidate
+	maxPodsToEvictPerNode      *uint
+	maxPodsToEvictPerNamespace *uint
+	nodePodCount               nodePodEvictedCount
+	namespacePodCount          namespacePodEvictCount
+}
+
+// NewEvictionPlan returns an empty EvictionPlan enforcing the given limits, nil for no limit
+func NewEvictionPlan(maxPodsToEvictPerNode, maxPodsToEvictPerNamespace *uint) *EvictionPlan {
+	return &EvictionPlan{
+		candidates:                 make(map[types.UID]*Candidate),
+		maxPodsToEvictPerNode:      maxPodsToEvictPerNode,
+		maxPodsToEvictPerNamespace: maxPodsToEvictPerNamespace,
+		nodePodCount:               make(nodePodEvictedCount),
+		namespacePodCount:          make(namespacePodEvictCount),
+	}
+}
+
+// Propose adds the pod to the plan. A pod proposed multiple times is kept once
+// with the options of its highest scored proposal. Proposing a new pod of a node or
+// a namespace whose limit is reached returns the same error evicting the pod would.
+func (ep *EvictionPlan) Propose(pod *v1.Pod, opts EvictOptions) error {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	if candidate, ok := ep.candidates[pod.UID]; ok {
+		candidate.Proposals++
+		if opts.Score > candidate.Options.Score {
+			candidate.Options = opts
+		}
+		klog.V(3).InfoS("Pod proposed for eviction again", "pod", klog.KObj(pod), "strategy", opts.StrategyName, "profile", opts.ProfileName, "proposals", candidate.Proposals)
+		return nil
+	}
+
+	if pod.Spec.NodeName != "" && ep.maxPodsToEvictPerNode != nil && ep.nodePodCount[pod.Spec.NodeName]+1 > *ep.maxPodsToEvictPerNode {
+		klog.V(3).InfoS("Pod not proposed for eviction, the limit of its node is reached", "pod", klog.KObj(pod), "limit", *ep.maxPodsToEvictPerNode, "node", pod.Spec.NodeName)
+		return NewEvictionNodeLimitError(pod.Spec.NodeName)
+	}
+	if ep.maxPodsToEvictPerNamespace != nil && ep.namespacePodCount[pod.Namespace]+1 > *ep.maxPodsToEvictPerNamespace {
+		klog.V(3).InfoS("Pod not proposed for eviction, the limit of its namespace is reached", "pod", klog.KObj(pod), "limit", *ep.maxPodsToEvictPerNamespace, "namespace", pod.Namespace)
+		return NewEvictionNamespaceLimitError(pod.Namespace)
+	}
+	if pod.Spec.NodeName != "" {
+		ep.nodePodCount[pod.Spec.NodeName]++
+	}
+	ep.namespacePodCount[pod.Namespace]++
+
+	ep.candidates[pod.UID] = &Candidate{
+		Pod:       pod,
+		Options:   opts,
+		Proposals: 1,
+		order:     len(ep.candidates),
+	}
+	klog.V(3).InfoS("Pod proposed for eviction", "pod", klog.KObj(pod), "reason", opts.Reason, "strategy", opts.StrategyName, "profile", opts.ProfileName, "score", opts.Score)
+	return nil
+}
+
+// CanPropose returns the error of the first per node or per namespace limit proposing all the pods
+// would exceed, nil when the pods can all be proposed. The pods already proposed are not counted again.
+func (ep *EvictionPlan) CanPropose(pods []*v1.Pod) error {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	var proposed []*v1.Pod
+	for _, pod := range pods {
+		if _, ok := ep.candidates[pod.UID]; !ok {
+			proposed = append(proposed, pod)
+		}
+	}
+	return exceededLimit(proposed, ep.nodePodCount, ep.namespacePodCount, ep.maxPodsToEvictPerNode, ep.maxPodsToEvictPerNamespace)
+}
+
+// Candidates returns the deduplicated candidates ranked by score from the highest.
+// Candidates with the same score are ranked by the number of proposals and
+// lastly by the order in which they were first proposed.
+func (ep *EvictionPlan) Candidates() []*Candidate {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+
+	candidates := make([]*Candidate, 0, len(ep.candidates))
+	for _, candidate := range ep.candidates {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Options.Score != candidates[j].Options.Score {
+			return candidates[i].Options.Score > candidates[j].Options.Score
+		}
+		if candidates[i].Proposals != candidates[j].Proposals {
+			return candidates[i].Proposals > candidates[j].Proposals
+		}
+		return candidates[i].order < candidates[j].order
+	})
+	return candidates
+}
+
+// Execute evicts the ranked candidates while exercising the eviction limits of the pod evictor.
+func (ep *EvictionPlan) Execute(ctx context.Context, podEvictor *PodEvictor) {
+	for _, candidate := range ep.Candidates() {
+		err := podEvictor.EvictPod(ctx, candidate.Pod, candidate.Options)
+		if err == nil {
+			continue
+		}
+		switch err.(type) {
+		case *EvictionTotalLimitError:
+			return
+		case *EvictionNodeLimitError, *EvictionNamespaceLimitError:
+			// node and namespace limits only rule out candidates of the same node or namespace
+		default:
+			klog.ErrorS(err, "Unable to evict a candidate of the eviction plan", "pod", klog.KObj(candidate.Pod), "strategy", candidate.Options.StrategyName, "profile", candidate.Options.ProfileName)
+		}
+	}
+}
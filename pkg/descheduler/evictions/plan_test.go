@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+	utilptr "k8s.io/utils/ptr"
+
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestEvictionPlan(t *testing.T) {
+	p1 := test.BuildTestPod("p1", 100, 0, "n1", nil)
+	p2 := test.BuildTestPod("p2", 100, 0, "n1", nil)
+	p3 := test.BuildTestPod("p3", 100, 0, "n2", nil)
+	p4 := test.BuildTestPod("p4", 100, 0, "n2", nil)
+
+	tests := []struct {
+		description         string
+		maxPodsToEvictTotal *uint
+		expectedCandidates  []string
+		expectedEvictions   []string
+	}{
+		{
+			description:        "candidates are ranked by score, proposals and order",
+			expectedCandidates: []string{"p3", "p2", "p4", "p1"},
+			expectedEvictions:  []string{"p3", "p2", "p4", "p1"},
+		},
+		{
+			description:         "the total limit is spent on the highest ranked candidates",
+			maxPodsToEvictTotal: utilptr.To[uint](2),
+			expectedCandidates:  []string{"p3", "p2", "p4", "p1"},
+			expectedEvictions:   []string{"p3", "p2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			plan := NewEvictionPlan(nil, nil)
+			mustPropose(t, plan, p1, EvictOptions{StrategyName: "A", ProfileName: "first"})
+			mustPropose(t, plan, p2, EvictOptions{StrategyName: "A", ProfileName: "first", Score: 0.2})
+			mustPropose(t, plan, p4, EvictOptions{StrategyName: "A", ProfileName: "first"})
+			mustPropose(t, plan, p3, EvictOptions{StrategyName: "B", ProfileName: "second", Reason: "important", Score: MaxScore})
+			// p4 proposed twice ranks above p1 with the same score
+			mustPropose(t, plan, p4, EvictOptions{StrategyName: "B", ProfileName: "second"})
+			// a lower score of a repeated proposal does not lower the rank
+			mustPropose(t, plan, p3, EvictOptions{StrategyName: "A", ProfileName: "first", Score: 0.5})
+
+			candidates := plan.Candidates()
+			names := []string{}
+			for _, candidate := range candidates {
+				names = append(names, candidate.Pod.Name)
+			}
+			if diff := cmp.Diff(tc.expectedCandidates, names); diff != "" {
+				t.Errorf("unexpected candidates (-want +got):\n%s", diff)
+			}
+			if candidates[0].Options.Reason != "important" || candidates[0].Proposals != 2 {
+				t.Errorf("expected p3 to keep the options of the highest scored proposal, got %+v", candidates[0])
+			}
+
+			fakeClient := fake.NewSimpleClientset(p1, p2, p3, p4)
+			evictedPods := []string{}
+			fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "eviction" {
+					if eviction, ok := action.(core.CreateAction).GetObject().(*policy.Eviction); ok {
+						evictedPods = append(evictedPods, eviction.Name)
+					}
+				}
+				return false, nil, nil
+			})
+
+			podEvictor := NewPodEvictor(
+				fakeClient,
+				&events.FakeRecorder{},
+				NewOptions().WithMaxPodsToEvictTotal(tc.maxPodsToEvictTotal),
+			)
+			plan.Execute(context.TODO(), podEvictor)
+
+			if diff := cmp.Diff(tc.expectedEvictions, evictedPods); diff != "" {
+				t.Errorf("unexpected evictions (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEvictionPlanLimits(t *testing.T) {
+	p1 := test.BuildTestPod("p1", 100, 0, "n1", nil)
+	p2 := test.BuildTestPod("p2", 100, 0, "n1", nil)
+	p3 := test.BuildTestPod("p3", 100, 0, "n2", nil)
+	p4 := test.BuildTestPod("p4", 100, 0, "n2", nil)
+	p4.Namespace = "other"
+
+	plan := NewEvictionPlan(utilptr.To[uint](1), utilptr.To[uint](2))
+	mustPropose(t, plan, p1, EvictOptions{})
+	// proposing a candidate again does not count against the limits
+	mustPropose(t, plan, p1, EvictOptions{})
+
+	if _, ok := plan.Propose(p2, EvictOptions{}).(*EvictionNodeLimitError); !ok {
+		t.Errorf("expected an EvictionNodeLimitError proposing a second pod of n1")
+	}
+	mustPropose(t, plan, p3, EvictOptions{})
+	if _, ok := plan.Propose(p4, EvictOptions{}).(*EvictionNodeLimitError); !ok {
+		t.Errorf("expected an EvictionNodeLimitError proposing a second pod of n2")
+	}
+
+	// p1 is proposed already, p2 would be the second pod of n1
+	if err := plan.CanPropose([]*v1.Pod{p1}); err != nil {
+		t.Errorf("Expected the proposed pods to be accepted again, got %v", err)
+	}
+	if _, ok := plan.CanPropose([]*v1.Pod{p1, p2}).(*EvictionNodeLimitError); !ok {
+		t.Errorf("Expected an EvictionNodeLimitError checking a second pod of n1")
+	}
+
+	plan = NewEvictionPlan(nil, utilptr.To[uint](1))
+	mustPropose(t, plan, p1, EvictOptions{})
+	if _, ok := plan.Propose(p3, EvictOptions{}).(*EvictionNamespaceLimitError); !ok {
+		t.Errorf("expected an EvictionNamespaceLimitError proposing a second pod of the namespace")
+	}
+	mustPropose(t, plan, p4, EvictOptions{})
+
+	if got := len(plan.Candidates()); got != 2 {
+		t.Errorf("expected 2 candidates, got %v", got)
+	}
+}
+
+func mustPropose(t *testing.T, plan *EvictionPlan, pod *v1.Pod, opts EvictOptions) {
+	t.Helper()
+	if err := plan.Propose(pod, opts); err != nil {
+		t.Fatalf("unexpected error proposing pod %v: %v", pod.Name, err)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"context"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SteeringTaintKey is the key of the PreferNoSchedule taint keeping the replacements
+	// of the evicted pods away from the node the pods were evicted from
+	SteeringTaintKey = "descheduler.alpha.kubernetes.io/steering"
+	// SteeringExpiresAnnotationKey records on the node when its steering taint is removed
+	SteeringExpiresAnnotationKey = "descheduler.alpha.kubernetes.io/steering-expires"
+)
+
+// steeringBackoff retries the node updates conflicting with another writer of the node,
+// as retry.DefaultRetry of client-go does
+var steeringBackoff = wait.Backoff{
+	Steps:    5,
+	Duration: 10 * time.Millisecond,
+	Factor:   1.0,
+	Jitter:   0.1,
+}
+
+// updateNode applies the change to the latest version of the node and updates it,
+// the update is retried with the latest version of the node on a conflict
+func updateNode(ctx context.Context, client clientset.Interface, nodeName string, change func(node *v1.Node)) error {
+	var lastErr error
+	err := wait.ExponentialBackoffWithContext(ctx, steeringBackoff, func(ctx context.Context) (bool, error) {
+		node, err := client.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		updated := node.DeepCopy()
+		change(updated)
+		_, lastErr = client.CoreV1().Nodes().Update(ctx, updated, metav1.UpdateOptions{})
+		if apierrors.IsConflict(lastErr) {
+			return false, nil
+		}
+		return true, lastErr
+	})
+	if wait.Interrupted(err) && lastErr != nil {
+		return lastErr
+	}
+	return err
+}
+
+// steerAway taints the node with the steering taint, so the scheduler prefers the other
+// nodes for the replacements of the pods evicted from it. The expiry of the taint is
+// extended when the node is already tainted. Returns whether the taint was added.
+func steerAway(ctx context.Context, client clientset.Interface, nodeName string, expires time.Time) (bool, error) {
+	tainted := false
+	err := updateNode(ctx, client, nodeName, func(node *v1.Node) {
+		tainted = !hasSteeringTaint(node)
+		if tainted {
+			node.Spec.Taints = append(node.Spec.Taints, v1.Taint{
+				Key:    SteeringTaintKey,
+				Effect: v1.TaintEffectPreferNoSchedule,
+			})
+		}
+		if node.Annotations == nil {
+			node.Annotations = map[string]string{}
+		}
+		node.Annotations[SteeringExpiresAnnotationKey] = expires.UTC().Format(time.RFC3339)
+	})
+	return tainted, err
+}
+
+// revertSteering removes the steering taint and its expiry from the node
+func revertSteering(ctx context.Context, client clientset.Interface, nodeName string) error {
+	return updateNode(ctx, client, nodeName, func(node *v1.Node) {
+		taints := node.Spec.Taints[:0]
+		for _, taint := range node.Spec.Taints {
+			if taint.Key != SteeringTaintKey {
+				taints = append(taints, taint)
+			}
+		}
+		node.Spec.Taints = taints
+		delete(node.Annotations, SteeringExpiresAnnotationKey)
+	})
+}
+
+// steeringExpired checks whether the steering of the node expired. A node with the steering
+// taint and without a readable expiry is considered expired so the taint is not kept forever.
+func steeringExpired(node *v1.Node, now time.Time) bool {
+	value, ok := node.Annotations[SteeringExpiresAnnotationKey]
+	if !ok {
+		return hasSteeringTaint(node)
+	}
+	expires, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		klog.V(1).InfoS("Unable to parse the steering expiry of the node", "node", klog.KObj(node), "value", value, "err", err)
+		return true
+	}
+	return !now.Before(expires)
+}
+
+func hasSteeringTaint(node *v1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == SteeringTaintKey {
+			return true
+		}
+	}
+	return false
+}
+
+// steer taints the node the pod is evicted from when the eviction carries a target node hint,
+// a failure only logs as the hint does not keep the pod from being evicted.
+// The node is tainted at most once per cycle, the node is updated without holding the lock.
+// Returns whether the node is steered and whether the taint was added by this eviction,
+// so the steering can be reverted when the eviction fails.
+func (pe *PodEvictor) steer(ctx context.Context, pod *v1.Pod, opts EvictOptions) (bool, bool) {
+	pe.mu.Lock()
+	if pe.steeringTTL <= 0 || pe.dryRun || opts.TargetNode == "" ||
+		pod.Spec.NodeName == "" || pod.Spec.NodeName == opts.TargetNode ||
+		// only the pods recreated by their controller have a replacement to steer
+		len(pod.OwnerReferences) == 0 ||
+		pe.steeredNodes[pod.Spec.NodeName] ||
+		// the pod the limits keep from being evicted has no replacement to steer
+		pe.exceededLimits([]*v1.Pod{pod}) != nil {
+		pe.mu.Unlock()
+		return false, false
+	}
+	pe.steeredNodes[pod.Spec.NodeName] = true
+	client := pe.client
+	pe.mu.Unlock()
+
+	expires := time.Now().Add(pe.steeringTTL)
+	tainted, err := steerAway(ctx, client, pod.Spec.NodeName, expires)
+	if err != nil {
+		klog.ErrorS(err, "Unable to steer the replacement of the pod away from its node", "pod", klog.KObj(pod), "node", pod.Spec.NodeName, "targetNode", opts.TargetNode)
+		pe.mu.Lock()
+		delete(pe.steeredNodes, pod.Spec.NodeName)
+		pe.mu.Unlock()
+		return false, false
+	}
+	klog.V(3).InfoS("Steering the replacement of the pod away from its node", "pod", klog.KObj(pod), "node", pod.Spec.NodeName, "targetNode", opts.TargetNode, "expires", expires)
+	return true, tainted
+}
+
+// unsteer forgets the steering of the node of a pod whose eviction failed, so the node can be
+// steered again by the next eviction, and removes the steering taint when the eviction added it.
+// An expiry extended by the eviction is kept.
+func (pe *PodEvictor) unsteer(ctx context.Context, pod *v1.Pod, tainted bool) {
+	pe.mu.Lock()
+	delete(pe.steeredNodes, pod.Spec.NodeName)
+	client := pe.client
+	pe.mu.Unlock()
+	if !tainted {
+		return
+	}
+
+	if err := revertSteering(ctx, client, pod.Spec.NodeName); err != nil {
+		klog.ErrorS(err, "Unable to revert the steering of the node of the pod not evicted", "pod", klog.KObj(pod), "node", pod.Spec.NodeName)
+		return
+	}
+	klog.V(3).InfoS("Reverted the steering of the node of the pod not evicted", "pod", klog.KObj(pod), "node", pod.Spec.NodeName)
+}
+
+// RevertExpiredSteering removes the steering taints whose TTL expired from the nodes.
+// The expiry is read from the nodes so the taints added before a restart are reverted too.
+func (pe *PodEvictor) RevertExpiredSteering(ctx context.Context, nodes []*v1.Node) {
+	pe.mu.Lock()
+	dryRun, client := pe.dryRun, pe.client
+	pe.mu.Unlock()
+	if dryRun {
+		return
+	}
+
+	now := time.Now()
+	for _, node := range nodes {
+		if !hasSteeringTaint(node) && node.Annotations[SteeringExpiresAnnotationKey] == "" {
+			continue
+		}
+		if !steeringExpired(node, now) {
+			continue
+		}
+		if err := revertSteering(ctx, client, node.Name); err != nil {
+			klog.ErrorS(err, "Unable to revert the steering of the node", "node", klog.KObj(node))
+			continue
+		}
+		klog.V(3).InfoS("Reverted the expired steering of the node", "node", klog.KObj(node))
+	}
+}
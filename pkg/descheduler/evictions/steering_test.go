@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evictions
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestEvictPodSteering(t *testing.T) {
+	tests := []struct {
+		description string
+		steeringTTL time.Duration
+		dryRun      bool
+		targetNode  string
+		apply       func(*v1.Pod)
+		wantTainted bool
+	}{
+		{
+			description: "the node is tainted when the eviction has a target node",
+			steeringTTL: time.Minute,
+			targetNode:  "node2",
+			apply:       test.SetRSOwnerRef,
+			wantTainted: true,
+		},
+		{
+			description: "the node is not tainted without a target node",
+			steeringTTL: time.Minute,
+			apply:       test.SetRSOwnerRef,
+		},
+		{
+			description: "the node is not tainted without a steering TTL",
+			targetNode:  "node2",
+			apply:       test.SetRSOwnerRef,
+		},
+		{
+			description: "the node is not tainted in the dry run mode",
+			steeringTTL: time.Minute,
+			dryRun:      true,
+			targetNode:  "node2",
+			apply:       test.SetRSOwnerRef,
+		},
+		{
+			description: "the node is not tainted for a pod without a controller",
+			steeringTTL: time.Minute,
+			targetNode:  "node2",
+		},
+		{
+			description: "the node is not tainted when the target node is the node of the pod",
+			steeringTTL: time.Minute,
+			targetNode:  "node1",
+			apply:       test.SetRSOwnerRef,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			node := test.BuildTestNode("node1", 1000, 2000, 9, nil)
+			pod := test.BuildTestPod("p1", 400, 0, "node1", tc.apply)
+			fakeClient := fake.NewSimpleClientset(node, pod)
+
+			podEvictor := NewPodEvictor(
+				fakeClient,
+				&events.FakeRecorder{},
+				NewOptions().WithDryRun(tc.dryRun).WithSteeringTTL(tc.steeringTTL),
+			)
+			if err := podEvictor.EvictPod(ctx, pod, EvictOptions{TargetNode: tc.targetNode}); err != nil {
+				t.Fatalf("Unexpected eviction error: %v", err)
+			}
+
+			updated, err := fakeClient.CoreV1().Nodes().Get(ctx, "node1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get the node: %v", err)
+			}
+			if tainted := hasSteeringTaint(updated); tainted != tc.wantTainted {
+				t.Errorf("Expected the node tainted to be %v, got %v", tc.wantTainted, tainted)
+			}
+			if _, ok := updated.Annotations[SteeringExpiresAnnotationKey]; ok != tc.wantTainted {
+				t.Errorf("Expected the node to have the steering expiry to be %v, got %v", tc.wantTainted, ok)
+			}
+		})
+	}
+}
+
+func TestEvictPodSteeringUpdates(t *testing.T) {
+	tests := []struct {
+		description    string
+		alreadySteered bool
+		updateConflict bool
+		evictionErr    error
+		wantErr        bool
+		wantTainted    bool
+	}{
+		{
+			description:    "the node update is retried on a conflict",
+			updateConflict: true,
+			wantTainted:    true,
+		},
+		{
+			description: "the taint is reverted when the eviction fails",
+			evictionErr: apierrors.NewTooManyRequests("disruption budget", 1),
+			wantErr:     true,
+		},
+		{
+			description:    "the taint of a node steered before is kept when the eviction fails",
+			alreadySteered: true,
+			evictionErr:    apierrors.NewTooManyRequests("disruption budget", 1),
+			wantErr:        true,
+			wantTainted:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			node := test.BuildTestNode("node1", 1000, 2000, 9, func(node *v1.Node) {
+				if tc.alreadySteered {
+					node.Spec.Taints = []v1.Taint{{Key: SteeringTaintKey, Effect: v1.TaintEffectPreferNoSchedule}}
+				}
+			})
+			pod := test.BuildTestPod("p1", 400, 0, "node1", test.SetRSOwnerRef)
+			fakeClient := fake.NewSimpleClientset(node, pod)
+			conflicts := 0
+			fakeClient.PrependReactor("update", "nodes", func(action core.Action) (bool, runtime.Object, error) {
+				if tc.updateConflict && conflicts == 0 {
+					conflicts++
+					return true, nil, apierrors.NewConflict(v1.Resource("nodes"), "node1", nil)
+				}
+				return false, nil, nil
+			})
+			fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "eviction" && tc.evictionErr != nil {
+					return true, nil, tc.evictionErr
+				}
+				return false, nil, nil
+			})
+
+			podEvictor := NewPodEvictor(fakeClient, &events.FakeRecorder{}, NewOptions().WithSteeringTTL(time.Minute))
+			err := podEvictor.EvictPod(ctx, pod, EvictOptions{TargetNode: "node2"})
+			if hasErr := err != nil; hasErr != tc.wantErr {
+				t.Fatalf("Expected eviction error %v, got %v", tc.wantErr, err)
+			}
+
+			updated, err := fakeClient.CoreV1().Nodes().Get(ctx, "node1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get the node: %v", err)
+			}
+			if tainted := hasSteeringTaint(updated); tainted != tc.wantTainted {
+				t.Errorf("Expected the node tainted to be %v, got %v", tc.wantTainted, tainted)
+			}
+			if steered := podEvictor.steeredNodes["node1"]; steered == tc.wantErr {
+				t.Errorf("Expected the node steered in the cycle to be %v, got %v", !tc.wantErr, steered)
+			}
+		})
+	}
+}
+
+func TestRevertExpiredSteering(t *testing.T) {
+	steered := func(expires string) func(*v1.Node) {
+		return func(node *v1.Node) {
+			node.Spec.Taints = []v1.Taint{
+				{Key: "dedicated", Value: "db", Effect: v1.TaintEffectNoSchedule},
+				{Key: SteeringTaintKey, Effect: v1.TaintEffectPreferNoSchedule},
+			}
+			if expires != "" {
+				node.Annotations = map[string]string{SteeringExpiresAnnotationKey: expires}
+			}
+		}
+	}
+
+	now := time.Now()
+	tests := []struct {
+		description string
+		node        *v1.Node
+		wantTainted bool
+	}{
+		{
+			description: "expired steering is reverted",
+			node:        test.BuildTestNode("node1", 1000, 2000, 9, steered(now.Add(-time.Minute).UTC().Format(time.RFC3339))),
+		},
+		{
+			description: "steering is kept until it expires",
+			node:        test.BuildTestNode("node1", 1000, 2000, 9, steered(now.Add(time.Hour).UTC().Format(time.RFC3339))),
+			wantTainted: true,
+		},
+		{
+			description: "steering without an expiry is reverted",
+			node:        test.BuildTestNode("node1", 1000, 2000, 9, steered("")),
+		},
+		{
+			description: "steering with an invalid expiry is reverted",
+			node:        test.BuildTestNode("node1", 1000, 2000, 9, steered("tomorrow")),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx := context.Background()
+			fakeClient := fake.NewSimpleClientset(tc.node)
+			podEvictor := NewPodEvictor(fakeClient, &events.FakeRecorder{}, NewOptions().WithSteeringTTL(time.Minute))
+
+			podEvictor.RevertExpiredSteering(ctx, []*v1.Node{tc.node})
+
+			updated, err := fakeClient.CoreV1().Nodes().Get(ctx, "node1", metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get the node: %v", err)
+			}
+			if tainted := hasSteeringTaint(updated); tainted != tc.wantTainted {
+				t.Errorf("Expected the node tainted to be %v, got %v", tc.wantTainted, tainted)
+			}
+			if _, ok := updated.Annotations[SteeringExpiresAnnotationKey]; ok != tc.wantTainted {
+				t.Errorf("Expected the node to have the steering expiry to be %v, got %v", tc.wantTainted, ok)
+			}
+			// the other taints of the node are kept
+			if len(updated.Spec.Taints) == 0 || updated.Spec.Taints[0].Key != "dedicated" {
+				t.Errorf("Expected the other taints to be kept, got %v", updated.Spec.Taints)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+	frameworkprofile "github.com/amit3512/descheduler_policy_master/pkg/framework/profile"
+)
+
+// ProfileExplanation is the outcome of the Filter and PreEvictionFilter plugins of a profile for a pod
+type ProfileExplanation struct {
+	Profile string
+	// Err is set when the profile could not be built
+	Err      error
+	Verdicts []frameworkprofile.EvictorVerdict
+}
+
+// Evictable tells whether every Filter and PreEvictionFilter plugin of the profile accepts the pod
+func (p ProfileExplanation) Evictable() bool {
+	if p.Err != nil {
+		return false
+	}
+	for _, verdict := range p.Verdicts {
+		if !verdict.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// ExplainPod builds every profile of the policy and runs all their Filter and PreEvictionFilter
+// plugins against the pod, so each reason the pod is not evictable gets reported. Nothing is evicted.
+func ExplainPod(ctx context.Context, client clientset.Interface, deschedulerPolicy *api.DeschedulerPolicy, namespace, name string) (*v1.Pod, []ProfileExplanation, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get pod %s/%s: %v", namespace, name, err)
+	}
+
+	sharedInformerFactory := newSharedInformerFactory(client)
+	getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(sharedInformerFactory.Core().V1().Pods().Informer())
+	if err != nil {
+		return nil, nil, fmt.Errorf("build get pods assigned to node function error: %v", err)
+	}
+	// the plugins list the nodes once the informers are running
+	sharedInformerFactory.Core().V1().Nodes().Informer()
+	podEvictor := evictions.NewPodEvictor(client, &events.FakeRecorder{}, evictions.NewOptions().WithDryRun(true))
+
+	explanations := make([]ProfileExplanation, 0, len(deschedulerPolicy.Profiles))
+	explainFncs := make([]func(pod *v1.Pod) []frameworkprofile.EvictorVerdict, len(deschedulerPolicy.Profiles))
+	for i, profile := range deschedulerPolicy.Profiles {
+		currProfile, err := frameworkprofile.NewProfile(
+			profile,
+			pluginregistry.PluginRegistry,
+			frameworkprofile.WithClientSet(client),
+			frameworkprofile.WithSharedInformerFactory(sharedInformerFactory),
+			frameworkprofile.WithPodEvictor(podEvictor),
+			frameworkprofile.WithGetPodsAssignedToNodeFnc(getPodsAssignedToNode),
+			frameworkprofile.WithContext(ctx),
+		)
+		explanations = append(explanations, ProfileExplanation{Profile: profile.Name, Err: err})
+		if err == nil {
+			explainFncs[i] = currProfile.ExplainPod
+		}
+	}
+
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+	for i, explain := range explainFncs {
+		if explain != nil {
+			explanations[i].Verdicts = explain(pod)
+		}
+	}
+	return pod, explanations, nil
+}
+
+// WriteExplanation prints the verdict of every plugin and the outcome of every check
+// the plugins report, the failed ones first
+func WriteExplanation(out io.Writer, pod *v1.Pod, explanations []ProfileExplanation) error {
+	nodeName := pod.Spec.NodeName
+	if nodeName == "" {
+		nodeName = "<none>"
+	}
+	if _, err := fmt.Fprintf(out, "Pod %s/%s on node %s\n", pod.Namespace, pod.Name, nodeName); err != nil {
+		return err
+	}
+	for _, explanation := range explanations {
+		if explanation.Err != nil {
+			fmt.Fprintf(out, "\nProfile %q: unable to build the profile: %v\n", explanation.Profile, explanation.Err)
+			continue
+		}
+		evictable := "evictable"
+		if !explanation.Evictable() {
+			evictable = "not evictable"
+		}
+		fmt.Fprintf(out, "\nProfile %q: %s\n", explanation.Profile, evictable)
+		for _, verdict := range explanation.Verdicts {
+			outcome := "passed"
+			if !verdict.Passed {
+				outcome = "failed"
+			}
+			fmt.Fprintf(out, "  %s %s: %s\n", verdict.ExtensionPoint, verdict.Plugin, outcome)
+			for _, check := range verdict.Checks {
+				if check.Err != nil {
+					fmt.Fprintf(out, "    FAIL %s: %v\n", check.Name, check.Err)
+				}
+			}
+			for _, check := range verdict.Checks {
+				if check.Err == nil {
+					fmt.Fprintf(out, "    ok   %s\n", check.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+const explainPolicy = `
+apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+profiles:
+  - name: strict
+    pluginConfig:
+    - name: "DefaultEvictor"
+      args:
+        nodeFit: true
+        priorityThreshold:
+          value: 800
+    - name: "RemovePodsViolatingNodeTaints"
+    plugins:
+      deschedule:
+        enabled:
+          - "RemovePodsViolatingNodeTaints"
+  - name: permissive
+    pluginConfig:
+    - name: "DefaultEvictor"
+      args:
+        evictLocalStoragePods: true
+        evictFailedBarePods: true
+    - name: "RemovePodsViolatingNodeTaints"
+    plugins:
+      deschedule:
+        enabled:
+          - "RemovePodsViolatingNodeTaints"
+`
+
+func TestExplainPod(t *testing.T) {
+	SetupPlugins()
+
+	node1 := test.BuildTestNode("n1", 2000, 3000, 10, nil)
+	node2 := test.BuildTestNode("n2", 2000, 3000, 10, func(node *v1.Node) {
+		node.Spec.Taints = []v1.Taint{{Key: "key", Value: "value", Effect: v1.TaintEffectNoSchedule}}
+	})
+	priority := int32(900)
+	pod := test.BuildTestPod("p1", 100, 0, node1.Name, func(pod *v1.Pod) {
+		pod.Spec.Priority = &priority
+		pod.Status.Phase = v1.PodFailed
+		pod.Spec.Volumes = []v1.Volume{{
+			Name:         "sample",
+			VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		}}
+	})
+	client := fakeclientset.NewSimpleClientset(node1, node2, pod)
+
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policyFile, []byte(explainPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := LoadPolicyConfig(policyFile, client, pluginregistry.PluginRegistry)
+	if err != nil {
+		t.Fatalf("Unable to load the policy: %v", err)
+	}
+
+	explainedPod, explanations, err := ExplainPod(context.Background(), client, policy, pod.Namespace, pod.Name)
+	if err != nil {
+		t.Fatalf("Unable to explain the pod: %v", err)
+	}
+	if len(explanations) != 2 {
+		t.Fatalf("Expected an explanation per profile, got %v", explanations)
+	}
+	if explanations[0].Evictable() || !explanations[1].Evictable() {
+		t.Errorf("Expected the pod to be evictable by the permissive profile only, got %+v", explanations)
+	}
+
+	out := &bytes.Buffer{}
+	if err := WriteExplanation(out, explainedPod, explanations); err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"Pod default/p1 on node n1",
+		`Profile "strict": not evictable`,
+		"  Filter DefaultEvictor: failed",
+		"    FAIL ownerRefs: pod does not have any ownerRefs",
+		"    FAIL priorityThreshold: pod has higher priority than specified priority class threshold",
+		"    FAIL localStorage: pod has local storage and descheduler is not configured with evictLocalStoragePods",
+		"    ok   mirrorPod",
+		"  PreEvictionFilter DefaultEvictor: failed",
+		"    FAIL nodeFit: pod does not fit on any other node",
+		`Profile "permissive": evictable`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the explanation:\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+
+	yamlv3 "gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api/v1alpha1"
+	"github.com/amit3512/descheduler_policy_master/pkg/api/v1alpha2"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+)
+
+// MigratePolicyFile converts a v1alpha1 policy file into an equivalent v1alpha2 policy
+func MigratePolicyFile(policyConfigFile string) (*v1alpha2.DeschedulerPolicy, error) {
+	policy, err := os.ReadFile(policyConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy config file %q: %+v", policyConfigFile, err)
+	}
+	return migratePolicy(policyConfigFile, policy)
+}
+
+// migratePolicy converts the strategies of a v1alpha1 policy into profiles the same way the
+// descheduler does when loading the policy, each profile with an explicit DefaultEvictor
+// configuration carrying the top level options of the v1alpha1 policy.
+func migratePolicy(policyConfigFile string, policy []byte) (*v1alpha2.DeschedulerPolicy, error) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := yaml.Unmarshal(policy, typeMeta); err != nil {
+		return nil, fmt.Errorf("failed decoding descheduler's policy config %q: %v", policyConfigFile, err)
+	}
+	if typeMeta.APIVersion != v1alpha1.SchemeGroupVersion.String() {
+		return nil, fmt.Errorf("policy config %q is not a %s policy: %q", policyConfigFile, v1alpha1.SchemeGroupVersion, typeMeta.APIVersion)
+	}
+
+	internalPolicy, err := decodePolicy(policyConfigFile, policy)
+	if err != nil {
+		return nil, err
+	}
+	// the strategies are kept in a map, the profiles get ordered to keep the output stable
+	sort.Slice(internalPolicy.Profiles, func(i, j int) bool {
+		return internalPolicy.Profiles[i].Name < internalPolicy.Profiles[j].Name
+	})
+
+	out := &v1alpha2.DeschedulerPolicy{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: v1alpha2.SchemeGroupVersion.String(),
+			Kind:       "DeschedulerPolicy",
+		},
+		NodeSelector:                   internalPolicy.NodeSelector,
+		MaxNoOfPodsToEvictPerNode:      internalPolicy.MaxNoOfPodsToEvictPerNode,
+		MaxNoOfPodsToEvictPerNamespace: internalPolicy.MaxNoOfPodsToEvictPerNamespace,
+		MaxNoOfPodsToEvictTotal:        internalPolicy.MaxNoOfPodsToEvictTotal,
+	}
+	for i := range internalPolicy.Profiles {
+		profile := v1alpha2.DeschedulerProfile{}
+		if err := v1alpha2.Convert_api_DeschedulerProfile_To_v1alpha2_DeschedulerProfile(&internalPolicy.Profiles[i], &profile, nil); err != nil {
+			return nil, fmt.Errorf("failed converting profile %q: %v", internalPolicy.Profiles[i].Name, err)
+		}
+		for j := range profile.PluginConfigs {
+			args := profile.PluginConfigs[j].Args.Object
+			if args == nil {
+				continue
+			}
+			// an empty threshold means the system critical priority, the same as no threshold
+			if evictorArgs, ok := args.(*defaultevictor.DefaultEvictorArgs); ok && evictorArgs.PriorityThreshold != nil &&
+				evictorArgs.PriorityThreshold.Value == nil && evictorArgs.PriorityThreshold.Name == "" {
+				evictorArgs.PriorityThreshold = nil
+			}
+			raw, err := json.Marshal(args)
+			if err == nil {
+				raw, err = pruneArgs(raw, func() interface{} { return reflect.New(reflect.TypeOf(args).Elem()).Interface() })
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed encoding args of plugin %q in profile %q: %v", profile.PluginConfigs[j].Name, profile.Name, err)
+			}
+			profile.PluginConfigs[j].Args.Raw = raw
+		}
+		out.Profiles = append(out.Profiles, profile)
+	}
+	return out, nil
+}
+
+// pruneArgs drops every field of the args decoding the same without it, e.g. fields
+// set to their zero value, so only the meaningful options are left
+func pruneArgs(raw []byte, newArgs func() interface{}) ([]byte, error) {
+	expected := newArgs()
+	if err := json.Unmarshal(raw, expected); err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	decodesSame := func() bool {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return false
+		}
+		got := newArgs()
+		return json.Unmarshal(data, got) == nil && reflect.DeepEqual(expected, got)
+	}
+	pruneFields(fields, decodesSame)
+	return json.Marshal(fields)
+}
+
+func pruneFields(fields map[string]interface{}, decodesSame func() bool) {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := fields[key]
+		if nested, ok := value.(map[string]interface{}); ok {
+			pruneFields(nested, decodesSame)
+		}
+		delete(fields, key)
+		if !decodesSame() {
+			fields[key] = value
+		}
+	}
+}
+
+// WritePolicy writes a v1alpha2 policy as YAML, leaving out empty fields
+func WritePolicy(out io.Writer, policy *v1alpha2.DeschedulerPolicy) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("unable to encode the policy: %v", err)
+	}
+	// JSON is YAML, decoding into a node keeps the order of the fields
+	root := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(data, root); err != nil {
+		return fmt.Errorf("unable to encode the policy: %v", err)
+	}
+	pruneEmptyNodes(root)
+
+	encoder := yamlv3.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("unable to encode the policy: %v", err)
+	}
+	return encoder.Close()
+}
+
+// pruneEmptyNodes drops null values and empty mappings, and switches to the block style
+func pruneEmptyNodes(node *yamlv3.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		pruneEmptyNodes(child)
+	}
+	if node.Kind != yamlv3.MappingNode {
+		return
+	}
+	var content []*yamlv3.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		value := node.Content[i+1]
+		if value.Tag == "!!null" || (value.Kind == yamlv3.MappingNode && len(value.Content) == 0) {
+			continue
+		}
+		content = append(content, node.Content[i], value)
+	}
+	node.Content = content
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+)
+
+func TestMigratePolicy(t *testing.T) {
+	SetupPlugins()
+
+	policy := []byte(`apiVersion: "descheduler/v1alpha1"
+kind: "DeschedulerPolicy"
+evictLocalStoragePods: true
+ignorePvcPods: true
+maxNoOfPodsToEvictPerNode: 5
+maxNoOfPodsToEvictTotal: 20
+strategies:
+  "LowNodeUtilization":
+    enabled: true
+    params:
+      nodeResourceUtilizationThresholds:
+        thresholds:
+          cpu: 20
+        targetThresholds:
+          cpu: 50
+  "PodLifeTime":
+    enabled: true
+    params:
+      thresholdPriority: 100
+      podLifeTime:
+        maxPodLifeTimeSeconds: 86400
+      namespaces:
+        include: ["dev"]
+  "RemoveDuplicates":
+    enabled: false
+`)
+
+	expected := `kind: DeschedulerPolicy
+apiVersion: descheduler/v1alpha2
+profiles:
+  - name: strategy-LowNodeUtilization-profile
+    pluginConfig:
+      - name: DefaultEvictor
+        args:
+          evictLocalStoragePods: true
+          ignorePvcPods: true
+      - name: LowNodeUtilization
+        args:
+          targetThresholds:
+            cpu: 50
+          thresholds:
+            cpu: 20
+    plugins:
+      balance:
+        enabled:
+          - LowNodeUtilization
+      filter:
+        enabled:
+          - DefaultEvictor
+      preevictionfilter:
+        enabled:
+          - DefaultEvictor
+  - name: strategy-PodLifeTime-profile
+    pluginConfig:
+      - name: DefaultEvictor
+        args:
+          evictLocalStoragePods: true
+          ignorePvcPods: true
+          priorityThreshold:
+            value: 100
+      - name: PodLifeTime
+        args:
+          maxPodLifeTimeSeconds: 86400
+          namespaces:
+            include:
+              - dev
+    plugins:
+      deschedule:
+        enabled:
+          - PodLifeTime
+      filter:
+        enabled:
+          - DefaultEvictor
+      preevictionfilter:
+        enabled:
+          - DefaultEvictor
+maxNoOfPodsToEvictPerNode: 5
+maxNoOfPodsToEvictTotal: 20
+`
+
+	migrated, err := migratePolicy("policy.yaml", policy)
+	if err != nil {
+		t.Fatalf("Unable to migrate the policy: %v", err)
+	}
+	out := &bytes.Buffer{}
+	if err := WritePolicy(out, migrated); err != nil {
+		t.Fatalf("Unable to write the policy: %v", err)
+	}
+	if diff := cmp.Diff(expected, out.String()); diff != "" {
+		t.Errorf("Unexpected migrated policy (-want +got):\n%s", diff)
+	}
+
+	if problems := validatePolicy("migrated.yaml", out.Bytes(), pluginregistry.PluginRegistry); len(problems) != 0 {
+		t.Errorf("Expected the migrated policy to be valid, got %v", problems)
+	}
+
+	// both policies are expected to load into the same configuration
+	client := fakeclientset.NewSimpleClientset()
+	original, err := decode("policy.yaml", policy, client, pluginregistry.PluginRegistry)
+	if err != nil {
+		t.Fatalf("Unable to decode the policy: %v", err)
+	}
+	result, err := decode("migrated.yaml", out.Bytes(), client, pluginregistry.PluginRegistry)
+	if err != nil {
+		t.Fatalf("Unable to decode the migrated policy: %v", err)
+	}
+	for _, policy := range []*api.DeschedulerPolicy{original, result} {
+		sort.Slice(policy.Profiles, func(i, j int) bool { return policy.Profiles[i].Name < policy.Profiles[j].Name })
+	}
+	if diff := cmp.Diff(original, result); diff != "" {
+		t.Errorf("Unexpected difference of the loaded policies (-original +migrated):\n%s", diff)
+	}
+}
+
+func TestMigratePolicyUnsupportedVersion(t *testing.T) {
+	policy := []byte(`apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+`)
+	if _, err := migratePolicy("policy.yaml", policy); err == nil {
+		t.Errorf("Expected an error migrating a v1alpha2 policy")
+	}
+}
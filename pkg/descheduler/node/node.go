@@ -266,6 +266,15 @@ func nodeAvailableResources(nodeIndexer podutil.GetPodsAssignedToNodeFunc, node
 
 // NodeUtilization returns the resources requested by the given pods. Only resources supplied in the resourceNames parameter are calculated.
 func NodeUtilization(pods []*v1.Pod, resourceNames []v1.ResourceName) map[v1.ResourceName]*resource.Quantity {
+	return NodeUtilizationOf(pods, resourceNames, func(pod *v1.Pod) v1.ResourceList {
+		req, _ := utils.PodRequestsAndLimits(pod)
+		return req
+	})
+}
+
+// NodeUtilizationOf returns the resources of the given pods, as returned by podResources for every pod,
+// e.g. their limits. Only resources supplied in the resourceNames parameter are calculated.
+func NodeUtilizationOf(pods []*v1.Pod, resourceNames []v1.ResourceName, podResources func(pod *v1.Pod) v1.ResourceList) map[v1.ResourceName]*resource.Quantity {
 	totalReqs := map[v1.ResourceName]*resource.Quantity{
 		v1.ResourceCPU:    resource.NewMilliQuantity(0, resource.DecimalSI),
 		v1.ResourceMemory: resource.NewQuantity(0, resource.BinarySI),
@@ -278,9 +287,9 @@ func NodeUtilization(pods []*v1.Pod, resourceNames []v1.ResourceName) map[v1.Res
 	}
 
 	for _, pod := range pods {
-		req, _ := utils.PodRequestsAndLimits(pod)
+		resources := podResources(pod)
 		for _, name := range resourceNames {
-			quantity, ok := req[name]
+			quantity, ok := resources[name]
 			if ok && name != v1.ResourcePods {
 				// As Quantity.Add says: Add adds the provided y quantity to the current value. If the current value is zero,
 				// the format of the quantity will be updated to the format of y.
@@ -292,6 +301,30 @@ func NodeUtilization(pods []*v1.Pod, resourceNames []v1.ResourceName) map[v1.Res
 	return totalReqs
 }
 
+// PodSizes returns the share of the node allocatable every pod takes, as returned by podResources for
+// every pod, summed over the resources other than the number of pods, so neither resource outweighs
+// the other by its unit.
+func PodSizes(pods []*v1.Pod, node *v1.Node, resourceNames []v1.ResourceName, podResources func(pod *v1.Pod) v1.ResourceList) map[*v1.Pod]float64 {
+	nodeCapacity := node.Status.Capacity
+	if len(node.Status.Allocatable) > 0 {
+		nodeCapacity = node.Status.Allocatable
+	}
+
+	sizes := make(map[*v1.Pod]float64, len(pods))
+	for _, pod := range pods {
+		resources := podResources(pod)
+		for _, name := range resourceNames {
+			capacity, ok := nodeCapacity[name]
+			if name == v1.ResourcePods || !ok || capacity.IsZero() {
+				continue
+			}
+			quantity := resources[name]
+			sizes[pod] += float64(quantity.MilliValue()) / float64(capacity.MilliValue())
+		}
+	}
+	return sizes
+}
+
 // IsBasicResource checks if resource is basic native.
 func IsBasicResource(name v1.ResourceName) bool {
 	switch name {
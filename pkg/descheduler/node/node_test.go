@@ -19,6 +19,7 @@ package node
 import (
 	"context"
 	"errors"
+	"math"
 	"sync"
 	"testing"
 
@@ -1131,3 +1132,21 @@ func createResourceList(cpu, memory, ephemeralStorage int64) v1.ResourceList {
 	resourceList[v1.ResourceEphemeralStorage] = *resource.NewQuantity(ephemeralStorage, resource.DecimalSI)
 	return resourceList
 }
+
+func TestPodSizes(t *testing.T) {
+	node := test.BuildTestNode("n1", 2000, 4000, 10, nil)
+	small := test.BuildTestPod("small", 200, 400, "n1", nil)
+	large := test.BuildTestPod("large", 1000, 1000, "n1", nil)
+	cpuOnly := test.BuildTestPod("cpu-only", 500, 0, "n1", nil)
+
+	sizes := PodSizes([]*v1.Pod{small, large, cpuOnly}, node, []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods}, func(pod *v1.Pod) v1.ResourceList {
+		return pod.Spec.Containers[0].Resources.Requests
+	})
+
+	expected := map[*v1.Pod]float64{small: 0.2, large: 0.75, cpuOnly: 0.25}
+	for pod, size := range expected {
+		if math.Abs(sizes[pod]-size) > 1e-9 {
+			t.Errorf("Expected pod %v to have size %v, got %v", pod.Name, size, sizes[pod])
+		}
+	}
+}
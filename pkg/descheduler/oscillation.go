@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"sort"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/nodeutilization"
+)
+
+const (
+	// NodeClassLow is the class of a node a utilization plugin considers underutilized
+	NodeClassLow = "low"
+	// NodeClassHigh is the class of a node a utilization plugin considers overutilized
+	NodeClassHigh = "high"
+	// NodeClassNone is the class of a node a utilization plugin considers appropriately utilized
+	NodeClassNone = "-"
+)
+
+// OscillationReport lists the signs of a policy moving the same workloads back and
+// forth across consecutive descheduling cycles
+type OscillationReport struct {
+	// Cycles is the number of cycles run
+	Cycles int `json:"cycles"`
+	// RepeatedPodEvictions are the pods evicted in more than one cycle. A pod created in place
+	// of an evicted pod by the rescheduling simulation is identified as the evicted pod.
+	RepeatedPodEvictions []RepeatedEviction `json:"repeatedPodEvictions,omitempty"`
+	// RepeatedOwnerEvictions are the owners whose pods were evicted in more than one cycle
+	RepeatedOwnerEvictions []RepeatedEviction `json:"repeatedOwnerEvictions,omitempty"`
+	// FlappingNodes are the nodes classified as underutilized in some cycles and overutilized in others
+	FlappingNodes []FlappingNode `json:"flappingNodes,omitempty"`
+}
+
+// RepeatedEviction is a pod or an owner evicted in more than one cycle
+type RepeatedEviction struct {
+	// Name is namespace/name of a pod, or namespace/kind/name of an owner
+	Name string `json:"name"`
+	// Cycles are the cycles the evictions happened in, starting at 1
+	Cycles []int `json:"cycles"`
+}
+
+// FlappingNode is a node switching between the low and high classes of a utilization plugin
+type FlappingNode struct {
+	Node    string `json:"node"`
+	Profile string `json:"profile"`
+	Plugin  string `json:"plugin"`
+	// Classes are the classes of the node at the start of every cycle
+	Classes []string `json:"classes"`
+}
+
+// Oscillating returns true when any repeated eviction or flapping node was found
+func (r *OscillationReport) Oscillating() bool {
+	return len(r.RepeatedPodEvictions) > 0 || len(r.RepeatedOwnerEvictions) > 0 || len(r.FlappingNodes) > 0
+}
+
+type nodeClassKey struct {
+	profile, plugin, node string
+}
+
+// oscillationDetector collects the evictions and the node classes of consecutive cycles
+type oscillationDetector struct {
+	mu             sync.Mutex
+	cycle          int
+	podEvictions   map[string][]int
+	ownerEvictions map[string][]int
+	nodeClasses    map[nodeClassKey][]string
+}
+
+func newOscillationDetector() *oscillationDetector {
+	return &oscillationDetector{
+		podEvictions:   map[string][]int{},
+		ownerEvictions: map[string][]int{},
+		nodeClasses:    map[nodeClassKey][]string{},
+	}
+}
+
+func (o *oscillationDetector) startCycle() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cycle++
+	return o.cycle
+}
+
+func (o *oscillationDetector) currentCycle() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.cycle
+}
+
+// recordEviction records the eviction of a pod under the given identity
+func (o *oscillationDetector) recordEviction(identity string, pod *v1.Pod) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.podEvictions[identity] = appendCycle(o.podEvictions[identity], o.cycle)
+	for _, ownerRef := range podutil.OwnerRef(pod) {
+		owner := pod.Namespace + "/" + ownerRef.Kind + "/" + ownerRef.Name
+		o.ownerEvictions[owner] = appendCycle(o.ownerEvictions[owner], o.cycle)
+	}
+}
+
+// appendCycle adds the cycle unless already present, so evictions of several pods
+// of an owner in a single cycle count once
+func appendCycle(cycles []int, cycle int) []int {
+	if len(cycles) > 0 && cycles[len(cycles)-1] == cycle {
+		return cycles
+	}
+	return append(cycles, cycle)
+}
+
+// recordNodeClasses records the classes the utilization plugins of the policy assign to the nodes
+func (o *oscillationDetector) recordNodeClasses(deschedulerPolicy *api.DeschedulerPolicy, nodes []*v1.Node, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc) {
+	for _, profile := range deschedulerPolicy.Profiles {
+		for _, pluginName := range profile.Plugins.Balance.Enabled {
+			if pluginName != nodeutilization.LowNodeUtilizationPluginName && pluginName != nodeutilization.HighNodeUtilizationPluginName {
+				continue
+			}
+			pluginConfig, _ := GetPluginConfig(pluginName, profile.PluginConfigs)
+			if pluginConfig == nil {
+				continue
+			}
+			lowNodes, highNodes, err := nodeutilization.ClassifyNodes(pluginConfig.Args, nodes, getPodsAssignedToNode)
+			if err != nil {
+				klog.ErrorS(err, "unable to classify nodes", "profile", profile.Name, "plugin", pluginName)
+				continue
+			}
+
+			classes := map[string]string{}
+			for _, node := range lowNodes {
+				classes[node] = NodeClassLow
+			}
+			for _, node := range highNodes {
+				classes[node] = NodeClassHigh
+			}
+
+			o.mu.Lock()
+			for _, node := range nodes {
+				class, ok := classes[node.Name]
+				if !ok {
+					class = NodeClassNone
+				}
+				key := nodeClassKey{profile: profile.Name, plugin: pluginName, node: node.Name}
+				o.nodeClasses[key] = append(o.nodeClasses[key], class)
+			}
+			o.mu.Unlock()
+		}
+	}
+}
+
+func (o *oscillationDetector) report() *OscillationReport {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	report := &OscillationReport{Cycles: o.cycle}
+	report.RepeatedPodEvictions = repeatedEvictions(o.podEvictions)
+	report.RepeatedOwnerEvictions = repeatedEvictions(o.ownerEvictions)
+
+	for key, classes := range o.nodeClasses {
+		var low, high bool
+		for _, class := range classes {
+			low = low || class == NodeClassLow
+			high = high || class == NodeClassHigh
+		}
+		if low && high {
+			report.FlappingNodes = append(report.FlappingNodes, FlappingNode{
+				Node:    key.node,
+				Profile: key.profile,
+				Plugin:  key.plugin,
+				Classes: classes,
+			})
+		}
+	}
+	sort.Slice(report.FlappingNodes, func(i, j int) bool {
+		a, b := report.FlappingNodes[i], report.FlappingNodes[j]
+		if a.Node != b.Node {
+			return a.Node < b.Node
+		}
+		if a.Profile != b.Profile {
+			return a.Profile < b.Profile
+		}
+		return a.Plugin < b.Plugin
+	})
+
+	return report
+}
+
+func repeatedEvictions(evictions map[string][]int) []RepeatedEviction {
+	var repeated []RepeatedEviction
+	for name, cycles := range evictions {
+		if len(cycles) > 1 {
+			repeated = append(repeated, RepeatedEviction{Name: name, Cycles: cycles})
+		}
+	}
+	sort.Slice(repeated, func(i, j int) bool { return repeated[i].Name < repeated[j].Name })
+	return repeated
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+// the nodes swap classes once a single pod moves, as the scheduler places the pod
+// on another node than the one the pod has been evicted for
+const oscillatingPolicy = `
+apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+profiles:
+  - name: balance
+    pluginConfig:
+    - name: "DefaultEvictor"
+    - name: "LowNodeUtilization"
+      args:
+        thresholds:
+          cpu: 40
+        targetThresholds:
+          cpu: 50
+    plugins:
+      balance:
+        enabled:
+          - "LowNodeUtilization"
+`
+
+func TestSimulateOscillation(t *testing.T) {
+	SetupPlugins()
+
+	node1 := test.BuildTestNode("n1", 1000, 3000, 10, nil)
+	node2 := test.BuildTestNode("n2", 1000, 3000, 10, nil)
+	// n3 has room for a pod below its target thresholds, unlike n1 and n2, but the
+	// scheduler prefers the other underutilized node for its lower memory allocation
+	node3 := test.BuildTestNode("n3", 1000, 3000, 10, nil)
+	var objects []runtime.Object
+	objects = append(objects, node1, node2, node3)
+	for _, placement := range []struct {
+		pod, node        string
+		milliCPU, memory int64
+	}{{"p1", "n1", 300, 0}, {"p2", "n1", 300, 0}, {"p3", "n2", 300, 0}, {"p4", "n3", 100, 1500}} {
+		pod := test.BuildTestPod(placement.pod, placement.milliCPU, placement.memory, placement.node, nil)
+		pod.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+		objects = append(objects, pod)
+	}
+
+	policyFile := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(policyFile, []byte(oscillatingPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Simulate(context.Background(), policyFile, objects, 3)
+	if err != nil {
+		t.Fatalf("Unable to simulate: %v", err)
+	}
+
+	got := [][]interface{}{}
+	for _, eviction := range result.Evictions {
+		got = append(got, []interface{}{eviction.Cycle, eviction.Pod.Spec.NodeName, eviction.Destination})
+	}
+	expected := [][]interface{}{{1, "n1", "n2"}, {2, "n2", "n1"}, {3, "n1", "n2"}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("Unexpected evictions (-want +got):\n%s", diff)
+	}
+
+	if result.Oscillations == nil || !result.Oscillations.Oscillating() {
+		t.Fatalf("Expected an oscillation to be detected, got %+v", result.Oscillations)
+	}
+	expectedOwners := []RepeatedEviction{{Name: "default/ReplicaSet/replicaset-1", Cycles: []int{1, 2, 3}}}
+	if diff := cmp.Diff(expectedOwners, result.Oscillations.RepeatedOwnerEvictions); diff != "" {
+		t.Errorf("Unexpected repeated owner evictions (-want +got):\n%s", diff)
+	}
+	expectedNodes := []FlappingNode{
+		{Node: "n1", Profile: "balance", Plugin: "LowNodeUtilization", Classes: []string{NodeClassHigh, NodeClassLow, NodeClassHigh}},
+		{Node: "n2", Profile: "balance", Plugin: "LowNodeUtilization", Classes: []string{NodeClassLow, NodeClassHigh, NodeClassLow}},
+	}
+	if diff := cmp.Diff(expectedNodes, result.Oscillations.FlappingNodes); diff != "" {
+		t.Errorf("Unexpected flapping nodes (-want +got):\n%s", diff)
+	}
+}
+
+func TestOscillationDetectorRepeatedPodEvictions(t *testing.T) {
+	detector := newOscillationDetector()
+	pod := test.BuildTestPod("p1", 100, 0, "n1", nil)
+	orphan := test.BuildTestPod("p2", 100, 0, "n1", nil)
+	pod.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+
+	detector.startCycle()
+	detector.recordEviction("default/p1", pod)
+	detector.recordEviction("default/p2", orphan)
+	detector.startCycle()
+	detector.startCycle()
+	// the replacement of p1 is identified as p1
+	detector.recordEviction("default/p1", pod)
+
+	report := detector.report()
+	if report.Cycles != 3 {
+		t.Errorf("Expected 3 cycles, got %v", report.Cycles)
+	}
+	if diff := cmp.Diff([]RepeatedEviction{{Name: "default/p1", Cycles: []int{1, 3}}}, report.RepeatedPodEvictions); diff != "" {
+		t.Errorf("Unexpected repeated pod evictions (-want +got):\n%s", diff)
+	}
+	if len(report.FlappingNodes) != 0 {
+		t.Errorf("Expected no flapping nodes, got %v", report.FlappingNodes)
+	}
+}
+
+func TestDryRunCycles(t *testing.T) {
+	initPluginRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	node1 := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+		node.Spec.Taints = []v1.Taint{{Key: "key", Value: "value", Effect: v1.TaintEffectNoSchedule}}
+	})
+	node2 := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+	p1 := test.BuildTestPod("p1", 100, 0, node1.Name, nil)
+	p1.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+
+	rs, descheduler, _ := initDescheduler(t, ctx, removePodsViolatingNodeTaintsPolicy(), node1, node2, p1)
+	rs.DryRun = true
+	rs.DryRunCycles = 3
+	rs.DryRunReport = filepath.Join(t.TempDir(), "report.md")
+	rs.DryRunReportFormat = DryRunReportFormatMarkdown
+
+	if err := descheduler.runDeschedulerLoop(ctx, []*v1.Node{node1, node2}); err != nil {
+		t.Fatalf("Unable to run a descheduling loop: %v", err)
+	}
+
+	data, err := os.ReadFile(rs.DryRunReport)
+	if err != nil {
+		t.Fatalf("Unable to read the dry run report: %v", err)
+	}
+	// the replacement lands on the untainted node and stays there
+	for _, expected := range []string{
+		"| 1 | default | p1 | n1 | n2 | ReplicaSet/replicaset-1 | Profile | RemovePodsViolatingNodeTaints |",
+		"1 pod(s) would be evicted.",
+		"## Oscillations over 3 cycles",
+		"No oscillation detected.",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected %q in the report:\n%s", expected, data)
+		}
+	}
+}
@@ -51,6 +51,28 @@ func WrapFilterFuncs(filters ...FilterFunc) FilterFunc {
 	}
 }
 
+// LessFunc reports whether the first pod should be ordered before the second one.
+type LessFunc func(*v1.Pod, *v1.Pod) bool
+
+// WrapLessFuncs wraps a set of LessFunc in one. The functions are consulted
+// in the given order, a later one is only used to break ties of the earlier ones.
+func WrapLessFuncs(lessFuncs ...LessFunc) LessFunc {
+	return func(a, b *v1.Pod) bool {
+		for _, less := range lessFuncs {
+			if less == nil {
+				continue
+			}
+			if less(a, b) {
+				return true
+			}
+			if less(b, a) {
+				return false
+			}
+		}
+		return false
+	}
+}
+
 type Options struct {
 	filter             FilterFunc
 	includedNamespaces sets.Set[string]
@@ -263,6 +285,17 @@ func SortPodsBasedOnPriorityLowToHigh(pods []*v1.Pod) {
 	})
 }
 
+// LessByPriority orders pods by priority, lowest first. Pods without priority come first.
+func LessByPriority(a, b *v1.Pod) bool {
+	if a.Spec.Priority == nil {
+		return b.Spec.Priority != nil
+	}
+	if b.Spec.Priority == nil {
+		return false
+	}
+	return *a.Spec.Priority < *b.Spec.Priority
+}
+
 // SortPodsBasedOnAge sorts Pods from oldest to most recent in place
 func SortPodsBasedOnAge(pods []*v1.Pod) {
 	sort.Slice(pods, func(i, j int) bool {
@@ -270,6 +303,17 @@ func SortPodsBasedOnAge(pods []*v1.Pod) {
 	})
 }
 
+// SortPods sorts pods in place using the given LessFunc while keeping
+// the original order of pods the LessFunc considers equal.
+func SortPods(pods []*v1.Pod, less LessFunc) {
+	if less == nil {
+		return
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		return less(pods[i], pods[j])
+	})
+}
+
 func GroupByNodeName(pods []*v1.Pod) map[string][]*v1.Pod {
 	m := make(map[string][]*v1.Pod)
 	for i := 0; i < len(pods); i++ {
@@ -177,6 +177,55 @@ func TestSortPodsBasedOnAge(t *testing.T) {
 	}
 }
 
+func TestSortPodsWithWrappedLessFuncs(t *testing.T) {
+	n1 := test.BuildTestNode("n1", 4000, 3000, 9, nil)
+
+	p1 := test.BuildTestPod("p1", 400, 0, n1.Name, func(pod *v1.Pod) {
+		test.SetPodPriority(pod, highPriority)
+	})
+	p2 := test.BuildTestPod("p2", 400, 0, n1.Name, func(pod *v1.Pod) {
+		test.SetPodPriority(pod, lowPriority)
+		pod.Labels = map[string]string{"tier": "b"}
+	})
+	p3 := test.BuildTestPod("p3", 400, 0, n1.Name, func(pod *v1.Pod) {
+		test.SetPodPriority(pod, lowPriority)
+		pod.Labels = map[string]string{"tier": "a"}
+	})
+	p4 := test.BuildTestPod("p4", 400, 0, n1.Name, func(pod *v1.Pod) {
+		test.SetPodPriority(pod, lowPriority)
+		pod.Labels = map[string]string{"tier": "b"}
+	})
+
+	byPriority := func(a, b *v1.Pod) bool {
+		return *a.Spec.Priority < *b.Spec.Priority
+	}
+	byTier := func(a, b *v1.Pod) bool {
+		return a.Labels["tier"] < b.Labels["tier"]
+	}
+
+	podList := []*v1.Pod{p1, p2, p3, p4}
+	SortPods(podList, WrapLessFuncs(byPriority, nil, byTier))
+
+	expected := []*v1.Pod{p3, p2, p4, p1}
+	if !reflect.DeepEqual(podList, expected) {
+		t.Errorf("Expected pods to be sorted as %v, got %v", podNames(expected), podNames(podList))
+	}
+
+	// a nil LessFunc leaves the order untouched
+	SortPods(podList, nil)
+	if !reflect.DeepEqual(podList, expected) {
+		t.Errorf("Expected pods to keep their order %v, got %v", podNames(expected), podNames(podList))
+	}
+}
+
+func podNames(pods []*v1.Pod) []string {
+	names := make([]string, 0, len(pods))
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
+
 func TestGroupByNodeName(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -51,12 +51,9 @@ func LoadPolicyConfig(policyConfigFile string, client clientset.Interface, regis
 }
 
 func decode(policyConfigFile string, policy []byte, client clientset.Interface, registry pluginregistry.Registry) (*api.DeschedulerPolicy, error) {
-	internalPolicy := &api.DeschedulerPolicy{}
-	var err error
-
-	decoder := scheme.Codecs.UniversalDecoder(v1alpha1.SchemeGroupVersion, v1alpha2.SchemeGroupVersion, api.SchemeGroupVersion)
-	if err := runtime.DecodeInto(decoder, policy, internalPolicy); err != nil {
-		return nil, fmt.Errorf("failed decoding descheduler's policy config %q: %v", policyConfigFile, err)
+	internalPolicy, err := decodePolicy(policyConfigFile, policy)
+	if err != nil {
+		return nil, err
 	}
 
 	err = validateDeschedulerConfiguration(*internalPolicy, registry)
@@ -69,6 +66,16 @@ func decode(policyConfigFile string, policy []byte, client clientset.Interface,
 	return internalPolicy, nil
 }
 
+// decodePolicy converts the policy into the internal version without validating it
+func decodePolicy(policyConfigFile string, policy []byte) (*api.DeschedulerPolicy, error) {
+	internalPolicy := &api.DeschedulerPolicy{}
+	decoder := scheme.Codecs.UniversalDecoder(v1alpha1.SchemeGroupVersion, v1alpha2.SchemeGroupVersion, api.SchemeGroupVersion)
+	if err := runtime.DecodeInto(decoder, policy, internalPolicy); err != nil {
+		return nil, fmt.Errorf("failed decoding descheduler's policy config %q: %v", policyConfigFile, err)
+	}
+	return internalPolicy, nil
+}
+
 func setDefaults(in api.DeschedulerPolicy, registry pluginregistry.Registry, client clientset.Interface) *api.DeschedulerPolicy {
 	for idx, profile := range in.Profiles {
 		// If we need to set defaults coming from loadtime in each profile we do it here
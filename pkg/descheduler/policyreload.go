@@ -0,0 +1,237 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	v1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/metrics"
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+)
+
+const (
+	policyReloadSuccess = "success"
+	policyReloadError   = "error"
+
+	// podNameEnv and podNamespaceEnv are set from the downward API in the descheduler manifests
+	podNameEnv                  = "POD_NAME"
+	podNamespaceEnv             = "POD_NAMESPACE"
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// policyReloader decodes the policy file anew when its content changes. The directory of the
+// file is watched rather than the file so the updates of a mounted ConfigMap, which swap
+// a symlink, are noticed as well.
+type policyReloader struct {
+	file     string
+	client   clientset.Interface
+	registry pluginregistry.Registry
+	// checksum is the checksum of the content decoded last, valid or not
+	checksum [sha256.Size]byte
+	// changed is set by the watcher, the file is read every cycle when it cannot be watched
+	changed  atomic.Bool
+	watching bool
+	// pod is the pod of the descheduler the reload events are recorded against, nil outside of a pod
+	pod *v1.ObjectReference
+}
+
+func newPolicyReloader(file string, client clientset.Interface, registry pluginregistry.Registry) (*policyReloader, error) {
+	policy, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy config file %q: %+v", file, err)
+	}
+	return &policyReloader{
+		file:     file,
+		client:   client,
+		registry: registry,
+		checksum: sha256.Sum256(policy),
+		pod:      podReference(),
+	}, nil
+}
+
+// podReference returns the pod the descheduler runs in, read from the POD_NAME and POD_NAMESPACE
+// variables, or else the host name and the namespace of the service account. Nil when not known.
+func podReference() *v1.ObjectReference {
+	name := os.Getenv(podNameEnv)
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	namespace := os.Getenv(podNamespaceEnv)
+	if namespace == "" {
+		if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+			namespace = strings.TrimSpace(string(data))
+		}
+	}
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return &v1.ObjectReference{APIVersion: "v1", Kind: "Pod", Namespace: namespace, Name: name}
+}
+
+// watch flags the policy as changed on every event in the directory of the file until the context is done
+func (r *policyReloader) watch(ctx context.Context) {
+	watcher, err := fsnotify.NewWatcher()
+	if err == nil {
+		err = watcher.Add(filepath.Dir(r.file))
+	}
+	if err != nil {
+		klog.ErrorS(err, "Unable to watch the policy file, reading it every descheduling cycle instead", "file", r.file)
+		if watcher != nil {
+			watcher.Close()
+		}
+		return
+	}
+	r.watching = true
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				klog.V(5).InfoS("Policy directory changed", "file", event.Name, "op", event.Op.String())
+				r.changed.Store(true)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				klog.ErrorS(err, "Error watching the policy file", "file", r.file)
+			}
+		}
+	}()
+}
+
+// reload returns the policy decoded from the file, nil when its content did not change since the last reload.
+// The content of a policy failing to decode or validate is not decoded again until it changes.
+func (r *policyReloader) reload() (*api.DeschedulerPolicy, error) {
+	if r.watching && !r.changed.Swap(false) {
+		return nil, nil
+	}
+
+	policy, err := os.ReadFile(r.file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy config file %q: %+v", r.file, err)
+	}
+	checksum := sha256.Sum256(policy)
+	if checksum == r.checksum {
+		return nil, nil
+	}
+	r.checksum = checksum
+
+	return decode(r.file, policy, r.client, r.registry)
+}
+
+// reloadPolicy swaps the policy for the policy decoded from the file when it changed. The policy is only
+// read between descheduling cycles, so a cycle runs entirely with one policy. The current policy is
+// kept when the new one fails to validate.
+func (d *descheduler) reloadPolicy() {
+	if d.policyReloader == nil {
+		return
+	}
+
+	policy, err := d.policyReloader.reload()
+	if err != nil {
+		klog.ErrorS(err, "Unable to reload the policy, keeping the current policy", "file", d.policyReloader.file)
+		metrics.PolicyReloads.With(map[string]string{"result": policyReloadError}).Inc()
+		d.recordReloadEvent(v1.EventTypeWarning, "PolicyReloadFailed", "keeping the current policy: %v", err)
+		return
+	}
+	if policy == nil {
+		return
+	}
+
+	d.carryOverPluginState(policy)
+	d.deschedulerPolicy = policy
+	d.podEvictor.SetLimits(policy.MaxNoOfPodsToEvictPerNode, policy.MaxNoOfPodsToEvictPerNamespace, policy.MaxNoOfPodsToEvictTotal)
+
+	klog.InfoS("Reloaded the policy", "file", d.policyReloader.file, "profiles", len(policy.Profiles))
+	metrics.PolicyReloads.With(map[string]string{"result": policyReloadSuccess}).Inc()
+	d.recordReloadEvent(v1.EventTypeNormal, "PolicyReloaded", "policy reloaded from %v", d.policyReloader.file)
+}
+
+// recordReloadEvent records an event about a reload against the pod of the descheduler, the policy is no API object
+func (d *descheduler) recordReloadEvent(eventtype, reason, note string, args ...interface{}) {
+	if d.policyReloader.pod == nil {
+		klog.V(3).InfoS("Not recording the reload event, the pod of the descheduler is not known", "reason", reason)
+		return
+	}
+	d.eventRecorder.Eventf(d.policyReloader.pod, nil, eventtype, reason, "Reload", note, args...)
+}
+
+// carryOverPluginState keeps the state the plugins keep across descheduling cycles for the plugins
+// configured the same way in the current and the reloaded policy and forgets the state of the other plugins
+func (d *descheduler) carryOverPluginState(reloaded *api.DeschedulerPolicy) {
+	for _, profile := range d.deschedulerPolicy.Profiles {
+		pluginState, ok := d.pluginStates[profile.Name]
+		if !ok {
+			continue
+		}
+		for _, pluginConfig := range profile.PluginConfigs {
+			args := pluginArgs(reloaded, profile.Name, pluginConfig.Name)
+			if args == nil || !apiequality.Semantic.DeepEqual(pluginConfig.Args, args) {
+				pluginState.Delete(pluginConfig.Name)
+			}
+		}
+	}
+	for profileName := range d.pluginStates {
+		if !hasProfile(reloaded, profileName) {
+			delete(d.pluginStates, profileName)
+		}
+	}
+}
+
+func hasProfile(policy *api.DeschedulerPolicy, profileName string) bool {
+	for _, profile := range policy.Profiles {
+		if profile.Name == profileName {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginArgs returns the args of the plugin in the profile, nil when the policy has no such plugin
+func pluginArgs(policy *api.DeschedulerPolicy, profileName, pluginName string) runtime.Object {
+	for _, profile := range policy.Profiles {
+		if profile.Name != profileName {
+			continue
+		}
+		for _, pluginConfig := range profile.PluginConfigs {
+			if pluginConfig.Name == pluginName {
+				return pluginConfig.Args
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/amit3512/descheduler_policy_master/metrics"
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/removeduplicates"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+)
+
+const reloadablePolicy = `apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+maxNoOfPodsToEvictTotal: %LIMIT%
+profiles:
+  - name: ProfileName
+    pluginConfig:
+    - name: "DefaultEvictor"
+    - name: "RemoveDuplicates"
+    plugins:
+      balance:
+        enabled:
+          - "RemoveDuplicates"
+`
+
+// invalidPolicy both includes and excludes namespaces
+const invalidPolicy = `apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+profiles:
+  - name: ProfileName
+    pluginConfig:
+    - name: "DefaultEvictor"
+    - name: "RemoveDuplicates"
+      args:
+        namespaces:
+          include:
+          - "dev"
+          exclude:
+          - "kube-system"
+    plugins:
+      balance:
+        enabled:
+          - "RemoveDuplicates"
+`
+
+func policyWithLimit(limit string) string {
+	return strings.ReplaceAll(reloadablePolicy, "%LIMIT%", limit)
+}
+
+func writePolicy(t *testing.T, file, policy string) {
+	if err := os.WriteFile(file, []byte(policy), 0o600); err != nil {
+		t.Fatalf("Unable to write the policy file: %v", err)
+	}
+}
+
+func TestPolicyReloader(t *testing.T) {
+	initPluginRegistry()
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy(t, file, policyWithLimit("1"))
+
+	reloader, err := newPolicyReloader(file, nil, pluginregistry.PluginRegistry)
+	if err != nil {
+		t.Fatalf("Unable to create the policy reloader: %v", err)
+	}
+
+	steps := []struct {
+		description   string
+		policy        string
+		expectedLimit uint
+		expectReload  bool
+		expectError   bool
+	}{
+		{
+			description: "unchanged policy is not reloaded",
+			policy:      policyWithLimit("1"),
+		},
+		{
+			description:   "changed policy is reloaded",
+			policy:        policyWithLimit("2"),
+			expectedLimit: 2,
+			expectReload:  true,
+		},
+		{
+			description: "invalid policy fails to reload",
+			policy:      invalidPolicy,
+			expectError: true,
+		},
+		{
+			description: "invalid policy is not reloaded again",
+			policy:      invalidPolicy,
+		},
+		{
+			description:   "fixed policy is reloaded",
+			policy:        policyWithLimit("3"),
+			expectedLimit: 3,
+			expectReload:  true,
+		},
+	}
+
+	for _, step := range steps {
+		t.Run(step.description, func(t *testing.T) {
+			writePolicy(t, file, step.policy)
+			policy, err := reloader.reload()
+			if hasError := err != nil; hasError != step.expectError {
+				t.Fatalf("Expected error %v, got %v", step.expectError, err)
+			}
+			if reloaded := policy != nil; reloaded != step.expectReload {
+				t.Fatalf("Expected reload %v, got %v", step.expectReload, reloaded)
+			}
+			if policy != nil && *policy.MaxNoOfPodsToEvictTotal != step.expectedLimit {
+				t.Errorf("Expected maxNoOfPodsToEvictTotal %v, got %v", step.expectedLimit, *policy.MaxNoOfPodsToEvictTotal)
+			}
+		})
+	}
+}
+
+func TestPolicyReloaderWatch(t *testing.T) {
+	initPluginRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy(t, file, policyWithLimit("1"))
+
+	reloader, err := newPolicyReloader(file, nil, pluginregistry.PluginRegistry)
+	if err != nil {
+		t.Fatalf("Unable to create the policy reloader: %v", err)
+	}
+	reloader.watch(ctx)
+	if !reloader.watching {
+		t.Skip("The policy directory cannot be watched")
+	}
+
+	writePolicy(t, file, policyWithLimit("2"))
+	if err := wait.PollUntilContextTimeout(ctx, 10*time.Millisecond, 5*time.Second, true, func(context.Context) (bool, error) {
+		return reloader.changed.Load(), nil
+	}); err != nil {
+		t.Fatalf("Expected the change of the policy file to be noticed: %v", err)
+	}
+
+	policy, err := reloader.reload()
+	if err != nil || policy == nil {
+		t.Fatalf("Expected the policy to be reloaded, got %v, %v", policy, err)
+	}
+	if policy, _ := reloader.reload(); policy != nil {
+		t.Errorf("Expected the policy not to be reloaded before the file changes again")
+	}
+}
+
+func TestReloadPolicy(t *testing.T) {
+	initPluginRegistry()
+	metrics.Register()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, descheduler, _ := initDescheduler(t, ctx, removeDuplicatesPolicy())
+	recorder := events.NewFakeRecorder(10)
+	descheduler.eventRecorder = recorder
+
+	t.Setenv(podNameEnv, "descheduler-6f7b9c")
+	t.Setenv(podNamespaceEnv, "kube-system")
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	writePolicy(t, file, policyWithLimit("1"))
+	reloader, err := newPolicyReloader(file, nil, pluginregistry.PluginRegistry)
+	if err != nil {
+		t.Fatalf("Unable to create the policy reloader: %v", err)
+	}
+	descheduler.policyReloader = reloader
+
+	counter := func(result string) float64 {
+		value, err := testutil.GetCounterMetricValue(metrics.PolicyReloads.With(map[string]string{"result": result}))
+		if err != nil {
+			t.Fatalf("Unable to read the policy reloads metric: %v", err)
+		}
+		return value
+	}
+	successes, failures := counter(policyReloadSuccess), counter(policyReloadError)
+
+	writePolicy(t, file, invalidPolicy)
+	current := descheduler.deschedulerPolicy
+	descheduler.reloadPolicy()
+	if descheduler.deschedulerPolicy != current {
+		t.Errorf("Expected the current policy to be kept when the reloaded policy is invalid")
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "PolicyReloadFailed") {
+		t.Errorf("Expected a PolicyReloadFailed event, got %q", event)
+	}
+	if got := counter(policyReloadError); got != failures+1 {
+		t.Errorf("Expected %v failed reloads, got %v", failures+1, got)
+	}
+
+	writePolicy(t, file, policyWithLimit("2"))
+	descheduler.reloadPolicy()
+	if descheduler.deschedulerPolicy == current || *descheduler.deschedulerPolicy.MaxNoOfPodsToEvictTotal != 2 {
+		t.Errorf("Expected the reloaded policy to be swapped in")
+	}
+	if event := <-recorder.Events; !strings.Contains(event, "PolicyReloaded") {
+		t.Errorf("Expected a PolicyReloaded event, got %q", event)
+	}
+	if got := counter(policyReloadSuccess); got != successes+1 {
+		t.Errorf("Expected %v successful reloads, got %v", successes+1, got)
+	}
+}
+
+func TestPodReference(t *testing.T) {
+	t.Setenv(podNameEnv, "descheduler-6f7b9c")
+	t.Setenv(podNamespaceEnv, "kube-system")
+	expected := &v1.ObjectReference{APIVersion: "v1", Kind: "Pod", Namespace: "kube-system", Name: "descheduler-6f7b9c"}
+	if got := podReference(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the reload events to be recorded against %v, got %v", expected, got)
+	}
+
+	// the host name of a pod is its name
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skipf("Unable to get the host name: %v", err)
+	}
+	t.Setenv(podNameEnv, "")
+	if got := podReference(); got == nil || got.Name != hostname {
+		t.Errorf("Expected the pod to be named after the host %q, got %v", hostname, got)
+	}
+}
+
+func TestCarryOverPluginState(t *testing.T) {
+	profile := func(name string, excludeOwnerKinds ...string) api.DeschedulerProfile {
+		return api.DeschedulerProfile{
+			Name: name,
+			PluginConfigs: []api.PluginConfig{
+				{Name: defaultevictor.PluginName, Args: &defaultevictor.DefaultEvictorArgs{}},
+				{Name: removeduplicates.PluginName, Args: &removeduplicates.RemoveDuplicatesArgs{ExcludeOwnerKinds: excludeOwnerKinds}},
+			},
+		}
+	}
+	descheduler := &descheduler{
+		deschedulerPolicy: &api.DeschedulerPolicy{Profiles: []api.DeschedulerProfile{profile("unchanged"), profile("changed"), profile("removed")}},
+		pluginStates:      map[string]*frameworktypes.PluginState{},
+	}
+	for _, profileName := range []string{"unchanged", "changed", "removed"} {
+		pluginState := descheduler.pluginState(profileName)
+		for _, pluginName := range []string{defaultevictor.PluginName, removeduplicates.PluginName} {
+			pluginState.LoadOrStore(pluginName, func() interface{} { return profileName })
+		}
+	}
+
+	descheduler.carryOverPluginState(&api.DeschedulerPolicy{Profiles: []api.DeschedulerProfile{profile("unchanged"), profile("changed", "Job")}})
+
+	kept := func(profileName, pluginName string) bool {
+		pluginState, ok := descheduler.pluginStates[profileName]
+		if !ok {
+			return false
+		}
+		return pluginState.LoadOrStore(pluginName, func() interface{} { return nil }) != nil
+	}
+	for _, pluginName := range []string{defaultevictor.PluginName, removeduplicates.PluginName} {
+		if !kept("unchanged", pluginName) {
+			t.Errorf("Expected the state of %v in the unchanged profile to be kept", pluginName)
+		}
+	}
+	if !kept("changed", defaultevictor.PluginName) {
+		t.Errorf("Expected the state of the unchanged plugin in the changed profile to be kept")
+	}
+	if kept("changed", removeduplicates.PluginName) {
+		t.Errorf("Expected the state of the changed plugin to be forgotten")
+	}
+	if _, ok := descheduler.pluginStates["removed"]; ok {
+		t.Errorf("Expected the state of the removed profile to be forgotten")
+	}
+}
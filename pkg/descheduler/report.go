@@ -0,0 +1,332 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+)
+
+const (
+	// DryRunReportFormatJSON renders the dry run report as JSON
+	DryRunReportFormatJSON = "json"
+	// DryRunReportFormatMarkdown renders the dry run report as Markdown tables
+	DryRunReportFormatMarkdown = "markdown"
+)
+
+// reportResourceNames are the resources the node utilization is reported for
+var reportResourceNames = []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods}
+
+// DryRunReport describes the evictions of a single descheduling cycle run in the dry run mode
+type DryRunReport struct {
+	// Time is the time the cycle started
+	Time metav1.Time `json:"time"`
+	// Evictions lists the pods which would be evicted in the eviction order
+	Evictions []DryRunEviction `json:"evictions"`
+	// Nodes lists the utilization of the nodes before the first and after the last cycle
+	Nodes []NodeUtilizationReport `json:"nodes"`
+	// Oscillations is set when multiple consecutive cycles were simulated
+	Oscillations *OscillationReport `json:"oscillations,omitempty"`
+}
+
+// DryRunEviction is a pod which would be evicted
+type DryRunEviction struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Node      string `json:"node"`
+	// Destination is the node a replacement of the pod is expected to land on
+	Destination string `json:"destination,omitempty"`
+	// Owner is the kind and name of the pod's controller, e.g. ReplicaSet/web
+	Owner   string `json:"owner,omitempty"`
+	Profile string `json:"profile"`
+	Plugin  string `json:"plugin"`
+	Reason  string `json:"reason,omitempty"`
+	// Cycle is the simulated cycle of the eviction, starting at 1, when multiple cycles were simulated
+	Cycle int `json:"cycle,omitempty"`
+}
+
+// NodeUtilizationReport is the resources requested by the pods of a node before and after the evictions
+type NodeUtilizationReport struct {
+	Node        string          `json:"node"`
+	Allocatable v1.ResourceList `json:"allocatable"`
+	Before      v1.ResourceList `json:"before"`
+	After       v1.ResourceList `json:"after"`
+}
+
+// dryRunReporter collects the evictions of a cycle and computes the
+// utilization of the nodes before and after them
+type dryRunReporter struct {
+	mu         sync.Mutex
+	report     *DryRunReport
+	nodes      []*v1.Node
+	podsBefore map[string][]*v1.Pod
+	evicted    map[types.UID]bool
+	// replacements are the pods simulated to replace the evicted ones
+	replacements []*v1.Pod
+}
+
+func newDryRunReporter(nodes []*v1.Node, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc) *dryRunReporter {
+	r := &dryRunReporter{
+		report: &DryRunReport{
+			Time:      metav1.Now(),
+			Evictions: []DryRunEviction{},
+			Nodes:     []NodeUtilizationReport{},
+		},
+		nodes:      nodes,
+		podsBefore: map[string][]*v1.Pod{},
+		evicted:    map[types.UID]bool{},
+	}
+	for _, node := range nodes {
+		pods, err := podutil.ListPodsOnANode(node.Name, getPodsAssignedToNode, nil)
+		if err != nil {
+			klog.V(2).InfoS("Node utilization will not be reported, error accessing its pods", "node", klog.KObj(node), "err", err)
+			continue
+		}
+		r.podsBefore[node.Name] = pods
+	}
+	return r
+}
+
+// recordEviction adds the evicted pod to the report. The replacement is the pod
+// created in its place by the placement simulation, if any.
+func (r *dryRunReporter) recordEviction(pod *v1.Pod, opts evictions.EvictOptions, replacement *v1.Pod, cycle int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var owner string
+	if ownerRef := metav1.GetControllerOf(pod); ownerRef != nil {
+		owner = ownerRef.Kind + "/" + ownerRef.Name
+	} else if ownerRefs := podutil.OwnerRef(pod); len(ownerRefs) > 0 {
+		owner = ownerRefs[0].Kind + "/" + ownerRefs[0].Name
+	}
+
+	var destination string
+	if replacement != nil {
+		destination = replacement.Spec.NodeName
+		r.replacements = append(r.replacements, replacement)
+	}
+
+	r.evicted[pod.UID] = true
+	r.report.Evictions = append(r.report.Evictions, DryRunEviction{
+		Namespace:   pod.Namespace,
+		Pod:         pod.Name,
+		Node:        pod.Spec.NodeName,
+		Destination: destination,
+		Owner:       owner,
+		Profile:     opts.ProfileName,
+		Plugin:      opts.StrategyName,
+		Reason:      opts.Reason,
+		Cycle:       cycle,
+	})
+}
+
+// finish computes the utilization of the nodes once all the evictions are recorded
+func (r *dryRunReporter) finish() *DryRunReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.report.Nodes = []NodeUtilizationReport{}
+	for _, node := range r.nodes {
+		podsBefore, ok := r.podsBefore[node.Name]
+		if !ok {
+			continue
+		}
+		var podsAfter []*v1.Pod
+		for _, pod := range podsBefore {
+			if !r.evicted[pod.UID] {
+				podsAfter = append(podsAfter, pod)
+			}
+		}
+		for _, pod := range r.replacements {
+			if pod.Spec.NodeName == node.Name && !r.evicted[pod.UID] {
+				podsAfter = append(podsAfter, pod)
+			}
+		}
+		allocatable := node.Status.Allocatable
+		if len(allocatable) == 0 {
+			allocatable = node.Status.Capacity
+		}
+		r.report.Nodes = append(r.report.Nodes, NodeUtilizationReport{
+			Node:        node.Name,
+			Allocatable: allocatable.DeepCopy(),
+			Before:      toResourceList(nodeutil.NodeUtilization(podsBefore, reportResourceNames)),
+			After:       toResourceList(nodeutil.NodeUtilization(podsAfter, reportResourceNames)),
+		})
+	}
+	sort.Slice(r.report.Nodes, func(i, j int) bool { return r.report.Nodes[i].Node < r.report.Nodes[j].Node })
+	return r.report
+}
+
+func toResourceList(usage map[v1.ResourceName]*resource.Quantity) v1.ResourceList {
+	resourceList := v1.ResourceList{}
+	for name, quantity := range usage {
+		resourceList[name] = quantity.DeepCopy()
+	}
+	return resourceList
+}
+
+// writeDryRunReportFile replaces the content of the report file with the report of the last cycle
+func writeDryRunReportFile(reportFile, format string, report *DryRunReport) error {
+	f, err := os.Create(reportFile)
+	if err != nil {
+		return fmt.Errorf("failed to create dry run report file %q: %v", reportFile, err)
+	}
+	defer f.Close()
+	if err := WriteDryRunReport(f, format, report); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ValidateDryRunReportFormat checks the format is one of the supported ones
+func ValidateDryRunReportFormat(format string) error {
+	switch format {
+	case DryRunReportFormatJSON, DryRunReportFormatMarkdown:
+		return nil
+	default:
+		return fmt.Errorf("unsupported dry run report format %q, expected one of %q, %q", format, DryRunReportFormatJSON, DryRunReportFormatMarkdown)
+	}
+}
+
+// WriteDryRunReport renders the report in the given format
+func WriteDryRunReport(out io.Writer, format string, report *DryRunReport) error {
+	switch format {
+	case DryRunReportFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case DryRunReportFormatMarkdown:
+		_, err := io.WriteString(out, markdownDryRunReport(report))
+		return err
+	default:
+		return ValidateDryRunReportFormat(format)
+	}
+}
+
+func markdownDryRunReport(report *DryRunReport) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Descheduler dry run report\n\nCycle started at %s.\n\n", report.Time.UTC().Format("2006-01-02T15:04:05Z"))
+
+	b.WriteString("## Evictions\n\n")
+	if len(report.Evictions) == 0 {
+		b.WriteString("No pods would be evicted.\n\n")
+	} else {
+		if report.Oscillations != nil {
+			b.WriteString("| Cycle ")
+		}
+		b.WriteString("| Namespace | Pod | Node | Destination | Owner | Profile | Plugin | Reason |\n")
+		if report.Oscillations != nil {
+			b.WriteString("|---")
+		}
+		b.WriteString("|---|---|---|---|---|---|---|---|\n")
+		for _, eviction := range report.Evictions {
+			if report.Oscillations != nil {
+				fmt.Fprintf(&b, "| %d ", eviction.Cycle)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s | %s |\n",
+				eviction.Namespace, eviction.Pod, eviction.Node, eviction.Destination, eviction.Owner, eviction.Profile, eviction.Plugin, markdownEscape(eviction.Reason))
+		}
+		fmt.Fprintf(&b, "\n%d pod(s) would be evicted.\n\n", len(report.Evictions))
+	}
+
+	b.WriteString("## Node utilization\n\n")
+	b.WriteString("| Node | CPU before | CPU after | Memory before | Memory after | Pods before | Pods after |\n")
+	b.WriteString("|---|---|---|---|---|---|---|\n")
+	for _, node := range report.Nodes {
+		fmt.Fprintf(&b, "| %s", node.Node)
+		for _, name := range reportResourceNames {
+			fmt.Fprintf(&b, " | %s | %s", formatUtilization(node.Before, node.Allocatable, name), formatUtilization(node.After, node.Allocatable, name))
+		}
+		b.WriteString(" |\n")
+	}
+
+	if report.Oscillations != nil {
+		markdownOscillations(&b, report.Oscillations)
+	}
+
+	return b.String()
+}
+
+func markdownOscillations(b *strings.Builder, oscillations *OscillationReport) {
+	fmt.Fprintf(b, "\n## Oscillations over %d cycles\n\n", oscillations.Cycles)
+	if !oscillations.Oscillating() {
+		b.WriteString("No oscillation detected.\n")
+		return
+	}
+	if len(oscillations.RepeatedPodEvictions) > 0 || len(oscillations.RepeatedOwnerEvictions) > 0 {
+		b.WriteString("| Evicted repeatedly | Cycles |\n")
+		b.WriteString("|---|---|\n")
+		for _, eviction := range append(oscillations.RepeatedPodEvictions, oscillations.RepeatedOwnerEvictions...) {
+			fmt.Fprintf(b, "| %s | %s |\n", eviction.Name, joinCycles(eviction.Cycles))
+		}
+		b.WriteString("\n")
+	}
+	if len(oscillations.FlappingNodes) > 0 {
+		b.WriteString("| Flapping node | Profile | Plugin | Classes |\n")
+		b.WriteString("|---|---|---|---|\n")
+		for _, node := range oscillations.FlappingNodes {
+			fmt.Fprintf(b, "| %s | %s | %s | %s |\n", node.Node, node.Profile, node.Plugin, strings.Join(node.Classes, " -> "))
+		}
+	}
+}
+
+func joinCycles(cycles []int) string {
+	formatted := make([]string, 0, len(cycles))
+	for _, cycle := range cycles {
+		formatted = append(formatted, fmt.Sprint(cycle))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// formatUtilization renders the requested quantity of a resource together with its percentage of the allocatable
+func formatUtilization(requested, allocatable v1.ResourceList, name v1.ResourceName) string {
+	quantity, ok := requested[name]
+	if !ok {
+		return "-"
+	}
+	capacity, ok := allocatable[name]
+	if !ok || capacity.IsZero() {
+		return quantity.String()
+	}
+	var percentage float64
+	if name == v1.ResourceCPU {
+		percentage = float64(quantity.MilliValue()) * 100 / float64(capacity.MilliValue())
+	} else {
+		percentage = float64(quantity.Value()) * 100 / float64(capacity.Value())
+	}
+	return fmt.Sprintf("%s (%.1f%%)", quantity.String(), percentage)
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", " ")
+}
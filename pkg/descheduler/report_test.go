@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestDryRunReport(t *testing.T) {
+	initPluginRegistry()
+
+	ctx := context.Background()
+	node1 := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+		node.Spec.Taints = []v1.Taint{
+			{
+				Key:    "key",
+				Value:  "value",
+				Effect: v1.TaintEffectNoSchedule,
+			},
+		}
+	})
+	node2 := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+
+	p1 := test.BuildTestPod("p1", 500, 1000, node1.Name, nil)
+	p1.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+	p2 := test.BuildTestPod("p2", 300, 0, node2.Name, nil)
+	p2.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+
+	for _, format := range []string{DryRunReportFormatJSON, DryRunReportFormatMarkdown} {
+		t.Run(format, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			rs, descheduler, client := initDescheduler(t, ctx, removePodsViolatingNodeTaintsPolicy(), node1, node2, p1, p2)
+			rs.DryRun = true
+			rs.DryRunReport = filepath.Join(t.TempDir(), "report")
+			rs.DryRunReportFormat = format
+
+			if err := descheduler.runDeschedulerLoop(ctx, []*v1.Node{node1, node2}); err != nil {
+				t.Fatalf("Unable to run a descheduling loop: %v", err)
+			}
+			if pods, _ := client.Tracker().List(v1.SchemeGroupVersion.WithResource("pods"), v1.SchemeGroupVersion.WithKind("Pod"), ""); len(pods.(*v1.PodList).Items) != 2 {
+				t.Errorf("Expected no pod to be evicted in the dry run mode")
+			}
+
+			data, err := os.ReadFile(rs.DryRunReport)
+			if err != nil {
+				t.Fatalf("Unable to read the dry run report: %v", err)
+			}
+
+			if format == DryRunReportFormatMarkdown {
+				for _, expected := range []string{
+					"| default | p1 | n1 |  | ReplicaSet/replicaset-1 | Profile | RemovePodsViolatingNodeTaints |",
+					"| n1 | 500m (25.0%) | 0 (0.0%) | 1k (33.3%) | 0 (0.0%) | 1 (10.0%) | 0 (0.0%) |",
+					"| n2 | 300m (15.0%) | 300m (15.0%) |",
+				} {
+					if !strings.Contains(string(data), expected) {
+						t.Errorf("Expected %q in the report:\n%s", expected, data)
+					}
+				}
+				return
+			}
+
+			report := &DryRunReport{}
+			if err := json.Unmarshal(data, report); err != nil {
+				t.Fatalf("Unable to decode the dry run report: %v", err)
+			}
+			expectedEvictions := []DryRunEviction{
+				{
+					Namespace: "default",
+					Pod:       "p1",
+					Node:      "n1",
+					Owner:     "ReplicaSet/replicaset-1",
+					Profile:   "Profile",
+					Plugin:    "RemovePodsViolatingNodeTaints",
+				},
+			}
+			if diff := cmp.Diff(expectedEvictions, report.Evictions); diff != "" {
+				t.Errorf("Unexpected evictions (-want +got):\n%s", diff)
+			}
+
+			got := map[string][2]int64{}
+			for _, node := range report.Nodes {
+				before, after := node.Before[v1.ResourceCPU], node.After[v1.ResourceCPU]
+				got[node.Node] = [2]int64{before.MilliValue(), after.MilliValue()}
+			}
+			expectedCPU := map[string][2]int64{"n1": {500, 0}, "n2": {300, 300}}
+			if diff := cmp.Diff(expectedCPU, got); diff != "" {
+				t.Errorf("Unexpected cpu utilization (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteDryRunReportNoEvictions(t *testing.T) {
+	report := &DryRunReport{
+		Nodes: []NodeUtilizationReport{
+			{
+				Node:        "n1",
+				Allocatable: v1.ResourceList{v1.ResourceCPU: resource.MustParse("2")},
+				Before:      v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+				After:       v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	if err := WriteDryRunReport(out, DryRunReportFormatMarkdown, report); err != nil {
+		t.Fatalf("Unable to write the report: %v", err)
+	}
+	for _, expected := range []string{"No pods would be evicted.", "| n1 | 1 (50.0%) | 1 (50.0%) | - | - | - | - |"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q in the report:\n%s", expected, out.String())
+		}
+	}
+
+	if err := WriteDryRunReport(out, "yaml", report); err == nil {
+		t.Errorf("Expected an error for an unsupported format")
+	}
+}
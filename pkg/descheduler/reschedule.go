@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"fmt"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apiserver/pkg/storage/names"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/klog/v2"
+
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+)
+
+// placementSimulator recreates evicted pods managed by a controller on the node
+// a scheduler would likely pick, so the plugins running later in the same dry run
+// cycle see the replacement pods instead of capacity freed out of nowhere.
+type placementSimulator struct {
+	mu                    sync.Mutex
+	fakeClient            *fakeclientset.Clientset
+	nodes                 []*v1.Node
+	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc
+	// deleted are the evicted pods the informers may still list
+	deleted map[types.UID]bool
+	// added are the replacement pods by their node the informers may not list yet
+	added map[string]map[types.UID]*v1.Pod
+	// replacements are the replacement pods by the uid of the evicted pod
+	replacements map[types.UID]*v1.Pod
+	// origins are the namespace/name of the pods first evicted by the uid of their replacements
+	origins map[types.UID]string
+}
+
+func newPlacementSimulator(fakeClient *fakeclientset.Clientset, nodes []*v1.Node, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc) *placementSimulator {
+	return &placementSimulator{
+		fakeClient:            fakeClient,
+		nodes:                 nodes,
+		getPodsAssignedToNode: getPodsAssignedToNode,
+		deleted:               map[types.UID]bool{},
+		added:                 map[string]map[types.UID]*v1.Pod{},
+		replacements:          map[types.UID]*v1.Pod{},
+		origins:               map[types.UID]string{},
+	}
+}
+
+// GetPodsAssignedToNode lists the pods of a node as if the informers already
+// observed all the evictions and replacement pods of the simulation.
+func (s *placementSimulator) GetPodsAssignedToNode(nodeName string, filter podutil.FilterFunc) ([]*v1.Pod, error) {
+	pods, err := s.getPodsAssignedToNode(nodeName, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := map[types.UID]bool{}
+	var result []*v1.Pod
+	for _, pod := range pods {
+		seen[pod.UID] = true
+		if s.deleted[pod.UID] || (filter != nil && !filter(pod)) {
+			continue
+		}
+		result = append(result, pod)
+	}
+	for uid, pod := range s.added[nodeName] {
+		if seen[uid] || s.deleted[uid] || (filter != nil && !filter(pod)) {
+			continue
+		}
+		result = append(result, pod)
+	}
+	return result, nil
+}
+
+// replacement returns the pod created in place of the evicted one, if any
+func (s *placementSimulator) replacement(uid types.UID) *v1.Pod {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.replacements[uid]
+}
+
+// identity returns the namespace/name of the pod, or of the pod first evicted
+// if the pod replaces an evicted pod, possibly over several cycles
+func (s *placementSimulator) identity(pod *v1.Pod) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.identityLocked(pod)
+}
+
+func (s *placementSimulator) identityLocked(pod *v1.Pod) string {
+	if origin, ok := s.origins[pod.UID]; ok {
+		return origin
+	}
+	return pod.Namespace + "/" + pod.Name
+}
+
+// evictionReactionFnc creates a replacement pod for every evicted pod managed
+// by a controller and falls through to the reactor deleting the evicted pod.
+func (s *placementSimulator) evictionReactionFnc() func(action core.Action) (bool, runtime.Object, error) {
+	return func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		createAct, matched := action.(core.CreateActionImpl)
+		if !matched {
+			return false, nil, fmt.Errorf("unable to convert action to core.CreateActionImpl")
+		}
+		eviction, matched := createAct.Object.(*policy.Eviction)
+		if !matched {
+			return false, nil, fmt.Errorf("unable to convert action object into *policy.Eviction")
+		}
+		obj, err := s.fakeClient.Tracker().Get(action.GetResource(), eviction.GetNamespace(), eviction.GetName())
+		if err != nil {
+			// let the next reactor report the missing pod
+			return false, nil, nil
+		}
+		if err := s.reschedule(obj.(*v1.Pod)); err != nil {
+			return true, nil, err
+		}
+		return false, nil, nil
+	}
+}
+
+func (s *placementSimulator) reschedule(pod *v1.Pod) error {
+	s.mu.Lock()
+	s.deleted[pod.UID] = true
+	for _, added := range s.added {
+		delete(added, pod.UID)
+	}
+	s.mu.Unlock()
+
+	if !isRecreatedByController(pod) {
+		return nil
+	}
+
+	replacement := newReplacementPod(pod)
+	node := s.selectNode(replacement, pod.Spec.NodeName)
+	if node == nil {
+		klog.V(3).InfoS("No node found for the replacement of the evicted pod", "pod", klog.KObj(pod))
+		return nil
+	}
+	replacement.Spec.NodeName = node.Name
+
+	if err := s.fakeClient.Tracker().Add(replacement); err != nil {
+		return fmt.Errorf("unable to create the replacement of pod %v/%v: %v", pod.Namespace, pod.Name, err)
+	}
+	klog.V(3).InfoS("Simulated the rescheduling of the evicted pod", "pod", klog.KObj(pod), "replacement", klog.KObj(replacement), "from", pod.Spec.NodeName, "to", node.Name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.added[node.Name]; !ok {
+		s.added[node.Name] = map[types.UID]*v1.Pod{}
+	}
+	s.added[node.Name][replacement.UID] = replacement
+	s.replacements[pod.UID] = replacement
+	s.origins[replacement.UID] = s.identityLocked(pod)
+	return nil
+}
+
+// selectNode picks the least allocated node the pod fits, other than the node it was evicted from
+func (s *placementSimulator) selectNode(pod *v1.Pod, sourceNode string) *v1.Node {
+	var selected *v1.Node
+	var selectedScore float64
+	for _, node := range s.nodes {
+		if node.Name == sourceNode {
+			continue
+		}
+		if err := nodeutil.NodeFit(s.GetPodsAssignedToNode, pod, node); err != nil {
+			klog.V(4).InfoS("Replacement pod does not fit on the node", "pod", klog.KObj(pod), "node", klog.KObj(node), "err", err)
+			continue
+		}
+		score, err := s.leastAllocatedScore(pod, node)
+		if err != nil {
+			continue
+		}
+		if selected == nil || score > selectedScore {
+			selected, selectedScore = node, score
+		}
+	}
+	return selected
+}
+
+// leastAllocatedScore favors nodes with more cpu and memory left after placing the pod,
+// the same way the LeastAllocated scoring strategy of the kube-scheduler does.
+func (s *placementSimulator) leastAllocatedScore(pod *v1.Pod, node *v1.Node) (float64, error) {
+	pods, err := podutil.ListPodsOnANode(node.Name, s.GetPodsAssignedToNode, nil)
+	if err != nil {
+		return 0, err
+	}
+	resourceNames := []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+	requested := nodeutil.NodeUtilization(append(pods, pod), resourceNames)
+
+	allocatable := node.Status.Allocatable
+	if len(allocatable) == 0 {
+		allocatable = node.Status.Capacity
+	}
+
+	var score float64
+	for _, name := range resourceNames {
+		capacity, ok := allocatable[name]
+		if !ok || capacity.IsZero() {
+			continue
+		}
+		score += float64(capacity.MilliValue()-requested[name].MilliValue()) / float64(capacity.MilliValue())
+	}
+	return score / float64(len(resourceNames)), nil
+}
+
+// isRecreatedByController checks the evicted pod gets replaced by its owner on another node.
+// Pods of a DaemonSet are recreated on the same node only.
+func isRecreatedByController(pod *v1.Pod) bool {
+	ownerRefs := podutil.OwnerRef(pod)
+	if len(ownerRefs) == 0 {
+		return false
+	}
+	for _, ownerRef := range ownerRefs {
+		if ownerRef.Kind == "DaemonSet" {
+			return false
+		}
+	}
+	return true
+}
+
+func newReplacementPod(pod *v1.Pod) *v1.Pod {
+	generateName := pod.GenerateName
+	if generateName == "" {
+		generateName = pod.Name + "-"
+	}
+
+	replacement := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              names.SimpleNameGenerator.GenerateName(generateName),
+			GenerateName:      pod.GenerateName,
+			Namespace:         pod.Namespace,
+			UID:               uuid.NewUUID(),
+			Labels:            pod.Labels,
+			Annotations:       pod.Annotations,
+			OwnerReferences:   pod.OwnerReferences,
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: *pod.Spec.DeepCopy(),
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+		},
+	}
+	replacement.Spec.NodeName = ""
+	return replacement
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestDryRunReschedule(t *testing.T) {
+	initPluginRegistry()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	node1 := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+		node.Spec.Taints = []v1.Taint{
+			{
+				Key:    "key",
+				Value:  "value",
+				Effect: v1.TaintEffectNoSchedule,
+			},
+		}
+	})
+	node2 := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+	node3 := test.BuildTestNode("n3", 2000, 3000, 10, nil)
+	node4 := test.BuildTestNode("n4", 2000, 3000, 10, func(node *v1.Node) {
+		node.Spec.Unschedulable = true
+	})
+
+	p1 := test.BuildTestPod("p1", 500, 0, node1.Name, nil)
+	p1.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+	p2 := test.BuildTestPod("p2", 1200, 0, node2.Name, nil)
+	p2.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+	p3 := test.BuildTestPod("p3", 200, 0, node3.Name, nil)
+	p3.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+
+	rs, descheduler, _ := initDescheduler(t, ctx, removePodsViolatingNodeTaintsPolicy(), node1, node2, node3, node4, p1, p2, p3)
+	rs.DryRun = true
+	rs.DryRunReschedule = true
+	rs.DryRunReport = filepath.Join(t.TempDir(), "report.json")
+
+	if err := descheduler.runDeschedulerLoop(ctx, []*v1.Node{node1, node2, node3, node4}); err != nil {
+		t.Fatalf("Unable to run a descheduling loop: %v", err)
+	}
+
+	data, err := os.ReadFile(rs.DryRunReport)
+	if err != nil {
+		t.Fatalf("Unable to read the dry run report: %v", err)
+	}
+	report := &DryRunReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		t.Fatalf("Unable to decode the dry run report: %v", err)
+	}
+
+	// n4 is unschedulable, n3 is less allocated than n2
+	if len(report.Evictions) != 1 || report.Evictions[0].Pod != "p1" || report.Evictions[0].Destination != "n3" {
+		t.Fatalf("Expected p1 to move to n3, got %+v", report.Evictions)
+	}
+
+	got := map[string][2]int64{}
+	for _, node := range report.Nodes {
+		before, after := node.Before[v1.ResourceCPU], node.After[v1.ResourceCPU]
+		got[node.Node] = [2]int64{before.MilliValue(), after.MilliValue()}
+	}
+	expected := map[string][2]int64{"n1": {500, 0}, "n2": {1200, 1200}, "n3": {200, 700}, "n4": {0, 0}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Unexpected cpu utilization (-want +got):\n%s", diff)
+	}
+}
+
+func TestPlacementSimulatorGetPodsAssignedToNode(t *testing.T) {
+	node1 := test.BuildTestNode("n1", 2000, 3000, 10, nil)
+	node2 := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+
+	owned := test.BuildTestPod("owned", 100, 0, node1.Name, nil)
+	owned.ObjectMeta.OwnerReferences = test.GetReplicaSetOwnerRefList()
+	daemon := test.BuildTestPod("daemon", 100, 0, node1.Name, nil)
+	daemon.ObjectMeta.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", APIVersion: "apps/v1", Name: "ds"}}
+	orphan := test.BuildTestPod("orphan", 100, 0, node1.Name, nil)
+
+	fakeClient := fakeclientset.NewSimpleClientset(owned, daemon, orphan)
+	// an informer which never observes any change
+	listed := map[string][]*v1.Pod{node1.Name: {owned, daemon, orphan}}
+	getPodsAssignedToNode := func(nodeName string, filter podutil.FilterFunc) ([]*v1.Pod, error) {
+		return listed[nodeName], nil
+	}
+
+	simulator := newPlacementSimulator(fakeClient, []*v1.Node{node1, node2}, getPodsAssignedToNode)
+	for _, pod := range []*v1.Pod{owned, daemon, orphan} {
+		if err := simulator.reschedule(pod); err != nil {
+			t.Fatalf("Unable to reschedule %v: %v", pod.Name, err)
+		}
+	}
+
+	if pods, _ := simulator.GetPodsAssignedToNode(node1.Name, nil); len(pods) != 0 {
+		t.Errorf("Expected no pods on n1, got %v", len(pods))
+	}
+	pods, _ := simulator.GetPodsAssignedToNode(node2.Name, nil)
+	if len(pods) != 1 || pods[0].OwnerReferences[0].Name != owned.OwnerReferences[0].Name {
+		t.Fatalf("Expected a single replacement of the owned pod on n2, got %v", pods)
+	}
+	if replacement := simulator.replacement(owned.UID); replacement == nil || replacement.UID != pods[0].UID {
+		t.Errorf("Expected the replacement of the owned pod to be tracked")
+	}
+	if simulator.replacement(daemon.UID) != nil || simulator.replacement(orphan.UID) != nil {
+		t.Errorf("Expected pods of a DaemonSet and pods without an owner not to be replaced")
+	}
+	if _, err := fakeClient.Tracker().Get(v1.SchemeGroupVersion.WithResource("pods"), pods[0].Namespace, pods[0].Name); err != nil {
+		t.Errorf("Expected the replacement pod to be created: %v", err)
+	}
+}
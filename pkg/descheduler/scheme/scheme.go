@@ -25,9 +25,14 @@ import (
 	"github.com/amit3512/descheduler_policy_master/pkg/api/v1alpha2"
 	"github.com/amit3512/descheduler_policy_master/pkg/apis/componentconfig"
 	componentconfigv1alpha1 "github.com/amit3512/descheduler_policy_master/pkg/apis/componentconfig/v1alpha1"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/celdeschedule"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/celevictor"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/extender"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/nodeconsolidation"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/nodeutilization"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/podlifetime"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/podsorting"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/removeduplicates"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/removefailedpods"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/removepodshavingtoomanyrestarts"
@@ -45,9 +50,14 @@ var (
 
 func init() {
 	utilruntime.Must(api.AddToScheme(Scheme))
+	utilruntime.Must(celdeschedule.AddToScheme(Scheme))
+	utilruntime.Must(celevictor.AddToScheme(Scheme))
 	utilruntime.Must(defaultevictor.AddToScheme(Scheme))
+	utilruntime.Must(extender.AddToScheme(Scheme))
+	utilruntime.Must(nodeconsolidation.AddToScheme(Scheme))
 	utilruntime.Must(nodeutilization.AddToScheme(Scheme))
 	utilruntime.Must(podlifetime.AddToScheme(Scheme))
+	utilruntime.Must(podsorting.AddToScheme(Scheme))
 	utilruntime.Must(removeduplicates.AddToScheme(Scheme))
 	utilruntime.Must(removefailedpods.AddToScheme(Scheme))
 	utilruntime.Must(removepodshavingtoomanyrestarts.AddToScheme(Scheme))
@@ -18,9 +18,15 @@ package descheduler
 
 import (
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/celdeschedule"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/celevictor"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/custompolicy"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/extender"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/nodeconsolidation"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/nodeutilization"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/podlifetime"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/podsorting"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/removeduplicates"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/removefailedpods"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/removepodshavingtoomanyrestarts"
@@ -36,10 +42,19 @@ func SetupPlugins() {
 }
 
 func RegisterDefaultPlugins(registry pluginregistry.Registry) {
+	pluginregistry.Register(celdeschedule.PluginName, celdeschedule.New, &celdeschedule.CELDeschedule{}, &celdeschedule.CELDescheduleArgs{}, celdeschedule.ValidateCELDescheduleArgs, celdeschedule.SetDefaults_CELDescheduleArgs, registry)
+	pluginregistry.Register(celevictor.PluginName, celevictor.New, &celevictor.CELEvictor{}, &celevictor.CELEvictorArgs{}, celevictor.ValidateCELEvictorArgs, celevictor.SetDefaults_CELEvictorArgs, registry)
+	pluginregistry.Register(custompolicy.CustomPolicyOnePluginName, custompolicy.NewCustomPolicyOne, &custompolicy.CustomPolicyOne{}, &custompolicy.CustomPolicyOneArgs{}, custompolicy.ValidateCustomPolicyOneArgs, custompolicy.SetDefaults_CustomPolicyOneArgs, registry)
 	pluginregistry.Register(defaultevictor.PluginName, defaultevictor.New, &defaultevictor.DefaultEvictor{}, &defaultevictor.DefaultEvictorArgs{}, defaultevictor.ValidateDefaultEvictorArgs, defaultevictor.SetDefaults_DefaultEvictorArgs, registry)
+	pluginregistry.Register(extender.PluginName, extender.New, &extender.Extender{}, &extender.ExtenderArgs{}, extender.ValidateExtenderArgs, extender.SetDefaults_ExtenderArgs, registry)
+	pluginregistry.Register(nodeconsolidation.PluginName, nodeconsolidation.New, &nodeconsolidation.NodeConsolidation{}, &nodeconsolidation.NodeConsolidationArgs{}, nodeconsolidation.ValidateNodeConsolidationArgs, nodeconsolidation.SetDefaults_NodeConsolidationArgs, registry)
 	pluginregistry.Register(nodeutilization.LowNodeUtilizationPluginName, nodeutilization.NewLowNodeUtilization, &nodeutilization.LowNodeUtilization{}, &nodeutilization.LowNodeUtilizationArgs{}, nodeutilization.ValidateLowNodeUtilizationArgs, nodeutilization.SetDefaults_LowNodeUtilizationArgs, registry)
 	pluginregistry.Register(nodeutilization.HighNodeUtilizationPluginName, nodeutilization.NewHighNodeUtilization, &nodeutilization.HighNodeUtilization{}, &nodeutilization.HighNodeUtilizationArgs{}, nodeutilization.ValidateHighNodeUtilizationArgs, nodeutilization.SetDefaults_HighNodeUtilizationArgs, registry)
 	pluginregistry.Register(podlifetime.PluginName, podlifetime.New, &podlifetime.PodLifeTime{}, &podlifetime.PodLifeTimeArgs{}, podlifetime.ValidatePodLifeTimeArgs, podlifetime.SetDefaults_PodLifeTimeArgs, registry)
+	pluginregistry.Register(podsorting.SortByPriorityPluginName, podsorting.NewSortByPriority, &podsorting.SortByPriority{}, &podsorting.PodSortingArgs{}, nil, podsorting.SetDefaults_PodSortingArgs, registry)
+	pluginregistry.Register(podsorting.SortByQoSClassPluginName, podsorting.NewSortByQoSClass, &podsorting.SortByQoSClass{}, &podsorting.PodSortingArgs{}, nil, podsorting.SetDefaults_PodSortingArgs, registry)
+	pluginregistry.Register(podsorting.SortByAgePluginName, podsorting.NewSortByAge, &podsorting.SortByAge{}, &podsorting.PodSortingArgs{}, nil, podsorting.SetDefaults_PodSortingArgs, registry)
+	pluginregistry.Register(podsorting.SortByDeletionCostPluginName, podsorting.NewSortByDeletionCost, &podsorting.SortByDeletionCost{}, &podsorting.PodSortingArgs{}, nil, podsorting.SetDefaults_PodSortingArgs, registry)
 	pluginregistry.Register(removeduplicates.PluginName, removeduplicates.New, &removeduplicates.RemoveDuplicates{}, &removeduplicates.RemoveDuplicatesArgs{}, removeduplicates.ValidateRemoveDuplicatesArgs, removeduplicates.SetDefaults_RemoveDuplicatesArgs, registry)
 	pluginregistry.Register(removefailedpods.PluginName, removefailedpods.New, &removefailedpods.RemoveFailedPods{}, &removefailedpods.RemoveFailedPodsArgs{}, removefailedpods.ValidateRemoveFailedPodsArgs, removefailedpods.SetDefaults_RemoveFailedPodsArgs, registry)
 	pluginregistry.Register(removepodshavingtoomanyrestarts.PluginName, removepodshavingtoomanyrestarts.New, &removepodshavingtoomanyrestarts.RemovePodsHavingTooManyRestarts{}, &removepodshavingtoomanyrestarts.RemovePodsHavingTooManyRestartsArgs{}, removepodshavingtoomanyrestarts.ValidateRemovePodsHavingTooManyRestartsArgs, removepodshavingtoomanyrestarts.SetDefaults_RemovePodsHavingTooManyRestartsArgs, registry)
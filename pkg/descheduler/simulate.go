@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	core "k8s.io/client-go/testing"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/cmd/descheduler/app/options"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+	"github.com/amit3512/descheduler_policy_master/pkg/utils"
+)
+
+// SimulatedEviction is a pod the descheduler would evict
+type SimulatedEviction struct {
+	Pod     *v1.Pod
+	Profile string
+	Plugin  string
+	Reason  string
+	// Cycle is the cycle of the eviction, starting at 1
+	Cycle int
+	// Destination is the node a replacement of the pod was placed on, if any
+	Destination string
+}
+
+// SimulationResult holds the outcome of the simulated descheduling cycles
+type SimulationResult struct {
+	// Evictions are the evicted pods in order
+	Evictions []SimulatedEviction
+	// Oscillations is set when more than one cycle was simulated
+	Oscillations *OscillationReport
+}
+
+// LoadSnapshot reads a ClusterSnapshot or a List of Nodes, Pods, Namespaces,
+// PriorityClasses and PodDisruptionBudgets serialized as YAML or JSON.
+func LoadSnapshot(snapshotFile string) ([]runtime.Object, error) {
+	data, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot file %q: %v", snapshotFile, err)
+	}
+	return decodeSnapshot(snapshotFile, data)
+}
+
+func decodeSnapshot(snapshotFile string, data []byte) ([]runtime.Object, error) {
+	items, err := snapshotItems(snapshotFile, data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := clientgoscheme.Codecs.UniversalDeserializer()
+	objects := make([]runtime.Object, 0, len(items))
+	for idx, item := range items {
+		obj, _, err := decoder.Decode(item.Raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed decoding item %d of snapshot %q: %v", idx, snapshotFile, err)
+		}
+		switch obj.(type) {
+		case *v1.Node, *v1.Pod, *v1.Namespace, *schedulingv1.PriorityClass, *policyv1.PodDisruptionBudget:
+			objects = append(objects, obj)
+		default:
+			return nil, fmt.Errorf("item %d of snapshot %q has unsupported kind %v", idx, snapshotFile, obj.GetObjectKind().GroupVersionKind())
+		}
+	}
+	return objects, nil
+}
+
+// pdbEvictionReactionFnc rejects evictions of pods covered by a PodDisruptionBudget
+// with no disruptions allowed, the same way the eviction API does. Allowed evictions
+// consume the disruptions of the budgets and fall through to the next reactor.
+func pdbEvictionReactionFnc(fakeClient *fakeclientset.Clientset) func(action core.Action) (bool, runtime.Object, error) {
+	return func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		createAct, matched := action.(core.CreateActionImpl)
+		if !matched {
+			return false, nil, fmt.Errorf("unable to convert action to core.CreateActionImpl")
+		}
+		eviction, matched := createAct.Object.(*policyv1.Eviction)
+		if !matched {
+			return false, nil, fmt.Errorf("unable to convert action object into *policy.Eviction")
+		}
+
+		obj, err := fakeClient.Tracker().Get(v1.SchemeGroupVersion.WithResource("pods"), eviction.GetNamespace(), eviction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		pod := obj.(*v1.Pod)
+
+		pdbs, err := fakeClient.Tracker().List(policyv1.SchemeGroupVersion.WithResource("poddisruptionbudgets"), policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget"), pod.Namespace)
+		if err != nil {
+			return true, nil, err
+		}
+		var matchingPDBs []*policyv1.PodDisruptionBudget
+		for _, item := range pdbs.(*policyv1.PodDisruptionBudgetList).Items {
+			selector, err := metav1.LabelSelectorAsSelector(item.Spec.Selector)
+			if err != nil || selector.Empty() || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			if item.Status.DisruptionsAllowed < 1 {
+				return true, nil, apierrors.NewTooManyRequests(fmt.Sprintf("Cannot evict pod as it would violate the pod's disruption budget %q", item.Name), 0)
+			}
+			matchingPDBs = append(matchingPDBs, item.DeepCopy())
+		}
+		for _, pdb := range matchingPDBs {
+			pdb.Status.DisruptionsAllowed--
+			if err := fakeClient.Tracker().Update(policyv1.SchemeGroupVersion.WithResource("poddisruptionbudgets"), pdb, pdb.Namespace); err != nil {
+				return true, nil, err
+			}
+		}
+		return false, nil, nil
+	}
+}
+
+// Simulate runs the given number of consecutive descheduling cycles of the policy against
+// the snapshot objects without contacting any API server. With more than one cycle a replacement
+// of every evicted pod managed by a controller is placed on another node, so the cluster evolves
+// between the cycles, and the repeated evictions and flapping nodes are reported.
+func Simulate(ctx context.Context, policyConfigFile string, objects []runtime.Object, cycles int) (*SimulationResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	fakeClient := fakeclientset.NewSimpleClientset(objects...)
+	// simulate a pod eviction by deleting a pod
+	fakeClient.PrependReactor("create", "pods", podEvictionReactionFnc(fakeClient))
+
+	deschedulerPolicy, err := LoadPolicyConfig(policyConfigFile, fakeClient, pluginregistry.PluginRegistry)
+	if err != nil {
+		return nil, err
+	}
+	if deschedulerPolicy == nil {
+		return nil, fmt.Errorf("deschedulerPolicy is nil")
+	}
+
+	rs, err := options.NewDeschedulerServer()
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize server: %v", err)
+	}
+	rs.Client = fakeClient
+	rs.EventClient = fakeClient
+	rs.DisableMetrics = true
+
+	sharedInformerFactory := newSharedInformerFactory(fakeClient)
+	eventBroadcaster, eventRecorder := utils.GetRecorderAndBroadcaster(ctx, fakeClient)
+	defer eventBroadcaster.Shutdown()
+
+	evictionPolicyGroupVersion := policyv1.SchemeGroupVersion.String()
+	descheduler, err := newDescheduler(rs, deschedulerPolicy, evictionPolicyGroupVersion, eventRecorder, sharedInformerFactory)
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	result := &SimulationResult{Evictions: []SimulatedEviction{}}
+	descheduler.podEvictor = evictions.NewPodEvictor(
+		nil,
+		eventRecorder,
+		podEvictorOptions(rs, deschedulerPolicy, evictionPolicyGroupVersion).
+			WithEvictionHandler(func(pod *v1.Pod, opts evictions.EvictOptions) {
+				descheduler.recordEviction(pod, opts)
+
+				mu.Lock()
+				defer mu.Unlock()
+				eviction := SimulatedEviction{
+					Pod:     pod,
+					Profile: opts.ProfileName,
+					Plugin:  opts.StrategyName,
+					Reason:  opts.Reason,
+					Cycle:   1,
+				}
+				if descheduler.oscillationDetector != nil {
+					eviction.Cycle = descheduler.oscillationDetector.currentCycle()
+				}
+				if descheduler.placementSimulator != nil {
+					if replacement := descheduler.placementSimulator.replacement(pod.UID); replacement != nil {
+						eviction.Destination = replacement.Spec.NodeName
+					}
+				}
+				result.Evictions = append(result.Evictions, eviction)
+			}),
+	)
+
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+	var nodeSelector string
+	if deschedulerPolicy.NodeSelector != nil {
+		nodeSelector = *deschedulerPolicy.NodeSelector
+	}
+	nodes, err := nodeutil.ReadyNodes(ctx, fakeClient, descheduler.nodeLister, nodeSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	if cycles > 1 {
+		descheduler.placementSimulator = newPlacementSimulator(fakeClient, nodes, descheduler.getPodsAssignedToNode)
+		fakeClient.PrependReactor("create", "pods", descheduler.placementSimulator.evictionReactionFnc())
+		descheduler.getPodsAssignedToNode = descheduler.placementSimulator.GetPodsAssignedToNode
+		descheduler.oscillationDetector = newOscillationDetector()
+	} else {
+		cycles = 1
+	}
+	// checked first so pods protected by a budget are neither replaced nor deleted
+	fakeClient.PrependReactor("create", "pods", pdbEvictionReactionFnc(fakeClient))
+
+	klog.V(1).InfoS("Simulating descheduling cycles", "cycles", cycles, "nodes", len(nodes), "objects", len(objects))
+	for i := 0; i < cycles; i++ {
+		if err := descheduler.runDeschedulerLoop(ctx, nodes); err != nil {
+			return nil, err
+		}
+	}
+
+	if descheduler.oscillationDetector != nil {
+		result.Oscillations = descheduler.oscillationDetector.report()
+	}
+	return result, nil
+}
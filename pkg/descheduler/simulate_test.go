@@ -0,0 +1,171 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const simulationSnapshot = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: n1
+  spec:
+    taints:
+    - key: dedicated
+      value: infra
+      effect: NoSchedule
+  status:
+    allocatable: {cpu: "2", memory: 4Gi, pods: "10"}
+    conditions:
+    - {type: Ready, status: "True"}
+- apiVersion: v1
+  kind: Node
+  metadata:
+    name: n2
+  status:
+    allocatable: {cpu: "2", memory: 4Gi, pods: "10"}
+    conditions:
+    - {type: Ready, status: "True"}
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: dev
+- apiVersion: scheduling.k8s.io/v1
+  kind: PriorityClass
+  metadata:
+    name: low
+  value: 10
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: web
+    namespace: dev
+    labels: {app: web}
+    ownerReferences:
+    - {apiVersion: apps/v1, kind: ReplicaSet, name: web, uid: "1"}
+  spec:
+    nodeName: n1
+    priorityClassName: low
+    priority: 10
+    containers:
+    - {name: c, image: nginx}
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: db
+    namespace: dev
+    labels: {app: db}
+    ownerReferences:
+    - {apiVersion: apps/v1, kind: ReplicaSet, name: db, uid: "2"}
+  spec:
+    nodeName: n1
+    containers:
+    - {name: c, image: postgres}
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: cache
+    namespace: dev
+    ownerReferences:
+    - {apiVersion: apps/v1, kind: ReplicaSet, name: cache, uid: "3"}
+  spec:
+    nodeName: n2
+    containers:
+    - {name: c, image: redis}
+- apiVersion: policy/v1
+  kind: PodDisruptionBudget
+  metadata:
+    name: db
+    namespace: dev
+  spec:
+    minAvailable: 1
+    selector:
+      matchLabels: {app: db}
+  status:
+    disruptionsAllowed: 0
+`
+
+const simulationPolicy = `
+apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+profiles:
+  - name: taints
+    pluginConfig:
+    - name: "DefaultEvictor"
+    - name: "RemovePodsViolatingNodeTaints"
+    plugins:
+      deschedule:
+        enabled:
+          - "RemovePodsViolatingNodeTaints"
+`
+
+func TestSimulate(t *testing.T) {
+	SetupPlugins()
+
+	dir := t.TempDir()
+	snapshotFile := filepath.Join(dir, "cluster.yaml")
+	policyFile := filepath.Join(dir, "policy.yaml")
+	if err := os.WriteFile(snapshotFile, []byte(simulationSnapshot), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(policyFile, []byte(simulationPolicy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := LoadSnapshot(snapshotFile)
+	if err != nil {
+		t.Fatalf("Unable to load the snapshot: %v", err)
+	}
+	if len(objects) != 8 {
+		t.Fatalf("Expected 8 objects in the snapshot, got %v", len(objects))
+	}
+
+	result, err := Simulate(context.Background(), policyFile, objects, 1)
+	if err != nil {
+		t.Fatalf("Unable to simulate: %v", err)
+	}
+	if result.Oscillations != nil {
+		t.Errorf("Expected no oscillation report for a single cycle")
+	}
+
+	// db is protected by its PodDisruptionBudget, cache runs on an untainted node
+	got := [][]string{}
+	for _, eviction := range result.Evictions {
+		got = append(got, []string{eviction.Profile, eviction.Plugin, eviction.Pod.Namespace + "/" + eviction.Pod.Name, eviction.Pod.Spec.NodeName})
+	}
+	expected := [][]string{{"taints", "RemovePodsViolatingNodeTaints", "dev/web", "n1"}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Errorf("Unexpected simulated evictions (-want +got):\n%s", diff)
+	}
+}
+
+func TestLoadSnapshotUnsupportedKind(t *testing.T) {
+	snapshot := `{"apiVersion": "v1", "kind": "List", "items": [{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm"}}]}`
+	if _, err := decodeSnapshot("snapshot.json", []byte(snapshot)); err == nil {
+		t.Errorf("Expected an error for an unsupported kind")
+	}
+}
@@ -0,0 +1,197 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientset "k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// SnapshotAPIVersion is the version of the snapshot file format
+	SnapshotAPIVersion = "descheduler/v1alpha1"
+	// SnapshotKind is the kind of the snapshot file
+	SnapshotKind = "ClusterSnapshot"
+)
+
+// ClusterSnapshot holds the cluster objects the descheduler makes its decisions on
+type ClusterSnapshot struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// CreationTimestamp is the time the snapshot was taken
+	CreationTimestamp metav1.Time `json:"creationTimestamp"`
+
+	// Anonymized is set when names and labels of the objects were replaced
+	Anonymized bool `json:"anonymized,omitempty"`
+
+	// Items are the Nodes, Pods, Namespaces, PriorityClasses and PodDisruptionBudgets
+	Items []runtime.RawExtension `json:"items"`
+}
+
+// TakeSnapshot lists Nodes, Pods, Namespaces, PriorityClasses and PodDisruptionBudgets
+// through the informers the descheduler runs with. When anonymize is set, names,
+// labels and other identifying fields are replaced with generated ones consistently
+// across all the objects so selectors keep matching.
+func TakeSnapshot(ctx context.Context, client clientset.Interface, anonymize bool) (*ClusterSnapshot, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sharedInformerFactory := newSharedInformerFactory(client)
+	listers := newListers(sharedInformerFactory)
+	pdbLister := sharedInformerFactory.Policy().V1().PodDisruptionBudgets().Lister()
+
+	sharedInformerFactory.Start(ctx.Done())
+	if err := waitForCacheSync(ctx, sharedInformerFactory); err != nil {
+		return nil, err
+	}
+
+	var objects []runtime.Object
+
+	namespaces, err := listers.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list namespaces: %v", err)
+	}
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].Name < namespaces[j].Name })
+	for _, item := range namespaces {
+		objects = append(objects, item.DeepCopy())
+	}
+
+	priorityClasses, err := listers.priorityClassLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list priorityclasses: %v", err)
+	}
+	sort.Slice(priorityClasses, func(i, j int) bool { return priorityClasses[i].Name < priorityClasses[j].Name })
+	for _, item := range priorityClasses {
+		objects = append(objects, item.DeepCopy())
+	}
+
+	nodes, err := listers.nodeLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %v", err)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	for _, item := range nodes {
+		objects = append(objects, item.DeepCopy())
+	}
+
+	pods, err := listers.podLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list pods: %v", err)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		if pods[i].Namespace != pods[j].Namespace {
+			return pods[i].Namespace < pods[j].Namespace
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	for _, item := range pods {
+		objects = append(objects, item.DeepCopy())
+	}
+
+	pdbs, err := pdbLister.List(labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("unable to list poddisruptionbudgets: %v", err)
+	}
+	sort.Slice(pdbs, func(i, j int) bool {
+		if pdbs[i].Namespace != pdbs[j].Namespace {
+			return pdbs[i].Namespace < pdbs[j].Namespace
+		}
+		return pdbs[i].Name < pdbs[j].Name
+	})
+	for _, item := range pdbs {
+		objects = append(objects, item.DeepCopy())
+	}
+
+	if anonymize {
+		anonymizer := newAnonymizer()
+		for _, obj := range objects {
+			anonymizer.anonymize(obj)
+		}
+	}
+
+	snapshot := &ClusterSnapshot{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: SnapshotAPIVersion,
+			Kind:       SnapshotKind,
+		},
+		CreationTimestamp: metav1.Now(),
+		Anonymized:        anonymize,
+		Items:             make([]runtime.RawExtension, 0, len(objects)),
+	}
+	for _, obj := range objects {
+		gvks, _, err := clientgoscheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return nil, err
+		}
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		snapshot.Items = append(snapshot.Items, runtime.RawExtension{Raw: raw})
+	}
+
+	klog.V(1).InfoS("Took a cluster snapshot", "nodes", len(nodes), "pods", len(pods), "namespaces", len(namespaces), "priorityClasses", len(priorityClasses), "podDisruptionBudgets", len(pdbs))
+	return snapshot, nil
+}
+
+// WriteSnapshot serializes the snapshot as YAML
+func WriteSnapshot(out io.Writer, snapshot *ClusterSnapshot) error {
+	data, err := yaml.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed encoding snapshot: %v", err)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// snapshotItems returns the raw items of either a ClusterSnapshot or a v1 List
+func snapshotItems(snapshotFile string, data []byte) ([]runtime.RawExtension, error) {
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return nil, fmt.Errorf("failed decoding snapshot %q: %v", snapshotFile, err)
+	}
+
+	if typeMeta.Kind == SnapshotKind {
+		if typeMeta.APIVersion != SnapshotAPIVersion {
+			return nil, fmt.Errorf("snapshot %q has unsupported version %q, expected %q", snapshotFile, typeMeta.APIVersion, SnapshotAPIVersion)
+		}
+		snapshot := &ClusterSnapshot{}
+		if err := yaml.Unmarshal(data, snapshot); err != nil {
+			return nil, fmt.Errorf("failed decoding snapshot %q: %v", snapshotFile, err)
+		}
+		return snapshot.Items, nil
+	}
+
+	list := &v1.List{}
+	if err := runtime.DecodeInto(clientgoscheme.Codecs.UniversalDeserializer(), data, list); err != nil {
+		return nil, fmt.Errorf("failed decoding snapshot %q: %v", snapshotFile, err)
+	}
+	return list.Items, nil
+}
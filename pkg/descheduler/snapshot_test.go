@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	SetupPlugins()
+
+	tests := []struct {
+		name      string
+		anonymize bool
+	}{
+		{
+			name: "plain snapshot",
+		},
+		{
+			name:      "anonymized snapshot",
+			anonymize: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			objects, err := decodeSnapshot("cluster.yaml", []byte(simulationSnapshot))
+			if err != nil {
+				t.Fatalf("Unable to decode the snapshot: %v", err)
+			}
+			for _, obj := range objects {
+				// managed fields are expected to be trimmed
+				obj.(metav1.Object).SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl"}})
+			}
+			client := fakeclientset.NewSimpleClientset(objects...)
+
+			snapshot, err := TakeSnapshot(ctx, client, tc.anonymize)
+			if err != nil {
+				t.Fatalf("Unable to take a snapshot: %v", err)
+			}
+			if snapshot.APIVersion != SnapshotAPIVersion || snapshot.Kind != SnapshotKind {
+				t.Errorf("Unexpected snapshot type %v/%v", snapshot.APIVersion, snapshot.Kind)
+			}
+
+			out := &bytes.Buffer{}
+			if err := WriteSnapshot(out, snapshot); err != nil {
+				t.Fatalf("Unable to write the snapshot: %v", err)
+			}
+			if strings.Contains(out.String(), "managedFields") {
+				t.Errorf("Expected managed fields to be trimmed")
+			}
+			if tc.anonymize {
+				for _, identifying := range []string{"dev", "web", "postgres", "nginx", "dedicated", "infra", "n1"} {
+					if strings.Contains(out.String(), identifying) {
+						t.Errorf("Expected %q to be anonymized", identifying)
+					}
+				}
+			}
+
+			dir := t.TempDir()
+			policyFile := filepath.Join(dir, "policy.yaml")
+			if err := os.WriteFile(policyFile, []byte(simulationPolicy), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			loaded, err := decodeSnapshot("snapshot.yaml", out.Bytes())
+			if err != nil {
+				t.Fatalf("Unable to decode the written snapshot: %v", err)
+			}
+			if len(loaded) != len(objects) {
+				t.Fatalf("Expected %v objects in the snapshot, got %v", len(objects), len(loaded))
+			}
+
+			// the same decisions are expected to be made on the snapshot
+			result, err := Simulate(ctx, policyFile, loaded, 1)
+			if err != nil {
+				t.Fatalf("Unable to simulate: %v", err)
+			}
+			simulatedEvictions := result.Evictions
+			if len(simulatedEvictions) != 1 {
+				t.Fatalf("Expected 1 simulated eviction, got %v", len(simulatedEvictions))
+			}
+			pod := simulatedEvictions[0].Pod
+			if tc.anonymize == (pod.Name == "web") {
+				t.Errorf("Unexpected name of the evicted pod %q", pod.Name)
+			}
+
+			var taintedNode string
+			for _, obj := range loaded {
+				if node, ok := obj.(*v1.Node); ok && len(node.Spec.Taints) > 0 {
+					taintedNode = node.Name
+				}
+			}
+			if pod.Spec.NodeName != taintedNode {
+				t.Errorf("Expected the evicted pod to run on the tainted node %q, got %q", taintedNode, pod.Spec.NodeName)
+			}
+		})
+	}
+}
+
+func TestDecodeSnapshotUnsupportedVersion(t *testing.T) {
+	snapshot := `{"apiVersion": "descheduler/v1", "kind": "ClusterSnapshot", "items": []}`
+	if _, err := decodeSnapshot("snapshot.json", []byte(snapshot)); err == nil {
+		t.Errorf("Expected an error for an unsupported snapshot version")
+	}
+}
+
+func TestTakeSnapshotUnsyncedInformers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	// the informers never sync with the context cancelled before they start
+	cancel()
+	if _, err := TakeSnapshot(ctx, fakeclientset.NewSimpleClientset(), false); err == nil {
+		t.Errorf("Expected an error when the informers do not sync")
+	}
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	fakeclientset "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/klog/v2"
+	sigsjson "sigs.k8s.io/json"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/api/v1alpha1"
+	"github.com/amit3512/descheduler_policy_master/pkg/api/v1alpha2"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	frameworkprofile "github.com/amit3512/descheduler_policy_master/pkg/framework/profile"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+)
+
+// PolicyProblem is a single problem found in a descheduler policy
+type PolicyProblem struct {
+	// Profile is the name of the profile the problem was found in, if any
+	Profile string
+	// Plugin is the name of the plugin the problem was found in, if any
+	Plugin string
+	// Path is the location of the problem in the policy, e.g. profiles[0].pluginConfig[1].args.thresholds.
+	// It is empty for problems of policies converted from v1alpha1 found after the conversion.
+	Path string
+	// Line is the line of the policy file the path starts at, or 0 if unknown
+	Line    int
+	Message string
+}
+
+func (p PolicyProblem) String() string {
+	var b strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&b, "line %d: ", p.Line)
+	}
+	if p.Path != "" {
+		fmt.Fprintf(&b, "%s: ", p.Path)
+	}
+	if p.Profile != "" {
+		fmt.Fprintf(&b, "profile %q: ", p.Profile)
+	}
+	if p.Plugin != "" {
+		fmt.Fprintf(&b, "plugin %q: ", p.Plugin)
+	}
+	b.WriteString(p.Message)
+	return b.String()
+}
+
+// ValidatePolicyFile reports all the problems found in a policy file without contacting any cluster.
+// Beside the checks done when the descheduler loads the policy, unknown fields are reported, and
+// all the plugins are built the same way the descheduler does at the start of every cycle.
+func ValidatePolicyFile(policyConfigFile string) ([]PolicyProblem, error) {
+	data, err := os.ReadFile(policyConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy config file %q: %+v", policyConfigFile, err)
+	}
+	return validatePolicy(policyConfigFile, data, pluginregistry.PluginRegistry), nil
+}
+
+func validatePolicy(policyConfigFile string, data []byte, registry pluginregistry.Registry) []PolicyProblem {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(data, root); err != nil {
+		return []PolicyProblem{{Message: fmt.Sprintf("unable to parse the policy: %v", err)}}
+	}
+	lineOf := func(problems []PolicyProblem) []PolicyProblem {
+		for i := range problems {
+			if problems[i].Path != "" {
+				problems[i].Line = yamlPathLine(root, problems[i].Path)
+			}
+		}
+		return problems
+	}
+
+	jsonData, err := sigsyaml.YAMLToJSON(data)
+	if err != nil {
+		return []PolicyProblem{{Message: fmt.Sprintf("unable to parse the policy: %v", err)}}
+	}
+	problems, knownFields := strictPolicyProblems(jsonData, registry)
+	if knownFields == nil {
+		knownFields = data
+	}
+
+	internalPolicy, err := decodePolicy(policyConfigFile, knownFields)
+	if err != nil {
+		// the strict checks already point at what the decoder failed on
+		if len(problems) == 0 {
+			problems = append(problems, PolicyProblem{Message: err.Error()})
+		}
+		return lineOf(problems)
+	}
+
+	// paths of the internal policy match the v1alpha2 fields only
+	typeMeta := &metav1.TypeMeta{}
+	_ = sigsyaml.Unmarshal(data, typeMeta)
+	withPaths := typeMeta.APIVersion == v1alpha2.SchemeGroupVersion.String()
+
+	client := fakeclientset.NewSimpleClientset()
+	for i, profile := range internalPolicy.Profiles {
+		profileProblems := profileConfigProblems(i, profile, registry)
+		if len(profileProblems) == 0 {
+			profileProblems = buildProfileProblems(i, profile, client, registry)
+		}
+		if !withPaths {
+			for j := range profileProblems {
+				profileProblems[j].Path = ""
+			}
+		}
+		problems = append(problems, profileProblems...)
+	}
+	return lineOf(problems)
+}
+
+// fieldError is implemented by the strict decoding errors of sigs.k8s.io/json
+type fieldError interface {
+	FieldPath() string
+}
+
+// strictPolicyProblems reports unknown and duplicate fields of the policy and of the args of its plugins.
+// When any is found in a v1alpha2 policy, the policy is returned without them so the decoder,
+// rejecting unknown fields of the args, does not stop the validation.
+func strictPolicyProblems(jsonData []byte, registry pluginregistry.Registry) ([]PolicyProblem, []byte) {
+	typeMeta := &metav1.TypeMeta{}
+	if err := sigsjson.UnmarshalCaseSensitivePreserveInts(jsonData, typeMeta); err != nil {
+		return []PolicyProblem{{Message: fmt.Sprintf("unable to parse the policy: %v", err)}}, nil
+	}
+
+	// other versions are reported by the decoder
+	switch typeMeta.APIVersion {
+	case v1alpha1.SchemeGroupVersion.String():
+		return strictProblems("", jsonData, &v1alpha1.DeschedulerPolicy{}, "", ""), nil
+	case v1alpha2.SchemeGroupVersion.String():
+	default:
+		return nil, nil
+	}
+
+	policy := &v1alpha2.DeschedulerPolicy{}
+	problems := strictProblems("", jsonData, policy, "", "")
+	for i, profile := range policy.Profiles {
+		for j, pluginConfig := range profile.PluginConfigs {
+			pluginUtilities, ok := registry[pluginConfig.Name]
+			if !ok || pluginConfig.Args.Raw == nil {
+				continue
+			}
+			path := fmt.Sprintf("profiles[%d].pluginConfig[%d].args", i, j)
+			args := pluginUtilities.PluginArgInstance.DeepCopyObject()
+			argsProblems := strictProblems(path, pluginConfig.Args.Raw, args, profile.Name, pluginConfig.Name)
+			if len(argsProblems) == 0 {
+				continue
+			}
+			problems = append(problems, argsProblems...)
+			if raw, err := json.Marshal(args); err == nil {
+				policy.Profiles[i].PluginConfigs[j].Args.Raw = raw
+			}
+		}
+	}
+	if len(problems) == 0 {
+		return nil, nil
+	}
+	knownFields, err := json.Marshal(policy)
+	if err != nil {
+		return problems, nil
+	}
+	return problems, knownFields
+}
+
+func strictProblems(path string, data []byte, into interface{}, profile, plugin string) []PolicyProblem {
+	strictErrs, err := sigsjson.UnmarshalStrict(data, into)
+	if err != nil {
+		return []PolicyProblem{{Profile: profile, Plugin: plugin, Path: path, Message: err.Error()}}
+	}
+	var problems []PolicyProblem
+	for _, strictErr := range strictErrs {
+		problem := PolicyProblem{Profile: profile, Plugin: plugin, Path: path, Message: strictErr.Error()}
+		if fieldErr, ok := strictErr.(fieldError); ok {
+			problem.Path = joinPath(path, fieldErr.FieldPath())
+			// the path is already part of the problem
+			problem.Message = strings.TrimSuffix(strictErr.Error(), " "+strconv.Quote(fieldErr.FieldPath()))
+		}
+		problems = append(problems, problem)
+	}
+	return problems
+}
+
+func joinPath(prefix, path string) string {
+	if prefix == "" || path == "" || strings.HasPrefix(path, "[") {
+		return prefix + path
+	}
+	return prefix + "." + path
+}
+
+// profileConfigProblems checks every plugin of the profile is registered, configured once,
+// valid and enabled only at the extension points it implements
+func profileConfigProblems(index int, profile api.DeschedulerProfile, registry pluginregistry.Registry) []PolicyProblem {
+	var problems []PolicyProblem
+	profilePath := fmt.Sprintf("profiles[%d]", index)
+
+	configured := map[string]bool{}
+	for j, pluginConfig := range profile.PluginConfigs {
+		path := fmt.Sprintf("%s.pluginConfig[%d]", profilePath, j)
+		if configured[pluginConfig.Name] {
+			problems = append(problems, PolicyProblem{Profile: profile.Name, Plugin: pluginConfig.Name, Path: path + ".name", Message: "plugin configured more than once, only the first pluginConfig is used"})
+			continue
+		}
+		configured[pluginConfig.Name] = true
+
+		pluginUtilities, ok := registry[pluginConfig.Name]
+		if !ok {
+			problems = append(problems, PolicyProblem{Profile: profile.Name, Plugin: pluginConfig.Name, Path: path + ".name", Message: "plugin not registered"})
+			continue
+		}
+		if pluginUtilities.PluginArgValidator == nil {
+			continue
+		}
+		if err := pluginUtilities.PluginArgValidator(pluginConfig.Args); err != nil {
+			problems = append(problems, PolicyProblem{Profile: profile.Name, Plugin: pluginConfig.Name, Path: path + ".args", Message: err.Error()})
+		}
+	}
+
+	extensionPoints := []struct {
+		name, field string
+		enabled     []string
+		implements  func(pluginType interface{}) bool
+	}{
+		{"preSort", "presort", profile.Plugins.PreSort.Enabled, func(t interface{}) bool { _, ok := t.(frameworktypes.PreSortPlugin); return ok }},
+		{"sort", "sort", profile.Plugins.Sort.Enabled, func(t interface{}) bool { _, ok := t.(frameworktypes.SortPlugin); return ok }},
+		{"deschedule", "deschedule", profile.Plugins.Deschedule.Enabled, func(t interface{}) bool { _, ok := t.(frameworktypes.DeschedulePlugin); return ok }},
+		{"balance", "balance", profile.Plugins.Balance.Enabled, func(t interface{}) bool { _, ok := t.(frameworktypes.BalancePlugin); return ok }},
+		{"filter", "filter", profile.Plugins.Filter.Enabled, func(t interface{}) bool { _, ok := t.(frameworktypes.EvictorPlugin); return ok }},
+		{"preEvictionFilter", "preevictionfilter", profile.Plugins.PreEvictionFilter.Enabled, func(t interface{}) bool { _, ok := t.(frameworktypes.EvictorPlugin); return ok }},
+	}
+	reported := map[string]bool{}
+	for _, extensionPoint := range extensionPoints {
+		for k, pluginName := range extensionPoint.enabled {
+			path := fmt.Sprintf("%s.plugins.%s.enabled[%d]", profilePath, extensionPoint.field, k)
+			pluginUtilities, ok := registry[pluginName]
+			switch {
+			case !ok:
+				problems = append(problems, PolicyProblem{Profile: profile.Name, Plugin: pluginName, Path: path, Message: "plugin not registered"})
+			case !extensionPoint.implements(pluginUtilities.PluginType):
+				problems = append(problems, PolicyProblem{Profile: profile.Name, Plugin: pluginName, Path: path, Message: fmt.Sprintf("plugin does not implement the %s extension point", extensionPoint.name)})
+			// the DefaultEvictor gets a default configuration when the policy is loaded
+			case !configured[pluginName] && pluginName != defaultevictor.PluginName && !reported[pluginName]:
+				reported[pluginName] = true
+				problems = append(problems, PolicyProblem{Profile: profile.Name, Plugin: pluginName, Path: path, Message: "plugin enabled without a pluginConfig"})
+			}
+		}
+	}
+	return problems
+}
+
+// buildProfileProblems builds the plugins of the profile the same way the descheduler does
+func buildProfileProblems(index int, profile api.DeschedulerProfile, client *fakeclientset.Clientset, registry pluginregistry.Registry) []PolicyProblem {
+	policy := setDefaults(api.DeschedulerPolicy{Profiles: []api.DeschedulerProfile{*profile.DeepCopy()}}, registry, client)
+
+	sharedInformerFactory := informers.NewSharedInformerFactory(client, 0)
+	getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(sharedInformerFactory.Core().V1().Pods().Informer())
+	if err != nil {
+		return []PolicyProblem{{Profile: profile.Name, Message: fmt.Sprintf("build get pods assigned to node function error: %v", err)}}
+	}
+	podEvictor := evictions.NewPodEvictor(client, &events.FakeRecorder{}, nil)
+
+	if _, err := frameworkprofile.NewProfile(
+		policy.Profiles[0],
+		registry,
+		frameworkprofile.WithClientSet(client),
+		frameworkprofile.WithSharedInformerFactory(sharedInformerFactory),
+		frameworkprofile.WithPodEvictor(podEvictor),
+		frameworkprofile.WithGetPodsAssignedToNodeFnc(getPodsAssignedToNode),
+	); err != nil {
+		klog.V(3).InfoS("Unable to create a profile", "profile", profile.Name, "err", err)
+		return []PolicyProblem{{Profile: profile.Name, Path: fmt.Sprintf("profiles[%d]", index), Message: err.Error()}}
+	}
+	return nil
+}
+
+var pathSegment = regexp.MustCompile(`[^.\[\]]+|\[\d+\]`)
+
+// yamlPathLine returns the line of the deepest node of the path present in the document
+func yamlPathLine(root *yaml.Node, path string) int {
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	node, line := root, root.Line
+	for _, segment := range pathSegment.FindAllString(path, -1) {
+		var next, key *yaml.Node
+		if strings.HasPrefix(segment, "[") {
+			index, _ := strconv.Atoi(strings.Trim(segment, "[]"))
+			if node.Kind == yaml.SequenceNode && index < len(node.Content) {
+				next = node.Content[index]
+			}
+		} else if node.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == segment {
+					key, next = node.Content[i], node.Content[i+1]
+				}
+			}
+		}
+		if next == nil {
+			break
+		}
+		node, line = next, next.Line
+		if key != nil {
+			line = key.Line
+		}
+	}
+	return line
+}
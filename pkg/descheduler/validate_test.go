@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package descheduler
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
+)
+
+func TestValidatePolicy(t *testing.T) {
+	SetupPlugins()
+
+	tests := []struct {
+		description string
+		policy      string
+		expected    []PolicyProblem
+	}{
+		{
+			description: "valid policy",
+			policy: `apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+profiles:
+  - name: ProfileName
+    pluginConfig:
+    - name: "DefaultEvictor"
+      args:
+        evictLocalStoragePods: true
+    - name: "RemovePodsViolatingNodeTaints"
+    - name: "LowNodeUtilization"
+      args:
+        thresholds:
+          cpu: 20
+        targetThresholds:
+          cpu: 50
+    plugins:
+      deschedule:
+        enabled:
+          - "RemovePodsViolatingNodeTaints"
+      balance:
+        enabled:
+          - "LowNodeUtilization"
+`,
+		},
+		{
+			description: "unknown fields",
+			policy: `apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+maxNoOfPodsToEvictPerNod: 3
+profiles:
+  - name: ProfileName
+    pluginConfig:
+    - name: "LowNodeUtilization"
+      args:
+        thresholds:
+          cpu: 20
+        targetThresholds:
+          cpu: 50
+        tresholds:
+          cpu: 10
+    plugins:
+      balance:
+        enabled:
+          - "LowNodeUtilization"
+`,
+			expected: []PolicyProblem{
+				{Path: "maxNoOfPodsToEvictPerNod", Line: 3, Message: "unknown field"},
+				{Profile: "ProfileName", Plugin: "LowNodeUtilization", Path: "profiles[0].pluginConfig[0].args.tresholds", Line: 13, Message: "unknown field"},
+			},
+		},
+		{
+			description: "invalid args, missing pluginConfig and wrong extension point",
+			policy: `apiVersion: "descheduler/v1alpha2"
+kind: "DeschedulerPolicy"
+profiles:
+  - name: ProfileName
+    pluginConfig:
+    - name: "LowNodeUtilization"
+      args:
+        thresholds:
+          cpu: 60
+        targetThresholds:
+          cpu: 50
+    - name: "LowNodeUtilization"
+    - name: "UnknownPlugin"
+    plugins:
+      balance:
+        enabled:
+          - "LowNodeUtilization"
+          - "HighNodeUtilization"
+          - "RemovePodsViolatingNodeTaints"
+      deschedule:
+        enabled:
+          - "UnknownPlugin"
+      preevictionfilter:
+        enabled:
+          - "LowNodeUtilization"
+`,
+			expected: []PolicyProblem{
+				{Profile: "ProfileName", Plugin: "LowNodeUtilization", Path: "profiles[0].pluginConfig[0].args", Line: 7, Message: "thresholds' cpu percentage is greater than targetThresholds'"},
+				{Profile: "ProfileName", Plugin: "LowNodeUtilization", Path: "profiles[0].pluginConfig[1].name", Line: 12, Message: "plugin configured more than once, only the first pluginConfig is used"},
+				{Profile: "ProfileName", Plugin: "UnknownPlugin", Path: "profiles[0].pluginConfig[2].name", Line: 13, Message: "plugin not registered"},
+				{Profile: "ProfileName", Plugin: "UnknownPlugin", Path: "profiles[0].plugins.deschedule.enabled[0]", Line: 22, Message: "plugin not registered"},
+				{Profile: "ProfileName", Plugin: "HighNodeUtilization", Path: "profiles[0].plugins.balance.enabled[1]", Line: 18, Message: "plugin enabled without a pluginConfig"},
+				{Profile: "ProfileName", Plugin: "RemovePodsViolatingNodeTaints", Path: "profiles[0].plugins.balance.enabled[2]", Line: 19, Message: "plugin does not implement the balance extension point"},
+				{Profile: "ProfileName", Plugin: "LowNodeUtilization", Path: "profiles[0].plugins.preevictionfilter.enabled[0]", Line: 25, Message: "plugin does not implement the preEvictionFilter extension point"},
+			},
+		},
+		{
+			description: "v1alpha1 unknown field",
+			policy: `apiVersion: "descheduler/v1alpha1"
+kind: "DeschedulerPolicy"
+strategies:
+  "RemovePodsViolatingNodeTaints":
+    enabled: true
+    parms:
+      excludedTaints: []
+`,
+			expected: []PolicyProblem{
+				{Path: "strategies.RemovePodsViolatingNodeTaints.parms", Line: 6, Message: "unknown field"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			problems := validatePolicy("policy.yaml", []byte(tc.policy), pluginregistry.PluginRegistry)
+			if diff := cmp.Diff(tc.expected, problems); diff != "" {
+				t.Errorf("Unexpected problems (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
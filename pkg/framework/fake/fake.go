@@ -6,6 +6,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
 	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
@@ -14,10 +15,15 @@ import (
 
 type HandleImpl struct {
 	ClientsetImpl                 clientset.Interface
+	MetricsClientsetImpl          metricsclient.Interface
 	GetPodsAssignedToNodeFuncImpl podutil.GetPodsAssignedToNodeFunc
 	SharedInformerFactoryImpl     informers.SharedInformerFactory
 	EvictorFilterImpl             frameworktypes.EvictorPlugin
 	PodEvictorImpl                *evictions.PodEvictor
+	EvictionPlanImpl              *evictions.EvictionPlan
+	SorterImpl                    frameworktypes.Sorter
+	PluginStateImpl               *frameworktypes.PluginState
+	ContextImpl                   context.Context
 }
 
 var _ frameworktypes.Handle = &HandleImpl{}
@@ -26,6 +32,10 @@ func (hi *HandleImpl) ClientSet() clientset.Interface {
 	return hi.ClientsetImpl
 }
 
+func (hi *HandleImpl) MetricsClientSet() metricsclient.Interface {
+	return hi.MetricsClientsetImpl
+}
+
 func (hi *HandleImpl) GetPodsAssignedToNodeFunc() podutil.GetPodsAssignedToNodeFunc {
 	return hi.GetPodsAssignedToNodeFuncImpl
 }
@@ -47,5 +57,46 @@ func (hi *HandleImpl) PreEvictionFilter(pod *v1.Pod) bool {
 }
 
 func (hi *HandleImpl) Evict(ctx context.Context, pod *v1.Pod, opts evictions.EvictOptions) error {
+	if hi.EvictionPlanImpl != nil {
+		return hi.EvictionPlanImpl.Propose(pod, opts)
+	}
 	return hi.PodEvictorImpl.EvictPod(ctx, pod, opts)
 }
+
+func (hi *HandleImpl) CanEvict(pods []*v1.Pod) error {
+	if hi.EvictionPlanImpl != nil {
+		return hi.EvictionPlanImpl.CanPropose(pods)
+	}
+	return hi.PodEvictorImpl.CanEvict(pods)
+}
+
+func (hi *HandleImpl) Planning() bool {
+	return hi.EvictionPlanImpl != nil
+}
+
+func (hi *HandleImpl) Sorter() frameworktypes.Sorter {
+	return hi
+}
+
+func (hi *HandleImpl) PreSort(pods []*v1.Pod) {
+	if hi.SorterImpl != nil {
+		hi.SorterImpl.PreSort(pods)
+	}
+}
+
+func (hi *HandleImpl) Sort(pods []*v1.Pod) {
+	if hi.SorterImpl != nil {
+		hi.SorterImpl.Sort(pods)
+	}
+}
+
+func (hi *HandleImpl) PluginState() *frameworktypes.PluginState {
+	return hi.PluginStateImpl
+}
+
+func (hi *HandleImpl) Context() context.Context {
+	if hi.ContextImpl == nil {
+		return context.Background()
+	}
+	return hi.ContextImpl
+}
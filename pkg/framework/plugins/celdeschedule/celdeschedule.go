@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celdeschedule
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	celutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/cel"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+)
+
+const PluginName = "CELDeschedule"
+
+var _ frameworktypes.DeschedulePlugin = &CELDeschedule{}
+
+// CELDeschedule evicts the pods for which a CEL expression over the pod, the node
+// it runs on and its owner reference evaluates to true
+type CELDeschedule struct {
+	handle      frameworktypes.Handle
+	args        *CELDescheduleArgs
+	podFilter   podutil.FilterFunc
+	expression  celutil.Expression
+	activations *celutil.ActivationCache
+}
+
+// New builds plugin from its arguments while passing a handle
+func New(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	celDescheduleArgs, ok := args.(*CELDescheduleArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type CELDescheduleArgs, got %T", args)
+	}
+
+	env, err := celutil.NewEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the CEL environment: %v", err)
+	}
+	expressions, err := celutil.Compile(env, []string{celDescheduleArgs.Expression})
+	if err != nil {
+		return nil, err
+	}
+
+	var includedNamespaces, excludedNamespaces sets.Set[string]
+	if celDescheduleArgs.Namespaces != nil {
+		includedNamespaces = sets.New(celDescheduleArgs.Namespaces.Include...)
+		excludedNamespaces = sets.New(celDescheduleArgs.Namespaces.Exclude...)
+	}
+
+	// We can combine Filter and PreEvictionFilter since for this strategy it does not matter where we run PreEvictionFilter
+	podFilter, err := podutil.NewOptions().
+		WithFilter(podutil.WrapFilterFuncs(handle.Evictor().Filter, handle.Evictor().PreEvictionFilter)).
+		WithNamespaces(includedNamespaces).
+		WithoutNamespaces(excludedNamespaces).
+		WithLabelSelector(celDescheduleArgs.LabelSelector).
+		BuildFilterFunc()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+	}
+
+	return &CELDeschedule{
+		handle:      handle,
+		args:        celDescheduleArgs,
+		podFilter:   podFilter,
+		expression:  expressions[0],
+		activations: celutil.NewActivationCache(),
+	}, nil
+}
+
+// Name retrieves the plugin name
+func (d *CELDeschedule) Name() string {
+	return PluginName
+}
+
+// Deschedule extension point implementation for the plugin
+func (d *CELDeschedule) Deschedule(ctx context.Context, nodes []*v1.Node) *frameworktypes.Status {
+	podsToEvict := make([]*v1.Pod, 0)
+	now := time.Now()
+
+	for _, node := range nodes {
+		klog.V(2).InfoS("Processing node", "node", klog.KObj(node))
+		pods, err := podutil.ListAllPodsOnANode(node.Name, d.handle.GetPodsAssignedToNodeFunc(), d.podFilter)
+		if err != nil {
+			// no pods evicted as error encountered retrieving evictable Pods
+			return &frameworktypes.Status{
+				Err: fmt.Errorf("error listing pods on a node: %v", err),
+			}
+		}
+
+		for _, pod := range pods {
+			if d.matches(ctx, pod, node, now) {
+				podsToEvict = append(podsToEvict, pod)
+			}
+		}
+	}
+
+	d.handle.Sorter().Sort(podsToEvict)
+
+loop:
+	for _, pod := range podsToEvict {
+		err := d.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName, Score: evictions.MaxScore})
+		if err == nil {
+			continue
+		}
+		switch err.(type) {
+		case *evictions.EvictionNodeLimitError:
+			continue loop
+		case *evictions.EvictionTotalLimitError:
+			return nil
+		default:
+			klog.Errorf("eviction failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// matches evaluates the expression over the pod, a pod the expression fails to evaluate for is not evicted
+func (d *CELDeschedule) matches(ctx context.Context, pod *v1.Pod, node *v1.Node, now time.Time) bool {
+	activation, err := d.activations.NewActivation(pod, node, now)
+	if err != nil {
+		klog.ErrorS(err, "Unable to evaluate the expression for the pod", "pod", klog.KObj(pod))
+		return false
+	}
+	matches, err := d.expression.Eval(ctx, activation)
+	if err != nil {
+		klog.V(4).InfoS("Expression failed to evaluate for the pod", "pod", klog.KObj(pod), "expression", d.expression.Source, "err", err)
+		return false
+	}
+	return matches
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celdeschedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworkfake "github.com/amit3512/descheduler_policy_master/pkg/framework/fake"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestCELDeschedule(t *testing.T) {
+	spotNode := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+		node.Labels = map[string]string{"pool": "spot"}
+		node.Status.Conditions = []v1.NodeCondition{
+			{Type: v1.NodeReady, Status: v1.ConditionTrue},
+			{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue},
+		}
+	})
+	onDemandNode := test.BuildTestNode("n2", 2000, 3000, 10, func(node *v1.Node) {
+		node.Labels = map[string]string{"pool": "on-demand"}
+	})
+
+	started := func(age time.Duration, namespace string, labels map[string]string) func(*v1.Pod) {
+		return func(pod *v1.Pod) {
+			test.SetRSOwnerRef(pod)
+			pod.Namespace = namespace
+			pod.Labels = labels
+			startTime := metav1.NewTime(time.Now().Add(-age))
+			pod.Status.StartTime = &startTime
+		}
+	}
+
+	pods := []*v1.Pod{
+		test.BuildTestPod("p1", 100, 0, "n1", started(7*time.Hour, "dev", map[string]string{"app": "web"})),
+		test.BuildTestPod("p2", 100, 0, "n1", started(time.Hour, "dev", map[string]string{"app": "web"})),
+		test.BuildTestPod("p3", 100, 0, "n2", started(7*time.Hour, "dev", map[string]string{"app": "web"})),
+		test.BuildTestPod("p4", 100, 0, "n1", started(7*time.Hour, "prod", map[string]string{"app": "db"})),
+		// never started, the expression fails to evaluate for the pod
+		test.BuildTestPod("p5", 100, 0, "n1", func(pod *v1.Pod) {
+			test.SetRSOwnerRef(pod)
+			pod.Namespace = "dev"
+		}),
+	}
+
+	longRunningOnSpot := "now - timestamp(pod.status.startTime) > duration('6h') && node.metadata.labels['pool'] == 'spot'"
+
+	testCases := []struct {
+		description     string
+		args            *CELDescheduleArgs
+		expectedEvicted []string
+	}{
+		{
+			description:     "pods running longer than 6h on spot nodes",
+			args:            &CELDescheduleArgs{Expression: longRunningOnSpot},
+			expectedEvicted: []string{"p1", "p4"},
+		},
+		{
+			description: "pods on nodes with a condition",
+			args: &CELDescheduleArgs{
+				Expression: "node.status.conditions.exists(c, c.type == 'MemoryPressure' && c.status == 'True')",
+			},
+			expectedEvicted: []string{"p1", "p2", "p4", "p5"},
+		},
+		{
+			description: "pods in the included namespaces only",
+			args: &CELDescheduleArgs{
+				Namespaces: &api.Namespaces{Include: []string{"prod"}},
+				Expression: longRunningOnSpot,
+			},
+			expectedEvicted: []string{"p4"},
+		},
+		{
+			description: "pods in the excluded namespaces are not evicted",
+			args: &CELDescheduleArgs{
+				Namespaces: &api.Namespaces{Exclude: []string{"prod"}},
+				Expression: longRunningOnSpot,
+			},
+			expectedEvicted: []string{"p1"},
+		},
+		{
+			description: "pods matching the label selector only",
+			args: &CELDescheduleArgs{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Expression:    longRunningOnSpot,
+			},
+			expectedEvicted: []string{"p1"},
+		},
+		{
+			description:     "expression matching no pod",
+			args:            &CELDescheduleArgs{Expression: "owner.kind == 'StatefulSet'"},
+			expectedEvicted: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			objs := []runtime.Object{spotNode, onDemandNode}
+			for _, pod := range pods {
+				objs = append(objs, pod)
+			}
+			fakeClient := fake.NewSimpleClientset(objs...)
+
+			evicted := sets.New[string]()
+			fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "eviction" {
+					eviction := action.(core.CreateAction).GetObject().(*policy.Eviction)
+					evicted.Insert(eviction.Name)
+				}
+				return false, nil, nil
+			})
+
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+			getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(podInformer)
+			if err != nil {
+				t.Fatalf("Build get pods assigned to node function error: %v", err)
+			}
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			podEvictor := evictions.NewPodEvictor(fakeClient, &events.FakeRecorder{}, nil)
+
+			defaultEvictorFilterArgs := &defaultevictor.DefaultEvictorArgs{}
+			evictorFilter, err := defaultevictor.New(
+				defaultEvictorFilterArgs,
+				&frameworkfake.HandleImpl{
+					ClientsetImpl:                 fakeClient,
+					GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+					SharedInformerFactoryImpl:     sharedInformerFactory,
+				},
+			)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			plugin, err := New(tc.args, &frameworkfake.HandleImpl{
+				ClientsetImpl:                 fakeClient,
+				PodEvictorImpl:                podEvictor,
+				EvictorFilterImpl:             evictorFilter.(frameworktypes.EvictorPlugin),
+				GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+				SharedInformerFactoryImpl:     sharedInformerFactory,
+			})
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			plugin.(frameworktypes.DeschedulePlugin).Deschedule(ctx, []*v1.Node{spotNode, onDemandNode})
+
+			if !evicted.Equal(sets.New(tc.expectedEvicted...)) {
+				t.Errorf("Expected %v pods to be evicted, got %v", sets.List(sets.New(tc.expectedEvicted...)), sets.List(evicted))
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celdeschedule
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	celutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/cel"
+)
+
+// ValidateCELDescheduleArgs validates CELDeschedule arguments, the expression is compiled, type checked and its cost estimated
+func ValidateCELDescheduleArgs(obj runtime.Object) error {
+	args := obj.(*CELDescheduleArgs)
+
+	// At most one of include/exclude can be set
+	if args.Namespaces != nil && len(args.Namespaces.Include) > 0 && len(args.Namespaces.Exclude) > 0 {
+		return fmt.Errorf("only one of Include/Exclude namespaces can be set")
+	}
+
+	if args.LabelSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(args.LabelSelector); err != nil {
+			return fmt.Errorf("failed to get label selectors from strategy's params: %+v", err)
+		}
+	}
+
+	if args.Expression == "" {
+		return fmt.Errorf("expression not set")
+	}
+
+	env, err := celutil.NewEnvironment()
+	if err != nil {
+		return fmt.Errorf("unable to create the CEL environment: %v", err)
+	}
+	if _, err := celutil.Compile(env, []string{args.Expression}); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celdeschedule
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+)
+
+func TestValidateCELDescheduleArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        *CELDescheduleArgs
+		expectError bool
+	}{
+		{
+			description: "valid args, no errors",
+			args: &CELDescheduleArgs{
+				Namespaces:    &api.Namespaces{Exclude: []string{"kube-system"}},
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				Expression:    "now - timestamp(pod.status.startTime) > duration('6h') && node.metadata.labels['pool'] == 'spot'",
+			},
+			expectError: false,
+		},
+		{
+			description: "no expression, expects error",
+			args:        &CELDescheduleArgs{},
+			expectError: true,
+		},
+		{
+			description: "included and excluded namespaces, expects error",
+			args: &CELDescheduleArgs{
+				Namespaces: &api.Namespaces{Include: []string{"dev"}, Exclude: []string{"kube-system"}},
+				Expression: "owner.kind == 'ReplicaSet'",
+			},
+			expectError: true,
+		},
+		{
+			description: "invalid label selector, expects error",
+			args: &CELDescheduleArgs{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"": "web"}},
+				Expression:    "owner.kind == 'ReplicaSet'",
+			},
+			expectError: true,
+		},
+		{
+			description: "expression comparing a timestamp with a string, expects error",
+			args: &CELDescheduleArgs{
+				Expression: "now > '2024-01-01'",
+			},
+			expectError: true,
+		},
+		{
+			description: "expression not evaluating to bool, expects error",
+			args: &CELDescheduleArgs{
+				Expression: "now - timestamp(pod.status.startTime)",
+			},
+			expectError: true,
+		},
+		{
+			description: "expression exceeding the cost limit, expects error",
+			args: &CELDescheduleArgs{
+				Expression: "pod.spec.containers.all(c, pod.spec.containers.all(d, pod.spec.containers.all(e, c.name != e.name)))",
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateCELDescheduleArgs(tc.args)
+			hasError := err != nil
+			if tc.expectError != hasError {
+				t.Errorf("Unexpected validation result, expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celevictor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	celutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/cel"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+)
+
+const PluginName = "CELEvictor"
+
+var _ frameworktypes.ExplainableEvictorPlugin = &CELEvictor{}
+
+// CELEvictor is an EvictorPlugin filtering the pods with CEL expressions evaluated over the pod,
+// the node it runs on and its owner reference. A pod is evictable when all the expressions
+// of an extension point evaluate to true.
+type CELEvictor struct {
+	args                         *CELEvictorArgs
+	nodeLister                   listersv1.NodeLister
+	filterExpressions            []celutil.Expression
+	preEvictionFilterExpressions []celutil.Expression
+	activations                  *celutil.ActivationCache
+}
+
+// New builds plugin from its arguments while passing a handle
+func New(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	celEvictorArgs, ok := args.(*CELEvictorArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type CELEvictorArgs, got %T", args)
+	}
+
+	env, err := celutil.NewEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the CEL environment: %v", err)
+	}
+	filterExpressions, err := celutil.Compile(env, celEvictorArgs.FilterExpressions)
+	if err != nil {
+		return nil, err
+	}
+	preEvictionFilterExpressions, err := celutil.Compile(env, celEvictorArgs.PreEvictionFilterExpressions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CELEvictor{
+		args:                         celEvictorArgs,
+		nodeLister:                   handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
+		filterExpressions:            filterExpressions,
+		preEvictionFilterExpressions: preEvictionFilterExpressions,
+		activations:                  celutil.NewActivationCache(),
+	}, nil
+}
+
+// Name retrieves the plugin name
+func (c *CELEvictor) Name() string {
+	return PluginName
+}
+
+func (c *CELEvictor) Filter(pod *v1.Pod) bool {
+	return c.passes(pod, c.FilterChecks(pod))
+}
+
+func (c *CELEvictor) PreEvictionFilter(pod *v1.Pod) bool {
+	return c.passes(pod, c.PreEvictionFilterChecks(pod))
+}
+
+// FilterChecks returns the outcome of every filter expression
+func (c *CELEvictor) FilterChecks(pod *v1.Pod) []frameworktypes.Check {
+	return c.evaluate(pod, c.filterExpressions)
+}
+
+// PreEvictionFilterChecks returns the outcome of every pre-eviction filter expression
+func (c *CELEvictor) PreEvictionFilterChecks(pod *v1.Pod) []frameworktypes.Check {
+	return c.evaluate(pod, c.preEvictionFilterExpressions)
+}
+
+func (c *CELEvictor) passes(pod *v1.Pod, checks []frameworktypes.Check) bool {
+	checkErrs := []error{}
+	for _, check := range checks {
+		if check.Err != nil {
+			checkErrs = append(checkErrs, check.Err)
+		}
+	}
+
+	if len(checkErrs) > 0 {
+		klog.V(4).InfoS("Pod fails the following expressions", "pod", klog.KObj(pod), "checks", utilerrors.NewAggregate(checkErrs).Error())
+		return false
+	}
+
+	return true
+}
+
+// evaluate evaluates the expressions over the pod, an expression failing to evaluate
+// keeps the pod from being evicted
+func (c *CELEvictor) evaluate(pod *v1.Pod, expressions []celutil.Expression) []frameworktypes.Check {
+	if len(expressions) == 0 {
+		return nil
+	}
+
+	checks := make([]frameworktypes.Check, 0, len(expressions))
+	activation, err := c.activations.NewActivation(pod, c.node(pod), time.Now())
+	if err != nil {
+		for _, e := range expressions {
+			checks = append(checks, frameworktypes.Check{Name: e.Source, Err: err})
+		}
+		return checks
+	}
+
+	for _, e := range expressions {
+		check := frameworktypes.Check{Name: e.Source}
+		// the filters are not given the context of the descheduling cycle, the evaluation is bounded by its cost and timeout
+		matches, err := e.Eval(context.Background(), activation)
+		switch {
+		case err != nil:
+			check.Err = fmt.Errorf("expression %q failed to evaluate: %v", e.Source, err)
+		case !matches:
+			check.Err = fmt.Errorf("expression %q evaluated to false", e.Source)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// node returns the node the pod is assigned to, nil when the pod is not assigned or the node is not known
+func (c *CELEvictor) node(pod *v1.Pod) *v1.Node {
+	if pod.Spec.NodeName == "" {
+		return nil
+	}
+	node, err := c.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Unable to get the node of the pod", "pod", klog.KObj(pod), "node", pod.Spec.NodeName)
+		}
+		return nil
+	}
+	return node
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celevictor
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	frameworkfake "github.com/amit3512/descheduler_policy_master/pkg/framework/fake"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestCELEvictor(t *testing.T) {
+	spotNode := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+		node.Labels = map[string]string{"pool": "spot-a"}
+	})
+	onDemandNode := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+
+	bestEffort := func(tier string) func(*v1.Pod) {
+		return func(pod *v1.Pod) {
+			test.SetRSOwnerRef(pod)
+			pod.Labels = map[string]string{"tier": tier}
+			pod.Status.QOSClass = v1.PodQOSBestEffort
+		}
+	}
+
+	testCases := []struct {
+		description                  string
+		filterExpressions            []string
+		preEvictionFilterExpressions []string
+		pod                          *v1.Pod
+		expectedFilter               bool
+		expectedPreEvictionFilter    bool
+	}{
+		{
+			description:               "pod matching the filter expression",
+			filterExpressions:         []string{"pod.metadata.labels['tier'] != 'db' && pod.status.qosClass == 'BestEffort'"},
+			pod:                       test.BuildTestPod("p1", 100, 0, "n1", bestEffort("web")),
+			expectedFilter:            true,
+			expectedPreEvictionFilter: true,
+		},
+		{
+			description:               "pod not matching the filter expression",
+			filterExpressions:         []string{"pod.metadata.labels['tier'] != 'db' && pod.status.qosClass == 'BestEffort'"},
+			pod:                       test.BuildTestPod("p1", 100, 0, "n1", bestEffort("db")),
+			expectedFilter:            false,
+			expectedPreEvictionFilter: true,
+		},
+		{
+			description:               "pod has to match all the filter expressions",
+			filterExpressions:         []string{"pod.status.qosClass == 'BestEffort'", "pod.metadata.namespace == 'kube-system'"},
+			pod:                       test.BuildTestPod("p1", 100, 0, "n1", bestEffort("web")),
+			expectedFilter:            false,
+			expectedPreEvictionFilter: true,
+		},
+		{
+			description:                  "pre-eviction filter expression over the node of the pod",
+			preEvictionFilterExpressions: []string{"node.metadata.labels['pool'].startsWith('spot')"},
+			pod:                          test.BuildTestPod("p1", 100, 0, "n1", bestEffort("web")),
+			expectedFilter:               true,
+			expectedPreEvictionFilter:    true,
+		},
+		{
+			description:                  "missing label fails the evaluation of the expression",
+			preEvictionFilterExpressions: []string{"node.metadata.labels['pool'].startsWith('spot')"},
+			pod:                          test.BuildTestPod("p1", 100, 0, "n2", bestEffort("web")),
+			expectedFilter:               true,
+			expectedPreEvictionFilter:    false,
+		},
+		{
+			description:                  "presence of a label checked before it is read",
+			preEvictionFilterExpressions: []string{"!('pool' in node.metadata.labels) || node.metadata.labels['pool'].startsWith('spot')"},
+			pod:                          test.BuildTestPod("p1", 100, 0, "n2", bestEffort("web")),
+			expectedFilter:               true,
+			expectedPreEvictionFilter:    true,
+		},
+		{
+			description:       "filter expression over the owner of the pod",
+			filterExpressions: []string{"owner.kind == 'ReplicaSet'"},
+			pod:               test.BuildTestPod("p1", 100, 0, "n1", bestEffort("web")),
+			expectedFilter:    true,
+			// no pre-eviction filter expression, the pod passes
+			expectedPreEvictionFilter: true,
+		},
+		{
+			description:               "pod without an owner",
+			filterExpressions:         []string{"has(owner.kind) && owner.kind == 'ReplicaSet'"},
+			pod:                       test.BuildTestPod("p1", 100, 0, "n1", nil),
+			expectedFilter:            false,
+			expectedPreEvictionFilter: true,
+		},
+		{
+			description:               "pod without labels has an empty label map",
+			filterExpressions:         []string{"size(pod.metadata.labels) == 0"},
+			pod:                       test.BuildTestPod("p1", 100, 0, "n1", nil),
+			expectedFilter:            true,
+			expectedPreEvictionFilter: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeClient := fake.NewSimpleClientset([]runtime.Object{spotNode, onDemandNode, tc.pod}...)
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			sharedInformerFactory.Core().V1().Nodes().Informer()
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			plugin, err := New(&CELEvictorArgs{
+				FilterExpressions:            tc.filterExpressions,
+				PreEvictionFilterExpressions: tc.preEvictionFilterExpressions,
+			}, &frameworkfake.HandleImpl{
+				ClientsetImpl:             fakeClient,
+				SharedInformerFactoryImpl: sharedInformerFactory,
+			})
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+			evictor := plugin.(frameworktypes.EvictorPlugin)
+
+			if got := evictor.Filter(tc.pod); got != tc.expectedFilter {
+				t.Errorf("Expected Filter to return %v, got %v", tc.expectedFilter, got)
+			}
+			if got := evictor.PreEvictionFilter(tc.pod); got != tc.expectedPreEvictionFilter {
+				t.Errorf("Expected PreEvictionFilter to return %v, got %v", tc.expectedPreEvictionFilter, got)
+			}
+		})
+	}
+}
+
+func TestCELEvictorInvalidExpression(t *testing.T) {
+	fakeClient := fake.NewSimpleClientset()
+	_, err := New(&CELEvictorArgs{
+		FilterExpressions: []string{"pod.metadata.name"},
+	}, &frameworkfake.HandleImpl{
+		ClientsetImpl:             fakeClient,
+		SharedInformerFactoryImpl: informers.NewSharedInformerFactory(fakeClient, 0),
+	})
+	if err == nil {
+		t.Errorf("Expected an error for an expression not evaluating to bool")
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celevictor
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CELEvictorArgs holds arguments used to configure CELEvictor plugin.
+type CELEvictorArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// FilterExpressions are CEL expressions over the pod, its node and its owner
+	// a pod has to satisfy all of to be evictable
+	FilterExpressions []string `json:"filterExpressions,omitempty"`
+	// PreEvictionFilterExpressions are CEL expressions a pod has to satisfy all of
+	// right before it is evicted
+	PreEvictionFilterExpressions []string `json:"preEvictionFilterExpressions,omitempty"`
+}
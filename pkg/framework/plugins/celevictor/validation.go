@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celevictor
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	celutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/cel"
+)
+
+// ValidateCELEvictorArgs compiles, type checks and estimates the cost of the expressions of CELEvictor arguments
+func ValidateCELEvictorArgs(obj runtime.Object) error {
+	args := obj.(*CELEvictorArgs)
+
+	if len(args.FilterExpressions) == 0 && len(args.PreEvictionFilterExpressions) == 0 {
+		return fmt.Errorf("at least one of filterExpressions and preEvictionFilterExpressions has to be set")
+	}
+
+	env, err := celutil.NewEnvironment()
+	if err != nil {
+		return fmt.Errorf("unable to create the CEL environment: %v", err)
+	}
+
+	var errs []error
+	for _, expression := range args.FilterExpressions {
+		if _, err := celutil.Compile(env, []string{expression}); err != nil {
+			errs = append(errs, fmt.Errorf("filterExpressions: %v", err))
+		}
+	}
+	for _, expression := range args.PreEvictionFilterExpressions {
+		if _, err := celutil.Compile(env, []string{expression}); err != nil {
+			errs = append(errs, fmt.Errorf("preEvictionFilterExpressions: %v", err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celevictor
+
+import (
+	"testing"
+)
+
+func TestValidateCELEvictorArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        *CELEvictorArgs
+		expectError bool
+	}{
+		{
+			description: "valid args, no errors",
+			args: &CELEvictorArgs{
+				FilterExpressions:            []string{"pod.metadata.labels['tier'] != 'db' && pod.status.qosClass == 'BestEffort'"},
+				PreEvictionFilterExpressions: []string{"owner.kind == 'ReplicaSet'", "node.metadata.labels['pool'].startsWith('spot')"},
+			},
+			expectError: false,
+		},
+		{
+			description: "no expression, expects error",
+			args:        &CELEvictorArgs{},
+			expectError: true,
+		},
+		{
+			description: "expression with a syntax error, expects error",
+			args: &CELEvictorArgs{
+				FilterExpressions: []string{"pod.metadata.labels['tier'] !="},
+			},
+			expectError: true,
+		},
+		{
+			description: "expression with an undeclared variable, expects error",
+			args: &CELEvictorArgs{
+				FilterExpressions: []string{"container.name == 'app'"},
+			},
+			expectError: true,
+		},
+		{
+			description: "expression not evaluating to bool, expects error",
+			args: &CELEvictorArgs{
+				PreEvictionFilterExpressions: []string{"size(pod.metadata.name)"},
+			},
+			expectError: true,
+		},
+		{
+			description: "expression exceeding the cost limit, expects error",
+			args: &CELEvictorArgs{
+				FilterExpressions: []string{"pod.spec.containers.all(c, pod.spec.containers.all(d, pod.spec.containers.all(e, c.name != e.name)))"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateCELEvictorArgs(tc.args)
+			hasError := err != nil
+			if tc.expectError != hasError {
+				t.Errorf("Unexpected validation result, expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -1,3 +1,19 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
 package custompolicy
 
 import (
@@ -8,15 +24,24 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
+
 	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
 	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
 	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/nodeutilization"
 	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/pkg/utils"
 )
 
 const CustomPolicyOnePluginName = "CustomPolicyOne"
 
+// CustomPolicyOne evicts pods from overutilized nodes, the nodes with the highest cpu utilization
+// first, as long as an underutilized node has the resources to take them. The pods with the
+// smallest cpu requests are evicted first, and the underutilized nodes related to a pod by
+// the configured labels are preferred to take it. Note that CPU/Memory requests are used
+// to calculate nodes' utilization and not the actual resource usage.
 type CustomPolicyOne struct {
 	handle    frameworktypes.Handle
 	args      *CustomPolicyOneArgs
@@ -27,13 +52,19 @@ var _ frameworktypes.BalancePlugin = &CustomPolicyOne{}
 
 // NewCustomPolicyOne builds plugin from its arguments while passing a handle
 func NewCustomPolicyOne(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
-	CustomPolicyOneArgsArgs, ok := args.(*CustomPolicyOneArgs)
+	customPolicyOneArgs, ok := args.(*CustomPolicyOneArgs)
 	if !ok {
 		return nil, fmt.Errorf("want args to be of type CustomPolicyOneArgs, got %T", args)
 	}
 
+	var excludedNamespaces sets.Set[string]
+	if customPolicyOneArgs.EvictableNamespaces != nil {
+		excludedNamespaces = sets.New(customPolicyOneArgs.EvictableNamespaces.Exclude...)
+	}
+
 	podFilter, err := podutil.NewOptions().
-		WithFilter(handle.Evictor().Filter).
+		WithFilter(podutil.WrapFilterFuncs(handle.Evictor().Filter, handle.Evictor().PreEvictionFilter)).
+		WithoutNamespaces(excludedNamespaces).
 		BuildFilterFunc()
 	if err != nil {
 		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
@@ -41,7 +72,7 @@ func NewCustomPolicyOne(args runtime.Object, handle frameworktypes.Handle) (fram
 
 	return &CustomPolicyOne{
 		handle:    handle,
-		args:      CustomPolicyOneArgsArgs,
+		args:      customPolicyOneArgs,
 		podFilter: podFilter,
 	}, nil
 }
@@ -56,13 +87,15 @@ func (l *CustomPolicyOne) Balance(ctx context.Context, nodes []*v1.Node) *framew
 	useDeviationThresholds := l.args.UseDeviationThresholds
 	thresholds := l.args.Thresholds
 	targetThresholds := l.args.TargetThresholds
+
+	nodeutilization.SetDefaultForLNUThresholds(thresholds, targetThresholds, useDeviationThresholds)
 	resourceNames := getResourceNames(thresholds)
 
-	// Correct function call with proper arguments
-	nodeUsageFunc := l.handle.GetPodsAssignedToNodeFunc()
+	getPodsAssignedToNode := l.handle.GetPodsAssignedToNodeFunc()
 	lowNodes, sourceNodes := classifyNodes(
-		getNodeUsage(nodes, resourceNames, nodeUsageFunc),
-		getNodeThresholds(nodes, thresholds, targetThresholds, resourceNames, nodeUsageFunc, useDeviationThresholds),
+		getNodeUsage(nodes, resourceNames, getPodsAssignedToNode),
+		getNodeThresholds(nodes, thresholds, targetThresholds, resourceNames, getPodsAssignedToNode, useDeviationThresholds),
+		// The node has to be schedulable (to be able to move workload there)
 		func(node *v1.Node, usage NodeUsage, threshold NodeThresholds) bool {
 			if nodeutil.IsNodeUnschedulable(node) {
 				klog.V(2).InfoS("Node is unschedulable, thus not considered as underutilized", "node", klog.KObj(node))
@@ -75,124 +108,240 @@ func (l *CustomPolicyOne) Balance(ctx context.Context, nodes []*v1.Node) *framew
 		},
 	)
 
-	// Calculate CPU utilization for each node in sourceNodes
-	nodeCPUUtilization := make(map[*v1.Node]float64)
-	for _, node := range sourceNodes {
-		pods, _ := nodeUsageFunc(node.spec.nodeName, l.podFilter)
-		nodeCPUUtilization[node] = calculateCPUUtilization(node, pods)
+	klog.V(1).InfoS("Number of underutilized nodes", "totalNumber", len(lowNodes))
+	klog.V(1).InfoS("Number of overutilized nodes", "totalNumber", len(sourceNodes))
+
+	if len(lowNodes) == 0 {
+		klog.V(1).InfoS("No node is underutilized, nothing to do here, you might tune your thresholds further")
+		return nil
+	}
+
+	if len(lowNodes) <= l.args.NumberOfNodes {
+		klog.V(1).InfoS("Number of nodes underutilized is less or equal than NumberOfNodes, nothing to do here", "underutilizedNodes", len(lowNodes), "numberOfNodes", l.args.NumberOfNodes)
+		return nil
+	}
+
+	if len(lowNodes) == len(nodes) {
+		klog.V(1).InfoS("All nodes are underutilized, nothing to do here")
+		return nil
+	}
+
+	if len(sourceNodes) == 0 {
+		klog.V(1).InfoS("All nodes are under target utilization, nothing to do here")
+		return nil
 	}
 
 	// Sort sourceNodes by CPU utilization in descending order
+	nodeCPUUtilization := make(map[string]float64, len(sourceNodes))
+	for _, sourceNode := range sourceNodes {
+		nodeCPUUtilization[sourceNode.node.Name] = calculateCPUUtilization(sourceNode.node, sourceNode.allPods)
+	}
 	sort.SliceStable(sourceNodes, func(i, j int) bool {
-		return nodeCPUUtilization[sourceNodes[i]] > nodeCPUUtilization[sourceNodes[j]]
+		return nodeCPUUtilization[sourceNodes[i].node.Name] > nodeCPUUtilization[sourceNodes[j].node.Name]
 	})
 
-	// Get the node with the highest CPU utilization
-	highestUtilizedNode := sourceNodes[0]
-	pods, _ := nodeUsageFunc(highestUtilizedNode.spec.nodeName, l.podFilter)
+	targetNodes := make([]*targetNode, 0, len(lowNodes))
+	for _, lowNode := range lowNodes {
+		targetNodes = append(targetNodes, &targetNode{
+			node:      lowNode.node,
+			available: calculateAvailableResources(lowNode),
+		})
+	}
 
-	// Find the pod with the least CPU usage on the highest utilized node
-	var podToEvict *v1.Pod
-	minCPUUsage := resource.NewQuantity(int64(1<<63-1), resource.DecimalSI) // Max int value
-	for _, pod := range pods {
-		podCPUUsage := resource.NewQuantity(0, resource.DecimalSI)
-		for _, container := range pod.Spec.Containers {
-			podCPUUsage.Add(container.Resources.Requests[v1.ResourceCPU])
+	for _, sourceNode := range sourceNodes {
+		err := l.evictPodsFromNode(ctx, sourceNode, targetNodes, resourceNames)
+		if err == nil {
+			continue
 		}
-
-		if podCPUUsage.Cmp(*minCPUUsage) < 0 {
-			minCPUUsage = podCPUUsage
-			podToEvict = pod
+		switch err.(type) {
+		case *evictions.EvictionTotalLimitError:
+			return nil
+		default:
 		}
 	}
 
-	if podToEvict == nil {
-		klog.V(1).InfoS("No pod found to evict from the most utilized node", "node", klog.KObj(highestUtilizedNode))
+	return nil
+}
+
+// evictPodsFromNode evicts the pods with the smallest cpu requests first until the node is no
+// longer overutilized. A pod is only evicted when one of the target nodes has the resources
+// to take it, the resources are then reserved on the target node.
+func (l *CustomPolicyOne) evictPodsFromNode(ctx context.Context, sourceNode NodeInfo, targetNodes []*targetNode, resourceNames []v1.ResourceName) error {
+	_, removablePods := classifyPods(sourceNode.allPods, l.podFilter)
+	if len(removablePods) == 0 {
+		klog.V(1).InfoS("No removable pods on node, try next node", "node", klog.KObj(sourceNode.node))
 		return nil
 	}
 
-	klog.V(1).InfoS("Evicting pod with the least CPU usage", "pod", klog.KObj(podToEvict), "node", klog.KObj(highestUtilizedNode))
+	sort.SliceStable(removablePods, func(i, j int) bool {
+		return podCPURequest(removablePods[i]) < podCPURequest(removablePods[j])
+	})
 
-	// Find the target node for rescheduling the pod
-	var targetNode *v1.Node
-	for _, node := range lowNodes {
-		if isRelatedToPod(podToEvict, node) {
-			targetNode = node
+	for _, pod := range removablePods {
+		if !isNodeAboveTargetUtilization(sourceNode.NodeUsage, sourceNode.thresholds.highResourceThreshold) {
 			break
 		}
-	}
 
-	if targetNode == nil {
-		// If no related node found, pick the node with the most available resources
-		targetNode = findNodeWithMostResources(lowNodes)
-	}
+		podRequests := nodeutil.NodeUtilization([]*v1.Pod{pod}, resourceNames)
+		candidates := make([]*targetNode, 0, len(targetNodes))
+		for _, target := range targetNodes {
+			if !target.fits(podRequests) {
+				continue
+			}
+			// the pod has to be schedulable to the target node, e.g. tolerate its taints
+			if err := nodeutil.NodeFit(l.handle.GetPodsAssignedToNodeFunc(), pod, target.node); err != nil {
+				klog.V(4).InfoS("Pod does not fit on the underutilized node", "pod", klog.KObj(pod), "node", klog.KObj(target.node), "reason", err)
+				continue
+			}
+			candidates = append(candidates, target)
+		}
 
-	if targetNode == nil {
-		klog.V(1).InfoS("No suitable target node found for rescheduling", "pod", klog.KObj(podToEvict))
-		return nil
+		// Find the target node for rescheduling the pod
+		var target *targetNode
+		for _, candidate := range candidates {
+			if isRelatedToPod(pod, candidate.node, l.args.RelatedLabels) {
+				target = candidate
+				break
+			}
+		}
+		if target == nil {
+			// If no related node found, pick the node with the most available resources
+			target = findNodeWithMostResources(candidates)
+		}
+		if target == nil {
+			klog.V(3).InfoS("Skipping eviction for pod, no underutilized node has the resources to take it", "pod", klog.KObj(pod))
+			continue
+		}
+
+		opts := evictions.EvictOptions{StrategyName: CustomPolicyOnePluginName, Score: nodeutilization.OverutilizationScore(sourceNode.node, sourceNode.usage, sourceNode.thresholds.highResourceThreshold), TargetNode: target.node.Name}
+		err := l.handle.Evictor().Evict(ctx, pod, opts)
+		if err == nil {
+			klog.V(3).InfoS("Evicted pod, expecting it to be rescheduled to the target node", "pod", klog.KObj(pod), "targetNode", klog.KObj(target.node))
+			for name, quantity := range podRequests {
+				if usage, ok := sourceNode.usage[name]; ok {
+					usage.Sub(*quantity)
+				}
+			}
+			target.reserve(podRequests)
+			continue
+		}
+		switch err.(type) {
+		case *evictions.EvictionNodeLimitError:
+			return nil
+		case *evictions.EvictionTotalLimitError:
+			return err
+		default:
+			klog.Errorf("eviction failed: %v", err)
+		}
 	}
+	return nil
+}
 
-	klog.V(1).InfoS("Rescheduling pod to target node", "pod", klog.KObj(podToEvict), "targetNode", klog.KObj(targetNode))
+// targetNode is an underutilized node the evicted pods are expected to be rescheduled to
+type targetNode struct {
+	node      *v1.Node
+	available map[v1.ResourceName]*resource.Quantity
+}
 
-	// Evict the pod and reschedule it to the target node
-	err := l.handle.Evictor().Evict(ctx, podToEvict, evictions.EvictOptions{StrategyName: CustomPolicyOnePluginName})
-	if err != nil {
-		klog.V(1).InfoS("Failed to evict pod", "pod", klog.KObj(podToEvict), "error", err)
-		return frameworktypes.NewStatus(frameworktypes.Error, fmt.Sprintf("Failed to evict pod: %v", err))
+// fits checks the node has the resources available for all the requests
+func (t *targetNode) fits(requests map[v1.ResourceName]*resource.Quantity) bool {
+	for name, quantity := range requests {
+		available, ok := t.available[name]
+		if !ok || available.Cmp(*quantity) < 0 {
+			return false
+		}
 	}
+	return true
+}
 
-	return nil
+// reserve subtracts the requests from the resources available on the node
+func (t *targetNode) reserve(requests map[v1.ResourceName]*resource.Quantity) {
+	for name, quantity := range requests {
+		if available, ok := t.available[name]; ok {
+			available.Sub(*quantity)
+		}
+	}
 }
 
-// Dummy function to calculate CPU utilization of a node based on its pods
+// calculateCPUUtilization returns the percentage of the node allocatable cpu requested by the pods
 func calculateCPUUtilization(node *v1.Node, pods []*v1.Pod) float64 {
-	totalCPU := resource.NewMilliQuantity(0, resource.DecimalSI)
-	for _, pod := range pods {
-		for _, container := range pod.Spec.Containers {
-			totalCPU.Add(container.Resources.Requests[v1.ResourceCPU])
-		}
+	nodeCapacity := node.Status.Capacity
+	if len(node.Status.Allocatable) > 0 {
+		nodeCapacity = node.Status.Allocatable
+	}
+	capacity := nodeCapacity[v1.ResourceCPU]
+	if capacity.IsZero() {
+		return 0
 	}
-	return float64(totalCPU.MilliValue()) / float64(node.Status.Capacity[v1.ResourceCPU].MilliValue()) * 100
+
+	totalCPU := nodeutil.NodeUtilization(pods, []v1.ResourceName{v1.ResourceCPU})[v1.ResourceCPU]
+	return float64(totalCPU.MilliValue()) / float64(capacity.MilliValue()) * 100
 }
 
-// Dummy function to find the node with the most available resources
-func findNodeWithMostResources(nodes []*v1.Node) *v1.Node {
-	var targetNode *v1.Node
-	maxAvailableResources := resource.NewQuantity(0, resource.DecimalSI)
+// findNodeWithMostResources returns the node with the largest share of its allocatable available,
+// summed over the resources
+func findNodeWithMostResources(nodes []*targetNode) *targetNode {
+	var target *targetNode
+	maxAvailableShare := -1.0
 
 	for _, node := range nodes {
-		availableResources := calculateAvailableResources(node)
-		if availableResources.Cmp(*maxAvailableResources) > 0 {
-			maxAvailableResources = availableResources
-			targetNode = node
+		nodeCapacity := node.node.Status.Capacity
+		if len(node.node.Status.Allocatable) > 0 {
+			nodeCapacity = node.node.Status.Allocatable
+		}
+
+		availableShare := 0.0
+		for name, available := range node.available {
+			capacity := nodeCapacity[name]
+			if capacity.IsZero() {
+				continue
+			}
+			availableShare += float64(available.MilliValue()) / float64(capacity.MilliValue())
+		}
+		if availableShare > maxAvailableShare {
+			maxAvailableShare = availableShare
+			target = node
 		}
 	}
 
-	return targetNode
+	return target
 }
 
-// Dummy function to calculate available resources on a node
-func calculateAvailableResources(node *v1.Node) *resource.Quantity {
-	// Implement logic to calculate the available resources (CPU, memory, etc.) on the node
-	// This function should return a quantity representing the aggregate available resources
-	return resource.NewQuantity(1000, resource.DecimalSI) // Example value
+// calculateAvailableResources returns the resources the node can take before its usage reaches the
+// high threshold, as the resources of the underutilized nodes are reserved by the nodeutilization plugins,
+// so the evicted pods do not make the target node overutilized
+func calculateAvailableResources(nodeInfo NodeInfo) map[v1.ResourceName]*resource.Quantity {
+	available := map[v1.ResourceName]*resource.Quantity{}
+	for name, usage := range nodeInfo.usage {
+		threshold, ok := nodeInfo.thresholds.highResourceThreshold[name]
+		if !ok {
+			continue
+		}
+		quantity := threshold.DeepCopy()
+		quantity.Sub(*usage)
+		if quantity.Sign() < 0 {
+			quantity.Set(0)
+		}
+		available[name] = &quantity
+	}
+	return available
 }
 
-// Implement logic to determine if a node is related to the pod
-func isRelatedToPod(pod *v1.Pod, node *v1.Node) bool {
-	podLabels := pod.Labels
-	nodeLabels := node.Labels
-	relatedLabels := []string{"app", "service", "database"}
-
+// isRelatedToPod checks the pod and the node have the same value for one of the related labels
+func isRelatedToPod(pod *v1.Pod, node *v1.Node, relatedLabels []string) bool {
 	for _, key := range relatedLabels {
-		if podValue, podHasLabel := podLabels[key]; podHasLabel {
-			if nodeValue, nodeHasLabel := nodeLabels[key]; nodeHasLabel {
-				if podValue == nodeValue {
-					return true
-				}
+		if podValue, podHasLabel := pod.Labels[key]; podHasLabel {
+			if nodeValue, nodeHasLabel := node.Labels[key]; nodeHasLabel && podValue == nodeValue {
+				return true
 			}
 		}
 	}
 
 	return false
 }
+
+// podCPURequest returns the cpu requests of the pod in millicores
+func podCPURequest(pod *v1.Pod) int64 {
+	req, _ := utils.PodRequestsAndLimits(pod)
+	return req.Cpu().MilliValue()
+}
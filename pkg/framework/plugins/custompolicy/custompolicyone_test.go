@@ -0,0 +1,304 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custompolicy
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworkfake "github.com/amit3512/descheduler_policy_master/pkg/framework/fake"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestCustomPolicyOne(t *testing.T) {
+	n1NodeName := "n1"
+	n2NodeName := "n2"
+	n3NodeName := "n3"
+
+	testCases := []struct {
+		name                string
+		numberOfNodes       int
+		evictableNamespaces *api.Namespaces
+		nodes               []*v1.Node
+		pods                []*v1.Pod
+		expectedEvicted     []string
+	}{
+		{
+			name: "pods with the smallest cpu requests are evicted until the node is below target",
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 1000, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 800, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 600, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p5", 1600, 0, n3NodeName, test.SetRSOwnerRef),
+			},
+			expectedEvicted: []string{"p2", "p4"},
+		},
+		{
+			name: "no node above target utilization",
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 1600, 0, n1NodeName, test.SetRSOwnerRef),
+			},
+		},
+		{
+			name: "no node underutilized",
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 2800, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 1600, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+		},
+		{
+			name:          "number of underutilized nodes not above numberOfNodes",
+			numberOfNodes: 1,
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 1000, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 1800, 0, n1NodeName, test.SetRSOwnerRef),
+			},
+		},
+		{
+			name: "pods not fitting on the underutilized nodes are not evicted",
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 1000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 900, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 1000, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 1000, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 200, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+		},
+		{
+			name: "pods not tolerating the taints of the underutilized nodes are not evicted",
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, func(node *v1.Node) {
+					node.Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "infra", Effect: v1.TaintEffectNoSchedule}}
+				}),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 1000, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 800, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 600, 0, n1NodeName, test.SetRSOwnerRef),
+			},
+		},
+		{
+			name: "resources taken by the evicted pods are reserved on the underutilized nodes",
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 2000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 1000, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 800, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 600, 0, n1NodeName, test.SetRSOwnerRef),
+				// n2 has only 1000m left for n3 once p2 and p4 are evicted
+				test.BuildTestPod("p5", 1200, 0, n3NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p6", 1200, 0, n3NodeName, test.SetRSOwnerRef),
+			},
+			expectedEvicted: []string{"p2", "p4"},
+		},
+		{
+			name:                "pods in excluded namespaces are not evicted",
+			evictableNamespaces: &api.Namespaces{Exclude: []string{"kube-system"}},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 1000, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 400, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Namespace = "kube-system"
+				}),
+				test.BuildTestPod("p3", 800, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 600, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p5", 1600, 0, n3NodeName, test.SetRSOwnerRef),
+			},
+			expectedEvicted: []string{"p4", "p3"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var objs []runtime.Object
+			for _, node := range tc.nodes {
+				objs = append(objs, node)
+			}
+			for _, pod := range tc.pods {
+				objs = append(objs, pod)
+			}
+			fakeClient := fake.NewSimpleClientset(objs...)
+
+			evicted := sets.New[string]()
+			fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "eviction" {
+					eviction := action.(core.CreateAction).GetObject().(*policy.Eviction)
+					evicted.Insert(eviction.Name)
+					return true, nil, nil
+				}
+				return false, nil, nil
+			})
+
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+
+			getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(podInformer)
+			if err != nil {
+				t.Errorf("Build get pods assigned to node function error: %v", err)
+			}
+
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			podEvictor := evictions.NewPodEvictor(fakeClient, &events.FakeRecorder{}, nil)
+
+			evictorFilter, err := defaultevictor.New(
+				&defaultevictor.DefaultEvictorArgs{},
+				&frameworkfake.HandleImpl{
+					ClientsetImpl:                 fakeClient,
+					GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+					SharedInformerFactoryImpl:     sharedInformerFactory,
+				},
+			)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			handle := &frameworkfake.HandleImpl{
+				ClientsetImpl:                 fakeClient,
+				GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+				PodEvictorImpl:                podEvictor,
+				EvictorFilterImpl:             evictorFilter.(frameworktypes.EvictorPlugin),
+				SharedInformerFactoryImpl:     sharedInformerFactory,
+			}
+
+			plugin, err := NewCustomPolicyOne(&CustomPolicyOneArgs{
+				Thresholds:          api.ResourceThresholds{v1.ResourceCPU: 30},
+				TargetThresholds:    api.ResourceThresholds{v1.ResourceCPU: 50},
+				NumberOfNodes:       tc.numberOfNodes,
+				EvictableNamespaces: tc.evictableNamespaces,
+				RelatedLabels:       []string{"app", "service", "database"},
+			},
+				handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+			if status := plugin.(frameworktypes.BalancePlugin).Balance(ctx, tc.nodes); status != nil {
+				t.Fatalf("Unexpected status: %v", status.Err)
+			}
+
+			if !evicted.Equal(sets.New(tc.expectedEvicted...)) {
+				t.Errorf("Expected %v pods to be evicted, got %v", sets.List(sets.New(tc.expectedEvicted...)), sets.List(evicted))
+			}
+		})
+	}
+}
+
+func TestTargetNodes(t *testing.T) {
+	related := test.BuildTestNode("related", 2000, 3000, 10, func(node *v1.Node) {
+		node.Labels = map[string]string{"app": "web"}
+	})
+	large := test.BuildTestNode("large", 8000, 3000, 10, nil)
+	resourceNames := []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods}
+
+	// the high threshold of the nodes is at 50% of their allocatable
+	nodeInfo := func(node *v1.Node, pods ...*v1.Pod) NodeInfo {
+		highResourceThreshold := map[v1.ResourceName]*resource.Quantity{}
+		for _, name := range resourceNames {
+			highResourceThreshold[name] = resourceThreshold(node.Status.Allocatable, name, 50)
+		}
+		return NodeInfo{
+			NodeUsage:  NodeUsage{node: node, usage: nodeutil.NodeUtilization(pods, resourceNames), allPods: pods},
+			thresholds: NodeThresholds{highResourceThreshold: highResourceThreshold},
+		}
+	}
+
+	targetNodes := []*targetNode{
+		{node: related, available: calculateAvailableResources(nodeInfo(related, test.BuildTestPod("p1", 500, 0, related.Name, nil)))},
+		{node: large, available: calculateAvailableResources(nodeInfo(large))},
+	}
+	if available := targetNodes[0].available[v1.ResourceCPU].MilliValue(); available != 500 {
+		t.Errorf("Expected 500m cpu available below the high threshold of node %v, got %vm", related.Name, available)
+	}
+	overutilized := nodeInfo(related, test.BuildTestPod("p1", 1500, 0, related.Name, nil))
+	if available := calculateAvailableResources(overutilized)[v1.ResourceCPU].MilliValue(); available != 0 {
+		t.Errorf("Expected no cpu available on node %v above its high threshold, got %vm", related.Name, available)
+	}
+
+	pod := test.BuildTestPod("p2", 400, 0, "", func(pod *v1.Pod) {
+		pod.Labels = map[string]string{"app": "web"}
+	})
+	if !isRelatedToPod(pod, related, []string{"app"}) {
+		t.Errorf("Expected node %v to be related to pod %v", related.Name, pod.Name)
+	}
+	if isRelatedToPod(pod, related, []string{"service"}) {
+		t.Errorf("Expected node %v not to be related to pod %v by the service label", related.Name, pod.Name)
+	}
+	if target := findNodeWithMostResources(targetNodes); target.node.Name != large.Name {
+		t.Errorf("Expected node %v to have the most resources, got %v", large.Name, target.node.Name)
+	}
+
+	requests := nodeutil.NodeUtilization([]*v1.Pod{pod}, resourceNames)
+	if !targetNodes[0].fits(requests) {
+		t.Fatalf("Expected pod %v to fit on node %v", pod.Name, related.Name)
+	}
+	targetNodes[0].reserve(requests)
+	if targetNodes[0].fits(requests) {
+		t.Errorf("Expected pod %v not to fit on node %v once its resources are reserved", pod.Name, related.Name)
+	}
+}
@@ -37,6 +37,7 @@ func TestSetDefaults_CustomPolicyOneArgs(t *testing.T) {
 				Thresholds:             nil,
 				TargetThresholds:       nil,
 				NumberOfNodes:          0,
+				RelatedLabels:          nil,
 			},
 		},
 		{
@@ -52,6 +53,7 @@ func TestSetDefaults_CustomPolicyOneArgs(t *testing.T) {
 					v1.ResourceMemory: 80,
 				},
 				NumberOfNodes: 10,
+				RelatedLabels: []string{"app.kubernetes.io/part-of"},
 			},
 			want: &CustomPolicyOneArgs{
 				UseDeviationThresholds: true,
@@ -64,6 +66,7 @@ func TestSetDefaults_CustomPolicyOneArgs(t *testing.T) {
 					v1.ResourceMemory: 80,
 				},
 				NumberOfNodes: 10,
+				RelatedLabels: []string{"app.kubernetes.io/part-of"},
 			},
 		},
 	}
@@ -64,4 +64,9 @@ type CustomPolicyOneArgs struct {
 	// considered while considering resources used by pods
 	// but then filtered out before eviction
 	EvictableNamespaces *api.Namespaces `json:"evictableNamespaces"`
+
+	// RelatedLabels are the label keys a pod and an underutilized node are related by when
+	// both have the same value. The related nodes are preferred to take the evicted pods.
+	// No node is related to a pod unless set.
+	RelatedLabels []string `json:"relatedLabels,omitempty"`
 }
@@ -15,8 +15,10 @@ package custompolicy
 
 import (
 	"fmt"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"github.com/amit3512/descheduler_policy_master/pkg/api"
 )
 
@@ -44,6 +46,11 @@ func ValidateCustomPolicyOneArgs(obj runtime.Object) error {
 	if err != nil {
 		return err
 	}
+	for _, key := range args.RelatedLabels {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("relatedLabels: %q is not a valid label key: %v", key, strings.Join(errs, "; "))
+		}
+	}
 	return nil
 }
 
@@ -182,3 +182,60 @@ func TestValidateLowNodeUtilizationPluginConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateCustomPolicyOneArgs(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          *CustomPolicyOneArgs
+		expectedError error
+	}{
+		{
+			name: "passing valid plugin config",
+			args: &CustomPolicyOneArgs{
+				Thresholds:       api.ResourceThresholds{v1.ResourceCPU: 20},
+				TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 80},
+				RelatedLabels:    []string{"app", "app.kubernetes.io/part-of"},
+			},
+			expectedError: nil,
+		},
+		{
+			name: "passing included namespaces",
+			args: &CustomPolicyOneArgs{
+				Thresholds:          api.ResourceThresholds{v1.ResourceCPU: 20},
+				TargetThresholds:    api.ResourceThresholds{v1.ResourceCPU: 80},
+				EvictableNamespaces: &api.Namespaces{Include: []string{"default"}},
+			},
+			expectedError: fmt.Errorf("only Exclude namespaces can be set, inclusion is not supported"),
+		},
+		{
+			name: "passing invalid thresholds",
+			args: &CustomPolicyOneArgs{
+				Thresholds:       api.ResourceThresholds{v1.ResourceCPU: 90},
+				TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 80},
+			},
+			expectedError: fmt.Errorf("thresholds' cpu percentage is greater than targetThresholds'"),
+		},
+		{
+			name: "passing invalid related label",
+			args: &CustomPolicyOneArgs{
+				Thresholds:       api.ResourceThresholds{v1.ResourceCPU: 20},
+				TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 80},
+				RelatedLabels:    []string{"app name"},
+			},
+			expectedError: fmt.Errorf("relatedLabels: \"app name\" is not a valid label key: name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')"),
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateCustomPolicyOneArgs(testCase.args)
+			if err == nil || testCase.expectedError == nil {
+				if err != testCase.expectedError {
+					t.Errorf("expected validity of plugin config to be %v but got %v instead", testCase.expectedError, err)
+				}
+			} else if err.Error() != testCase.expectedError.Error() {
+				t.Errorf("expected validity of plugin config to be %v but got %v instead", testCase.expectedError, err)
+			}
+		})
+	}
+}
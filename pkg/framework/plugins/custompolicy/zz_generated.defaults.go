@@ -29,5 +29,15 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&CustomPolicyOneArgs{}, func(obj interface{}) { SetObjectDefaults_CustomPolicyOneArgs(obj.(*CustomPolicyOneArgs)) })
+	scheme.AddTypeDefaultingFunc(&HighNodeUtilizationArgs{}, func(obj interface{}) { SetObjectDefaults_HighNodeUtilizationArgs(obj.(*HighNodeUtilizationArgs)) })
 	return nil
 }
+
+func SetObjectDefaults_CustomPolicyOneArgs(in *CustomPolicyOneArgs) {
+	SetDefaults_CustomPolicyOneArgs(in)
+}
+
+func SetObjectDefaults_HighNodeUtilizationArgs(in *HighNodeUtilizationArgs) {
+	SetDefaults_HighNodeUtilizationArgs(in)
+}
@@ -37,9 +37,13 @@ const (
 	evictPodAnnotationKey = "descheduler.alpha.kubernetes.io/evict"
 )
 
-var _ frameworktypes.EvictorPlugin = &DefaultEvictor{}
+var _ frameworktypes.ExplainableEvictorPlugin = &DefaultEvictor{}
 
-type constraint func(pod *v1.Pod) error
+// constraint is a named check a pod has to pass to be evictable
+type constraint struct {
+	name  string
+	check func(pod *v1.Pod) error
+}
 
 // DefaultEvictor is the first EvictorPlugin, which defines the default extension points of the
 // pre-baked evictor that is shipped.
@@ -77,82 +81,82 @@ func New(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plug
 
 	if defaultEvictorArgs.EvictFailedBarePods {
 		klog.V(1).InfoS("Warning: EvictFailedBarePods is set to True. This could cause eviction of pods without ownerReferences.")
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "ownerRefs", check: func(pod *v1.Pod) error {
 			ownerRefList := podutil.OwnerRef(pod)
 			// Enable evictFailedBarePods to evict bare pods in failed phase
 			if len(ownerRefList) == 0 && pod.Status.Phase != v1.PodFailed {
 				return fmt.Errorf("pod does not have any ownerRefs and is not in failed phase")
 			}
 			return nil
-		})
+		}})
 	} else {
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "ownerRefs", check: func(pod *v1.Pod) error {
 			ownerRefList := podutil.OwnerRef(pod)
 			if len(ownerRefList) == 0 {
 				return fmt.Errorf("pod does not have any ownerRefs")
 			}
 			return nil
-		})
+		}})
 	}
 	if !defaultEvictorArgs.EvictSystemCriticalPods {
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "systemCriticalPriority", check: func(pod *v1.Pod) error {
 			if utils.IsCriticalPriorityPod(pod) {
 				return fmt.Errorf("pod has system critical priority")
 			}
 			return nil
-		})
+		}})
 
 		if defaultEvictorArgs.PriorityThreshold != nil && (defaultEvictorArgs.PriorityThreshold.Value != nil || len(defaultEvictorArgs.PriorityThreshold.Name) > 0) {
 			thresholdPriority, err := utils.GetPriorityValueFromPriorityThreshold(context.TODO(), handle.ClientSet(), defaultEvictorArgs.PriorityThreshold)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get priority threshold: %v", err)
 			}
-			ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+			ev.constraints = append(ev.constraints, constraint{name: "priorityThreshold", check: func(pod *v1.Pod) error {
 				if IsPodEvictableBasedOnPriority(pod, thresholdPriority) {
 					return nil
 				}
 				return fmt.Errorf("pod has higher priority than specified priority class threshold")
-			})
+			}})
 		}
 	} else {
 		klog.V(1).InfoS("Warning: EvictSystemCriticalPods is set to True. This could cause eviction of Kubernetes system pods.")
 	}
 	if !defaultEvictorArgs.EvictLocalStoragePods {
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "localStorage", check: func(pod *v1.Pod) error {
 			if utils.IsPodWithLocalStorage(pod) {
 				return fmt.Errorf("pod has local storage and descheduler is not configured with evictLocalStoragePods")
 			}
 			return nil
-		})
+		}})
 	}
 	if !defaultEvictorArgs.EvictDaemonSetPods {
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "daemonSet", check: func(pod *v1.Pod) error {
 			ownerRefList := podutil.OwnerRef(pod)
 			if utils.IsDaemonsetPod(ownerRefList) {
 				return fmt.Errorf("pod is related to daemonset and descheduler is not configured with evictDaemonSetPods")
 			}
 			return nil
-		})
+		}})
 	}
 	if defaultEvictorArgs.IgnorePvcPods {
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "pvc", check: func(pod *v1.Pod) error {
 			if utils.IsPodWithPVC(pod) {
 				return fmt.Errorf("pod has a PVC and descheduler is configured to ignore PVC pods")
 			}
 			return nil
-		})
+		}})
 	}
 	selector, err := metav1.LabelSelectorAsSelector(defaultEvictorArgs.LabelSelector)
 	if err != nil {
 		return nil, fmt.Errorf("could not get selector from label selector")
 	}
 	if defaultEvictorArgs.LabelSelector != nil && !selector.Empty() {
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "labelSelector", check: func(pod *v1.Pod) error {
 			if !selector.Matches(labels.Set(pod.Labels)) {
 				return fmt.Errorf("pod labels do not match the labelSelector filter in the policy parameter")
 			}
 			return nil
-		})
+		}})
 	}
 
 	if defaultEvictorArgs.MinReplicas > 1 {
@@ -161,7 +165,7 @@ func New(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plug
 		if err != nil {
 			return nil, err
 		}
-		ev.constraints = append(ev.constraints, func(pod *v1.Pod) error {
+		ev.constraints = append(ev.constraints, constraint{name: "minReplicas", check: func(pod *v1.Pod) error {
 			if len(pod.OwnerReferences) == 0 {
 				return nil
 			}
@@ -182,7 +186,7 @@ func New(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plug
 			}
 
 			return nil
-		})
+		}})
 	}
 
 	return ev, nil
@@ -194,52 +198,70 @@ func (d *DefaultEvictor) Name() string {
 }
 
 func (d *DefaultEvictor) PreEvictionFilter(pod *v1.Pod) bool {
-	if d.args.NodeFit {
-		nodes, err := nodeutil.ReadyNodes(context.TODO(), d.handle.ClientSet(), d.handle.SharedInformerFactory().Core().V1().Nodes().Lister(), d.args.NodeSelector)
-		if err != nil {
-			klog.ErrorS(err, "unable to list ready nodes", "pod", klog.KObj(pod))
-			return false
-		}
-		if !nodeutil.PodFitsAnyOtherNode(d.handle.GetPodsAssignedToNodeFunc(), pod, nodes) {
-			klog.InfoS("pod does not fit on any other node because of nodeSelector(s), Taint(s), or nodes marked as unschedulable", "pod", klog.KObj(pod))
+	for _, c := range d.PreEvictionFilterChecks(pod) {
+		if c.Err != nil {
+			klog.InfoS("Pod fails the pre-eviction check", "pod", klog.KObj(pod), "check", c.Name, "reason", c.Err.Error())
 			return false
 		}
-		return true
 	}
 	return true
 }
 
+// PreEvictionFilterChecks returns the outcome of the nodeFit check, if enabled
+func (d *DefaultEvictor) PreEvictionFilterChecks(pod *v1.Pod) []frameworktypes.Check {
+	if !d.args.NodeFit {
+		return nil
+	}
+	nodes, err := nodeutil.ReadyNodes(context.TODO(), d.handle.ClientSet(), d.handle.SharedInformerFactory().Core().V1().Nodes().Lister(), d.args.NodeSelector)
+	if err != nil {
+		return []frameworktypes.Check{{Name: "nodeFit", Err: fmt.Errorf("unable to list ready nodes: %v", err)}}
+	}
+	if !nodeutil.PodFitsAnyOtherNode(d.handle.GetPodsAssignedToNodeFunc(), pod, nodes) {
+		return []frameworktypes.Check{{Name: "nodeFit", Err: fmt.Errorf("pod does not fit on any other node because of nodeSelector(s), Taint(s), or nodes marked as unschedulable")}}
+	}
+	return []frameworktypes.Check{{Name: "nodeFit"}}
+}
+
 func (d *DefaultEvictor) Filter(pod *v1.Pod) bool {
 	checkErrs := []error{}
+	for _, c := range d.FilterChecks(pod) {
+		if c.Err != nil {
+			checkErrs = append(checkErrs, c.Err)
+		}
+	}
+
+	if len(checkErrs) > 0 {
+		klog.V(4).InfoS("Pod fails the following checks", "pod", klog.KObj(pod), "checks", utilerrors.NewAggregate(checkErrs).Error())
+		return false
+	}
 
+	return true
+}
+
+// FilterChecks returns the outcome of every check of the Filter extension point.
+// A pod with the evict annotation skips all of them.
+func (d *DefaultEvictor) FilterChecks(pod *v1.Pod) []frameworktypes.Check {
 	if HaveEvictAnnotation(pod) {
-		return true
+		return []frameworktypes.Check{{Name: "evictAnnotation"}}
 	}
 
+	checks := []frameworktypes.Check{{Name: "mirrorPod"}, {Name: "staticPod"}, {Name: "terminating"}}
 	if utils.IsMirrorPod(pod) {
-		checkErrs = append(checkErrs, fmt.Errorf("pod is a mirror pod"))
+		checks[0].Err = fmt.Errorf("pod is a mirror pod")
 	}
 
 	if utils.IsStaticPod(pod) {
-		checkErrs = append(checkErrs, fmt.Errorf("pod is a static pod"))
+		checks[1].Err = fmt.Errorf("pod is a static pod")
 	}
 
 	if utils.IsPodTerminating(pod) {
-		checkErrs = append(checkErrs, fmt.Errorf("pod is terminating"))
+		checks[2].Err = fmt.Errorf("pod is terminating")
 	}
 
 	for _, c := range d.constraints {
-		if err := c(pod); err != nil {
-			checkErrs = append(checkErrs, err)
-		}
+		checks = append(checks, frameworktypes.Check{Name: c.name, Err: c.check(pod)})
 	}
-
-	if len(checkErrs) > 0 {
-		klog.V(4).InfoS("Pod fails the following checks", "pod", klog.KObj(pod), "checks", utilerrors.NewAggregate(checkErrs).Error())
-		return false
-	}
-
-	return true
+	return checks
 }
 
 func getPodIndexerByOwnerRefs(indexName string, handle frameworktypes.Handle) (cache.Indexer, error) {
@@ -18,6 +18,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -722,6 +723,107 @@ func TestDefaultEvictorFilter(t *testing.T) {
 	}
 }
 
+func TestDefaultEvictorChecks(t *testing.T) {
+	n1 := test.BuildTestNode("node1", 1000, 2000, 13, nil)
+	n2 := test.BuildTestNode("node2", 1000, 2000, 13, func(node *v1.Node) {
+		node.Spec.Taints = []v1.Taint{{Key: "hardware", Value: "gpu", Effect: v1.TaintEffectNoSchedule}}
+	})
+	lowPriority := int32(800)
+	highPriority := int32(900)
+	ownerRefUUID := uuid.NewUUID()
+
+	bareLocalStoragePod := func(pod *v1.Pod) {
+		pod.Spec.Priority = &highPriority
+		pod.Spec.Volumes = []v1.Volume{{
+			Name:         "sample",
+			VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+		}}
+	}
+
+	testCases := []struct {
+		testCase
+		expectedFilterFailures            []string
+		expectedPreEvictionFilterFailures []string
+		expectedFilterChecks              int
+	}{
+		{
+			testCase: testCase{
+				description:       "bare pod with local storage above the priority threshold",
+				pods:              []*v1.Pod{test.BuildTestPod("p1", 400, 0, n1.Name, bareLocalStoragePod)},
+				priorityThreshold: &lowPriority,
+			},
+			expectedFilterFailures: []string{"ownerRefs", "priorityThreshold", "localStorage"},
+			// mirrorPod, staticPod, terminating, ownerRefs, systemCriticalPriority, priorityThreshold, localStorage, daemonSet
+			expectedFilterChecks: 8,
+		}, {
+			testCase: testCase{
+				description: "evict annotation skips the checks",
+				pods: []*v1.Pod{test.BuildTestPod("p1", 400, 0, n1.Name, func(pod *v1.Pod) {
+					bareLocalStoragePod(pod)
+					pod.Annotations = map[string]string{evictPodAnnotationKey: ""}
+				})},
+				priorityThreshold: &lowPriority,
+			},
+			expectedFilterChecks: 1,
+		}, {
+			testCase: testCase{
+				description: "owner with fewer replicas than minReplicas, no other node fits",
+				pods: []*v1.Pod{
+					test.BuildTestPod("p1", 400, 0, n1.Name, func(pod *v1.Pod) {
+						pod.ObjectMeta.OwnerReferences = test.GetNormalPodOwnerRefList()
+						pod.ObjectMeta.OwnerReferences[0].UID = ownerRefUUID
+					}),
+				},
+				nodes:       []*v1.Node{n1, n2},
+				nodeFit:     true,
+				minReplicas: 2,
+			},
+			expectedFilterFailures:            []string{"minReplicas"},
+			expectedPreEvictionFilterFailures: []string{"nodeFit"},
+			expectedFilterChecks:              8,
+		},
+	}
+
+	failures := func(checks []frameworktypes.Check) []string {
+		var names []string
+		for _, check := range checks {
+			if check.Err != nil {
+				names = append(names, check.Name)
+			}
+		}
+		return names
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			evictorPlugin, err := initializePlugin(ctx, tc.testCase)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+			explainer := evictorPlugin.(frameworktypes.ExplainableEvictorPlugin)
+
+			filterChecks := explainer.FilterChecks(tc.pods[0])
+			if len(filterChecks) != tc.expectedFilterChecks {
+				t.Errorf("Expected %d filter checks, got %v", tc.expectedFilterChecks, filterChecks)
+			}
+			if diff := cmp.Diff(tc.expectedFilterFailures, failures(filterChecks)); diff != "" {
+				t.Errorf("Unexpected failed filter checks (-want +got):\n%s", diff)
+			}
+			if filter := explainer.Filter(tc.pods[0]); filter != (len(tc.expectedFilterFailures) == 0) {
+				t.Errorf("Filter returned %t while the failed checks are %v", filter, tc.expectedFilterFailures)
+			}
+
+			preEvictionFilterChecks := explainer.PreEvictionFilterChecks(tc.pods[0])
+			if diff := cmp.Diff(tc.expectedPreEvictionFilterFailures, failures(preEvictionFilterChecks)); diff != "" {
+				t.Errorf("Unexpected failed pre-eviction filter checks (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestReinitialization(t *testing.T) {
 	n1 := test.BuildTestNode("node1", 1000, 2000, 13, nil)
 	ownerRefUUID := uuid.NewUUID()
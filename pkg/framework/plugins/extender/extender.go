@@ -0,0 +1,246 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+)
+
+const PluginName = "Extender"
+
+var (
+	_ frameworktypes.EvictorPlugin    = &Extender{}
+	_ frameworktypes.DeschedulePlugin = &Extender{}
+)
+
+// Extender delegates the filtering and the descheduling decisions to an external
+// HTTP(S) service. Every extension point is called only when its verb is set.
+type Extender struct {
+	handle     frameworktypes.Handle
+	args       *ExtenderArgs
+	client     *client
+	nodeLister listersv1.NodeLister
+	podFilter  podutil.FilterFunc
+
+	// verdicts keeps the verdict of the extender on every pod for the cycle, as the filters
+	// are called for the same pod by several plugins and the plugin lives for a cycle
+	mu       sync.Mutex
+	verdicts map[verdictKey]bool
+	// failed is set once a call to filter a pod failed, the extender is not asked again in the cycle
+	failed bool
+}
+
+// verdictKey identifies the verdict on a pod at an extension point
+type verdictKey struct {
+	verb string
+	uid  types.UID
+}
+
+// New builds plugin from its arguments while passing a handle
+func New(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	extenderArgs, ok := args.(*ExtenderArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type ExtenderArgs, got %T", args)
+	}
+
+	c, err := newClient(extenderArgs)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the extender client: %v", err)
+	}
+
+	extender := &Extender{
+		handle:     handle,
+		args:       extenderArgs,
+		client:     c,
+		nodeLister: handle.SharedInformerFactory().Core().V1().Nodes().Lister(),
+		verdicts:   make(map[verdictKey]bool),
+	}
+
+	if extenderArgs.DescheduleVerb != "" {
+		// We can combine Filter and PreEvictionFilter since for this strategy it does not matter where we run PreEvictionFilter
+		extender.podFilter, err = podutil.NewOptions().
+			WithFilter(podutil.WrapFilterFuncs(handle.Evictor().Filter, handle.Evictor().PreEvictionFilter)).
+			BuildFilterFunc()
+		if err != nil {
+			return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+		}
+	}
+
+	return extender, nil
+}
+
+// Name retrieves the plugin name
+func (e *Extender) Name() string {
+	return PluginName
+}
+
+func (e *Extender) Filter(pod *v1.Pod) bool {
+	return e.filter(e.args.FilterVerb, pod)
+}
+
+func (e *Extender) PreEvictionFilter(pod *v1.Pod) bool {
+	return e.filter(e.args.PreEvictionFilterVerb, pod)
+}
+
+// filter returns the verdict of the extender on the pod, the extender is asked once per pod and cycle
+func (e *Extender) filter(verb string, pod *v1.Pod) bool {
+	if verb == "" {
+		return true
+	}
+
+	key := verdictKey{verb: verb, uid: pod.UID}
+	e.mu.Lock()
+	evictable, ok := e.verdicts[key]
+	e.mu.Unlock()
+	if ok {
+		return evictable
+	}
+
+	evictable = e.ask(verb, pod)
+	e.mu.Lock()
+	e.verdicts[key] = evictable
+	e.mu.Unlock()
+	return evictable
+}
+
+// ask asks the extender whether the pod is evictable, the pod is not evicted
+// when the extender fails unless the extender is ignorable. Once a call failed the
+// remaining pods of the cycle get the same verdict without waiting for the extender.
+func (e *Extender) ask(verb string, pod *v1.Pod) bool {
+	e.mu.Lock()
+	failed := e.failed
+	e.mu.Unlock()
+	if failed {
+		return e.args.Ignorable
+	}
+
+	result := &ExtenderFilterResult{}
+	err := e.client.send(e.handle.Context(), verb, &ExtenderFilterArgs{Pod: pod, Node: e.node(pod)}, result)
+	if err == nil && result.Error != "" {
+		err = fmt.Errorf("%s", result.Error)
+	}
+	if err != nil {
+		klog.ErrorS(err, "Extender failed to filter the pod, not asking it again in this cycle", "pod", klog.KObj(pod), "verb", verb)
+		e.mu.Lock()
+		e.failed = true
+		e.mu.Unlock()
+		return e.args.Ignorable
+	}
+
+	if !result.Evictable {
+		klog.V(4).InfoS("Pod is not evictable according to the extender", "pod", klog.KObj(pod), "verb", verb, "reason", result.Reason)
+	}
+	return result.Evictable
+}
+
+// Deschedule extension point implementation for the plugin
+func (e *Extender) Deschedule(ctx context.Context, nodes []*v1.Node) *frameworktypes.Status {
+	if e.args.DescheduleVerb == "" {
+		return nil
+	}
+
+	candidates := make(map[types.NamespacedName]*v1.Pod)
+	pods := make([]*v1.Pod, 0)
+	for _, node := range nodes {
+		klog.V(2).InfoS("Processing node", "node", klog.KObj(node))
+		nodePods, err := podutil.ListAllPodsOnANode(node.Name, e.handle.GetPodsAssignedToNodeFunc(), e.podFilter)
+		if err != nil {
+			// no pods evicted as error encountered retrieving evictable Pods
+			return &frameworktypes.Status{
+				Err: fmt.Errorf("error listing pods on a node: %v", err),
+			}
+		}
+		for _, pod := range nodePods {
+			candidates[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = pod
+		}
+		pods = append(pods, nodePods...)
+	}
+
+	result := &ExtenderDescheduleResult{}
+	err := e.client.send(ctx, e.args.DescheduleVerb, &ExtenderDescheduleArgs{Nodes: nodes, Pods: pods}, result)
+	if err == nil && result.Error != "" {
+		err = fmt.Errorf("%s", result.Error)
+	}
+	if err != nil {
+		if e.args.Ignorable {
+			klog.ErrorS(err, "Ignorable extender failed to deschedule", "verb", e.args.DescheduleVerb)
+			return nil
+		}
+		return &frameworktypes.Status{
+			Err: fmt.Errorf("extender failed to deschedule: %v", err),
+		}
+	}
+
+loop:
+	for _, eviction := range result.Evictions {
+		// only the pods sent to the extender passed the evictor filters
+		pod, ok := candidates[types.NamespacedName{Namespace: eviction.Namespace, Name: eviction.Name}]
+		if !ok {
+			klog.V(2).InfoS("Skipping a pod the extender was not asked about", "pod", klog.KRef(eviction.Namespace, eviction.Name))
+			continue
+		}
+		err := e.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName, Reason: eviction.Reason, Score: evictionScore(eviction)})
+		if err == nil {
+			continue
+		}
+		switch err.(type) {
+		case *evictions.EvictionNodeLimitError:
+			continue loop
+		case *evictions.EvictionTotalLimitError:
+			return nil
+		default:
+			klog.Errorf("eviction failed: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// evictionScore returns the score the extender gave the eviction within [0, MaxScore],
+// evictions.MaxScore when not set
+func evictionScore(eviction ExtenderEviction) float64 {
+	if eviction.Score == nil || math.IsNaN(*eviction.Score) {
+		return evictions.MaxScore
+	}
+	return math.Min(math.Max(*eviction.Score, 0), evictions.MaxScore)
+}
+
+// node returns the node the pod is assigned to, nil when the pod is not assigned or the node is not known
+func (e *Extender) node(pod *v1.Pod) *v1.Node {
+	if pod.Spec.NodeName == "" {
+		return nil
+	}
+	node, err := e.nodeLister.Get(pod.Spec.NodeName)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Unable to get the node of the pod", "pod", klog.KObj(pod), "node", pod.Spec.NodeName)
+		}
+		return nil
+	}
+	return node
+}
@@ -0,0 +1,473 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+	utilptr "k8s.io/utils/ptr"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworkfake "github.com/amit3512/descheduler_policy_master/pkg/framework/fake"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+// newExtenderHandler returns a handler answering the filter verb with the "evictable" label
+// of the pod and the deschedule verb with the pods named by the "evict" label of the nodes
+func newExtenderHandler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/filter", func(w http.ResponseWriter, r *http.Request) {
+		args := &ExtenderFilterArgs{}
+		if err := json.NewDecoder(r.Body).Decode(args); err != nil {
+			t.Errorf("Unable to decode the filter arguments: %v", err)
+		}
+		result := &ExtenderFilterResult{Evictable: args.Pod.Labels["evictable"] == "true"}
+		if args.Node == nil || args.Node.Name != args.Pod.Spec.NodeName {
+			result = &ExtenderFilterResult{Error: "node of the pod not sent"}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("/deschedule", func(w http.ResponseWriter, r *http.Request) {
+		args := &ExtenderDescheduleArgs{}
+		if err := json.NewDecoder(r.Body).Decode(args); err != nil {
+			t.Errorf("Unable to decode the deschedule arguments: %v", err)
+		}
+		result := &ExtenderDescheduleResult{Evictions: []ExtenderEviction{}}
+		for _, node := range args.Nodes {
+			if name, ok := node.Labels["evict"]; ok {
+				result.Evictions = append(result.Evictions, ExtenderEviction{Namespace: "default", Name: name, Reason: "requested"})
+			}
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "extender failure", http.StatusInternalServerError)
+	})
+	return mux
+}
+
+type testEnv struct {
+	handle  *frameworkfake.HandleImpl
+	nodes   []*v1.Node
+	pods    map[string]*v1.Pod
+	evicted sets.Set[string]
+}
+
+func newTestEnv(ctx context.Context, t *testing.T) *testEnv {
+	n1 := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+		node.Labels = map[string]string{"evict": "p1"}
+	})
+	n2 := test.BuildTestNode("n2", 2000, 3000, 10, func(node *v1.Node) {
+		// p4 is not evictable by the default evictor so it is not sent to the extender
+		node.Labels = map[string]string{"evict": "p4"}
+	})
+
+	pods := map[string]*v1.Pod{
+		"p1": test.BuildTestPod("p1", 100, 0, "n1", func(pod *v1.Pod) {
+			test.SetRSOwnerRef(pod)
+			pod.Labels = map[string]string{"evictable": "true"}
+		}),
+		"p2": test.BuildTestPod("p2", 100, 0, "n1", test.SetRSOwnerRef),
+		"p3": test.BuildTestPod("p3", 100, 0, "n2", test.SetRSOwnerRef),
+		"p4": test.BuildTestPod("p4", 100, 0, "n2", nil),
+	}
+
+	objs := []runtime.Object{n1, n2}
+	for _, pod := range pods {
+		objs = append(objs, pod)
+	}
+	fakeClient := fake.NewSimpleClientset(objs...)
+
+	evicted := sets.New[string]()
+	fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() == "eviction" {
+			eviction := action.(core.CreateAction).GetObject().(*policy.Eviction)
+			evicted.Insert(eviction.Name)
+		}
+		return false, nil, nil
+	})
+
+	sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+	podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+	sharedInformerFactory.Core().V1().Nodes().Informer()
+	getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(podInformer)
+	if err != nil {
+		t.Fatalf("Build get pods assigned to node function error: %v", err)
+	}
+	sharedInformerFactory.Start(ctx.Done())
+	sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+	handle := &frameworkfake.HandleImpl{
+		ClientsetImpl:                 fakeClient,
+		PodEvictorImpl:                evictions.NewPodEvictor(fakeClient, &events.FakeRecorder{}, nil),
+		GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+		SharedInformerFactoryImpl:     sharedInformerFactory,
+	}
+	evictorFilter, err := defaultevictor.New(&defaultevictor.DefaultEvictorArgs{}, handle)
+	if err != nil {
+		t.Fatalf("Unable to initialize the plugin: %v", err)
+	}
+	handle.EvictorFilterImpl = evictorFilter.(frameworktypes.EvictorPlugin)
+
+	return &testEnv{
+		handle:  handle,
+		nodes:   []*v1.Node{n1, n2},
+		pods:    pods,
+		evicted: evicted,
+	}
+}
+
+func TestExtenderFilter(t *testing.T) {
+	server := httptest.NewServer(newExtenderHandler(t))
+	defer server.Close()
+
+	testCases := []struct {
+		description string
+		args        *ExtenderArgs
+		pod         string
+		expected    bool
+	}{
+		{
+			description: "pod evictable according to the extender",
+			args:        &ExtenderArgs{URLPrefix: server.URL, FilterVerb: "filter"},
+			pod:         "p1",
+			expected:    true,
+		},
+		{
+			description: "pod not evictable according to the extender",
+			args:        &ExtenderArgs{URLPrefix: server.URL, FilterVerb: "filter"},
+			pod:         "p2",
+			expected:    false,
+		},
+		{
+			description: "pod not evictable when the extender fails",
+			args:        &ExtenderArgs{URLPrefix: server.URL, FilterVerb: "fail"},
+			pod:         "p1",
+			expected:    false,
+		},
+		{
+			description: "pod evictable when an ignorable extender fails",
+			args:        &ExtenderArgs{URLPrefix: server.URL, FilterVerb: "fail", Ignorable: true},
+			pod:         "p2",
+			expected:    true,
+		},
+		{
+			description: "pod evictable when the verb is not set",
+			args:        &ExtenderArgs{URLPrefix: server.URL, DescheduleVerb: "deschedule"},
+			pod:         "p2",
+			expected:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			env := newTestEnv(ctx, t)
+			plugin, err := New(tc.args, env.handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			if got := plugin.(frameworktypes.EvictorPlugin).Filter(env.pods[tc.pod]); got != tc.expected {
+				t.Errorf("Expected Filter to return %v, got %v", tc.expected, got)
+			}
+
+			preEvictionArgs := tc.args.DeepCopy()
+			preEvictionArgs.PreEvictionFilterVerb, preEvictionArgs.FilterVerb = tc.args.FilterVerb, ""
+			plugin, err = New(preEvictionArgs, env.handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			if got := plugin.(frameworktypes.EvictorPlugin).PreEvictionFilter(env.pods[tc.pod]); got != tc.expected {
+				t.Errorf("Expected PreEvictionFilter to return %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestExtenderFilterVerdicts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls atomic.Int32
+	handler := newExtenderHandler(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	env := newTestEnv(ctx, t)
+	plugin, err := New(&ExtenderArgs{URLPrefix: server.URL, FilterVerb: "filter", PreEvictionFilterVerb: "filter"}, env.handle)
+	if err != nil {
+		t.Fatalf("Unable to initialize the plugin: %v", err)
+	}
+	extender := plugin.(frameworktypes.EvictorPlugin)
+
+	for i := 0; i < 3; i++ {
+		if !extender.Filter(env.pods["p1"]) || extender.Filter(env.pods["p2"]) {
+			t.Fatalf("Unexpected verdicts of the extender")
+		}
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Expected the extender to be asked once per pod, got %v calls", got)
+	}
+	if !extender.PreEvictionFilter(env.pods["p1"]) {
+		t.Errorf("Expected pod p1 to pass the pre-eviction filter")
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Expected the verdict of the same verb to be kept for the other extension point, got %v calls", got)
+	}
+}
+
+func TestExtenderFilterCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(newExtenderHandler(t))
+	defer server.Close()
+
+	env := newTestEnv(ctx, t)
+	cycleCtx, cancelCycle := context.WithCancel(ctx)
+	env.handle.ContextImpl = cycleCtx
+	cancelCycle()
+
+	plugin, err := New(&ExtenderArgs{URLPrefix: server.URL, FilterVerb: "filter"}, env.handle)
+	if err != nil {
+		t.Fatalf("Unable to initialize the plugin: %v", err)
+	}
+	if plugin.(frameworktypes.EvictorPlugin).Filter(env.pods["p1"]) {
+		t.Errorf("Expected the pod not to pass the filter once the descheduling cycle is cancelled")
+	}
+}
+
+func TestExtenderFilterFailFast(t *testing.T) {
+	for _, ignorable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("ignorable %v", ignorable), func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var calls atomic.Int32
+			handler := newExtenderHandler(t)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				handler.ServeHTTP(w, r)
+			}))
+			defer server.Close()
+
+			env := newTestEnv(ctx, t)
+			plugin, err := New(&ExtenderArgs{URLPrefix: server.URL, FilterVerb: "fail", Ignorable: ignorable}, env.handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			for _, name := range []string{"p1", "p2", "p3"} {
+				if got := plugin.(frameworktypes.EvictorPlugin).Filter(env.pods[name]); got != ignorable {
+					t.Errorf("Expected Filter to return %v for pod %v, got %v", ignorable, name, got)
+				}
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("Expected the extender not to be asked again after a failure, got %v calls", got)
+			}
+		})
+	}
+}
+
+func TestExtenderDeschedule(t *testing.T) {
+	server := httptest.NewServer(newExtenderHandler(t))
+	defer server.Close()
+
+	testCases := []struct {
+		description     string
+		args            *ExtenderArgs
+		expectedEvicted []string
+		expectError     bool
+	}{
+		{
+			description:     "pods returned by the extender are evicted",
+			args:            &ExtenderArgs{URLPrefix: server.URL, DescheduleVerb: "deschedule"},
+			expectedEvicted: []string{"p1"},
+		},
+		{
+			description:     "no pod evicted when the extender fails",
+			args:            &ExtenderArgs{URLPrefix: server.URL, DescheduleVerb: "fail"},
+			expectedEvicted: []string{},
+			expectError:     true,
+		},
+		{
+			description:     "no error when an ignorable extender fails",
+			args:            &ExtenderArgs{URLPrefix: server.URL, DescheduleVerb: "fail", Ignorable: true},
+			expectedEvicted: []string{},
+		},
+		{
+			description:     "no pod evicted when the verb is not set",
+			args:            &ExtenderArgs{URLPrefix: server.URL, FilterVerb: "filter"},
+			expectedEvicted: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			env := newTestEnv(ctx, t)
+			plugin, err := New(tc.args, env.handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			status := plugin.(frameworktypes.DeschedulePlugin).Deschedule(ctx, env.nodes)
+			if hasError := status != nil && status.Err != nil; hasError != tc.expectError {
+				t.Errorf("Expected error %v, got status %v", tc.expectError, status)
+			}
+
+			if !env.evicted.Equal(sets.New(tc.expectedEvicted...)) {
+				t.Errorf("Expected %v pods to be evicted, got %v", tc.expectedEvicted, sets.List(env.evicted))
+			}
+		})
+	}
+}
+
+func TestExtenderDescheduleScore(t *testing.T) {
+	testCases := []struct {
+		description   string
+		score         *float64
+		expectedScore float64
+	}{
+		{
+			description:   "eviction without a score",
+			expectedScore: evictions.MaxScore,
+		},
+		{
+			description:   "eviction scored by the extender",
+			score:         utilptr.To(0.3),
+			expectedScore: 0.3,
+		},
+		{
+			description:   "score above the maximum",
+			score:         utilptr.To(2.0),
+			expectedScore: evictions.MaxScore,
+		},
+		{
+			description:   "negative score",
+			score:         utilptr.To(-1.0),
+			expectedScore: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				json.NewEncoder(w).Encode(&ExtenderDescheduleResult{
+					Evictions: []ExtenderEviction{{Namespace: "default", Name: "p1", Score: tc.score}},
+				})
+			}))
+			defer server.Close()
+
+			env := newTestEnv(ctx, t)
+			env.handle.EvictionPlanImpl = evictions.NewEvictionPlan(nil, nil)
+			plugin, err := New(&ExtenderArgs{URLPrefix: server.URL, DescheduleVerb: "deschedule"}, env.handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			if status := plugin.(frameworktypes.DeschedulePlugin).Deschedule(ctx, env.nodes); status != nil {
+				t.Fatalf("Unexpected status: %v", status.Err)
+			}
+			candidates := env.handle.EvictionPlanImpl.Candidates()
+			if len(candidates) != 1 {
+				t.Fatalf("Expected a single pod to be proposed for eviction, got %v", len(candidates))
+			}
+			if got := candidates[0].Options.Score; got != tc.expectedScore {
+				t.Errorf("Expected score %v, got %v", tc.expectedScore, got)
+			}
+		})
+	}
+}
+
+func TestExtenderTLS(t *testing.T) {
+	server := httptest.NewTLSServer(newExtenderHandler(t))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	caData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caData, 0o600); err != nil {
+		t.Fatalf("Unable to write the CA file: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		tlsConfig   *ExtenderTLSConfig
+		expected    bool
+	}{
+		{
+			description: "certificate of the extender verified with the CA file",
+			tlsConfig:   &ExtenderTLSConfig{CAFile: caFile},
+			expected:    true,
+		},
+		{
+			description: "certificate of the extender not verified",
+			tlsConfig:   &ExtenderTLSConfig{Insecure: true},
+			expected:    true,
+		},
+		{
+			description: "certificate of the extender signed by an unknown authority",
+			expected:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			env := newTestEnv(ctx, t)
+			plugin, err := New(&ExtenderArgs{URLPrefix: server.URL, FilterVerb: "filter", TLSConfig: tc.tlsConfig}, env.handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			if got := plugin.(frameworktypes.EvictorPlugin).Filter(env.pods["p1"]); got != tc.expected {
+				t.Errorf("Expected Filter to return %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
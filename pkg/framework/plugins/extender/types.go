@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ExtenderArgs holds arguments used to configure Extender plugin.
+type ExtenderArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// URLPrefix is the URL of the extender the verbs are appended to, e.g. https://extender:8443/descheduler
+	URLPrefix string `json:"urlPrefix"`
+	// FilterVerb is called for every pod at the Filter extension point, not called when empty
+	FilterVerb string `json:"filterVerb,omitempty"`
+	// PreEvictionFilterVerb is called for every pod at the PreEvictionFilter extension point, not called when empty
+	PreEvictionFilterVerb string `json:"preEvictionFilterVerb,omitempty"`
+	// DescheduleVerb is called with the evictable pods of all nodes at the Deschedule extension point,
+	// not called when empty
+	DescheduleVerb string `json:"descheduleVerb,omitempty"`
+	// HTTPTimeout is the timeout of every call to the extender
+	HTTPTimeout *metav1.Duration `json:"httpTimeout,omitempty"`
+	// TLSConfig configures the connection to an https extender
+	TLSConfig *ExtenderTLSConfig `json:"tlsConfig,omitempty"`
+	// Ignorable lets the pods pass the filters and skips the deschedule call
+	// when the extender fails, otherwise no pod is evicted
+	Ignorable bool `json:"ignorable,omitempty"`
+}
+
+// ExtenderTLSConfig contains the settings to connect to an https extender
+type ExtenderTLSConfig struct {
+	// Insecure skips the verification of the certificate of the extender
+	Insecure bool `json:"insecure,omitempty"`
+	// ServerName is the name the certificate of the extender is verified for, the host of the URL when empty
+	ServerName string `json:"serverName,omitempty"`
+	// CertFile and KeyFile are the client certificate and key presented to the extender
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+	// CAFile holds the certificates of the authorities the certificate of the extender is verified with,
+	// the system roots when empty
+	CAFile string `json:"caFile,omitempty"`
+}
+
+// ExtenderFilterArgs is sent to the extender at the Filter and PreEvictionFilter extension points
+type ExtenderFilterArgs struct {
+	Pod *v1.Pod `json:"pod"`
+	// Node is the node the pod runs on, nil when not known
+	Node *v1.Node `json:"node,omitempty"`
+}
+
+// ExtenderFilterResult is the verdict of the extender on a pod
+type ExtenderFilterResult struct {
+	Evictable bool `json:"evictable"`
+	// Reason tells why the pod is not evictable
+	Reason string `json:"reason,omitempty"`
+	// Error is set when the extender fails to decide
+	Error string `json:"error,omitempty"`
+}
+
+// ExtenderDescheduleArgs is sent to the extender at the Deschedule extension point
+type ExtenderDescheduleArgs struct {
+	Nodes []*v1.Node `json:"nodes"`
+	// Pods are the evictable pods of the nodes
+	Pods []*v1.Pod `json:"pods"`
+}
+
+// ExtenderDescheduleResult holds the pods the extender wants evicted
+type ExtenderDescheduleResult struct {
+	Evictions []ExtenderEviction `json:"evictions"`
+	// Error is set when the extender fails to decide
+	Error string `json:"error,omitempty"`
+}
+
+// ExtenderEviction is a pod to evict, one of the pods sent to the extender
+type ExtenderEviction struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+	// Score ranks the eviction in an eviction plan, within [0, 1], 1 when not set
+	Score *float64 `json:"score,omitempty"`
+}
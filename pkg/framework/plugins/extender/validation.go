@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"fmt"
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// ValidateExtenderArgs validates the URL, the verbs, the timeout and the TLS settings of Extender arguments
+func ValidateExtenderArgs(obj runtime.Object) error {
+	args := obj.(*ExtenderArgs)
+	var errs []error
+
+	u, err := url.Parse(args.URLPrefix)
+	switch {
+	case args.URLPrefix == "":
+		errs = append(errs, fmt.Errorf("urlPrefix has to be set"))
+	case err != nil:
+		errs = append(errs, fmt.Errorf("invalid urlPrefix %q: %v", args.URLPrefix, err))
+	case u.Scheme != "http" && u.Scheme != "https":
+		errs = append(errs, fmt.Errorf("urlPrefix %q must use the http or https scheme", args.URLPrefix))
+	case u.Host == "":
+		errs = append(errs, fmt.Errorf("urlPrefix %q must have a host", args.URLPrefix))
+	}
+
+	if args.FilterVerb == "" && args.PreEvictionFilterVerb == "" && args.DescheduleVerb == "" {
+		errs = append(errs, fmt.Errorf("at least one of filterVerb, preEvictionFilterVerb and descheduleVerb has to be set"))
+	}
+
+	if args.HTTPTimeout != nil && args.HTTPTimeout.Duration <= 0 {
+		errs = append(errs, fmt.Errorf("httpTimeout must be positive, got %v", args.HTTPTimeout.Duration))
+	}
+
+	if args.TLSConfig != nil {
+		if err == nil && u.Scheme == "http" {
+			errs = append(errs, fmt.Errorf("tlsConfig can only be set with an https urlPrefix"))
+		}
+		if (args.TLSConfig.CertFile == "") != (args.TLSConfig.KeyFile == "") {
+			errs = append(errs, fmt.Errorf("certFile and keyFile of tlsConfig have to be set together"))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
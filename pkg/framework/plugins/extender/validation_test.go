@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package extender
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateExtenderArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        *ExtenderArgs
+		expectError bool
+	}{
+		{
+			description: "valid args, no errors",
+			args: &ExtenderArgs{
+				URLPrefix:      "https://extender:8443/descheduler",
+				FilterVerb:     "filter",
+				DescheduleVerb: "deschedule",
+				HTTPTimeout:    &metav1.Duration{Duration: 5 * time.Second},
+				TLSConfig:      &ExtenderTLSConfig{CertFile: "tls.crt", KeyFile: "tls.key", CAFile: "ca.crt"},
+			},
+			expectError: false,
+		},
+		{
+			description: "no urlPrefix, expects error",
+			args:        &ExtenderArgs{FilterVerb: "filter"},
+			expectError: true,
+		},
+		{
+			description: "urlPrefix with an unsupported scheme, expects error",
+			args:        &ExtenderArgs{URLPrefix: "ftp://extender/descheduler", FilterVerb: "filter"},
+			expectError: true,
+		},
+		{
+			description: "urlPrefix without a host, expects error",
+			args:        &ExtenderArgs{URLPrefix: "http:///descheduler", FilterVerb: "filter"},
+			expectError: true,
+		},
+		{
+			description: "no verb, expects error",
+			args:        &ExtenderArgs{URLPrefix: "http://extender/descheduler"},
+			expectError: true,
+		},
+		{
+			description: "negative httpTimeout, expects error",
+			args: &ExtenderArgs{
+				URLPrefix:   "http://extender/descheduler",
+				FilterVerb:  "filter",
+				HTTPTimeout: &metav1.Duration{Duration: -time.Second},
+			},
+			expectError: true,
+		},
+		{
+			description: "zero httpTimeout, expects error",
+			args: &ExtenderArgs{
+				URLPrefix:   "http://extender/descheduler",
+				FilterVerb:  "filter",
+				HTTPTimeout: &metav1.Duration{},
+			},
+			expectError: true,
+		},
+		{
+			description: "tlsConfig with an http urlPrefix, expects error",
+			args: &ExtenderArgs{
+				URLPrefix:  "http://extender/descheduler",
+				FilterVerb: "filter",
+				TLSConfig:  &ExtenderTLSConfig{Insecure: true},
+			},
+			expectError: true,
+		},
+		{
+			description: "certFile without keyFile, expects error",
+			args: &ExtenderArgs{
+				URLPrefix:  "https://extender/descheduler",
+				FilterVerb: "filter",
+				TLSConfig:  &ExtenderTLSConfig{CertFile: "tls.crt"},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateExtenderArgs(tc.args)
+			hasError := err != nil
+			if tc.expectError != hasError {
+				t.Errorf("Unexpected validation result, expected error %v, got %v", tc.expectError, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconsolidation
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+)
+
+func TestSetDefaults_NodeConsolidationArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   runtime.Object
+		want runtime.Object
+	}{
+		{
+			name: "NodeConsolidationArgs empty",
+			in:   &NodeConsolidationArgs{},
+			want: &NodeConsolidationArgs{
+				MaxNodesPerCycle: 1,
+			},
+		},
+		{
+			name: "NodeConsolidationArgs with value",
+			in: &NodeConsolidationArgs{
+				MaxNodesPerCycle: 3,
+				Taint:            &v1.Taint{Key: "consolidation", Effect: v1.TaintEffectNoSchedule},
+				Cordon:           true,
+			},
+			want: &NodeConsolidationArgs{
+				MaxNodesPerCycle: 3,
+				Taint:            &v1.Taint{Key: "consolidation", Effect: v1.TaintEffectNoSchedule},
+				Cordon:           true,
+			},
+		},
+	}
+	for _, tc := range tests {
+		scheme := runtime.NewScheme()
+		utilruntime.Must(AddToScheme(scheme))
+		t.Run(tc.name, func(t *testing.T) {
+			scheme.Default(tc.in)
+			if diff := cmp.Diff(tc.in, tc.want); diff != "" {
+				t.Errorf("Got unexpected defaults (-want, +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconsolidation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/pkg/utils"
+)
+
+const PluginName = "NodeConsolidation"
+
+// NodeConsolidation evicts all the pods of the nodes whose pods all fit on the other nodes,
+// so the emptied nodes can be removed by the cluster autoscaler. A node is drained only when
+// every pod it runs can be evicted and a simulation places all of them on the remaining nodes.
+type NodeConsolidation struct {
+	handle    frameworktypes.Handle
+	args      *NodeConsolidationArgs
+	podFilter func(pod *v1.Pod) bool
+}
+
+var _ frameworktypes.BalancePlugin = &NodeConsolidation{}
+
+// New builds plugin from its arguments while passing a handle
+func New(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	nodeConsolidationArgs, ok := args.(*NodeConsolidationArgs)
+	if !ok {
+		return nil, fmt.Errorf("want args to be of type NodeConsolidationArgs, got %T", args)
+	}
+
+	var excludedNamespaces sets.Set[string]
+	if nodeConsolidationArgs.EvictableNamespaces != nil {
+		excludedNamespaces = sets.New(nodeConsolidationArgs.EvictableNamespaces.Exclude...)
+	}
+
+	podFilter, err := podutil.NewOptions().
+		WithFilter(podutil.WrapFilterFuncs(handle.Evictor().Filter, handle.Evictor().PreEvictionFilter)).
+		WithoutNamespaces(excludedNamespaces).
+		BuildFilterFunc()
+	if err != nil {
+		return nil, fmt.Errorf("error initializing pod filter function: %v", err)
+	}
+
+	return &NodeConsolidation{
+		handle:    handle,
+		args:      nodeConsolidationArgs,
+		podFilter: podFilter,
+	}, nil
+}
+
+// Name retrieves the plugin name
+func (n *NodeConsolidation) Name() string {
+	return PluginName
+}
+
+// Balance extension point implementation for the plugin
+func (n *NodeConsolidation) Balance(ctx context.Context, nodes []*v1.Node) *frameworktypes.Status {
+	// an eviction plan ranks the pods of all plugins together and may evict only part of a node
+	if n.handle.Evictor().Planning() {
+		klog.V(1).InfoS("Nodes are not consolidated when the evictions are only planned")
+		return nil
+	}
+
+	resourceNames := []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}
+	for name := range n.args.Thresholds {
+		if name != v1.ResourceCPU && name != v1.ResourceMemory {
+			resourceNames = append(resourceNames, name)
+		}
+	}
+
+	getPodsAssignedToNode := n.handle.GetPodsAssignedToNodeFunc()
+	usages := make(map[string]api.ResourceThresholds, len(nodes))
+	for _, node := range nodes {
+		pods, err := podutil.ListPodsOnANode(node.Name, getPodsAssignedToNode, nil)
+		if err != nil {
+			return &frameworktypes.Status{
+				Err: fmt.Errorf("error listing pods on node %q: %v", node.Name, err),
+			}
+		}
+		usages[node.Name] = usagePercentages(node, pods, resourceNames)
+	}
+
+	// the least utilized nodes are the cheapest to empty, the most utilized nodes
+	// are filled first so the pods are packed on as few nodes as possible
+	sorted := append([]*v1.Node{}, nodes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return usageSum(usages[sorted[i].Name]) < usageSum(usages[sorted[j].Name])
+	})
+
+	var candidates []*v1.Node
+	for _, node := range sorted {
+		if belowThresholds(usages[node.Name], n.args.Thresholds) {
+			candidates = append(candidates, node)
+		}
+	}
+	klog.V(1).InfoS("Number of nodes considered for consolidation", "totalNumber", len(candidates))
+
+	packing := newPacking(getPodsAssignedToNode)
+	drained := sets.New[string]()
+	// the nodes receiving the pods of a drained node have to keep them
+	receivers := sets.New[string]()
+	for _, candidate := range candidates {
+		if drained.Len() >= n.args.MaxNodesPerCycle {
+			klog.V(1).InfoS("Maximum number of nodes drained in this cycle reached", "maxNodesPerCycle", n.args.MaxNodesPerCycle)
+			break
+		}
+		if receivers.Has(candidate.Name) {
+			continue
+		}
+
+		pods, err := n.podsToMove(candidate)
+		if err != nil {
+			klog.V(1).InfoS("Node can not be drained", "node", klog.KObj(candidate), "reason", err)
+			continue
+		}
+		if len(pods) == 0 {
+			klog.V(2).InfoS("Node has no pod to evict", "node", klog.KObj(candidate))
+			continue
+		}
+
+		var destinations []*v1.Node
+		for i := len(sorted) - 1; i >= 0; i-- {
+			if sorted[i].Name != candidate.Name && !drained.Has(sorted[i].Name) {
+				destinations = append(destinations, sorted[i])
+			}
+		}
+		placements, ok := packing.place(pods, destinations)
+		if !ok {
+			klog.V(1).InfoS("Pods of the node do not fit on the other nodes", "node", klog.KObj(candidate))
+			continue
+		}
+
+		// a node is only drained when all its pods can be evicted within the eviction limits
+		if err := n.handle.Evictor().CanEvict(pods); err != nil {
+			klog.V(1).InfoS("Node can not be drained within the eviction limits", "node", klog.KObj(candidate), "reason", err)
+			packing.revert(placements)
+			if _, ok := err.(*evictions.EvictionTotalLimitError); ok {
+				return nil
+			}
+			continue
+		}
+
+		changes, err := n.prepareNode(ctx, candidate)
+		if err != nil {
+			klog.ErrorS(err, "Failed to prepare the node for draining", "node", klog.KObj(candidate))
+			packing.revert(placements)
+			continue
+		}
+
+		klog.V(1).InfoS("Draining node", "node", klog.KObj(candidate), "pods", len(pods))
+		remaining, err := n.drain(ctx, pods, drainScore(usages[candidate.Name]))
+		// the evicted pods are scheduled to the nodes they were placed on in any case
+		for _, pod := range pods[:len(pods)-len(remaining)] {
+			receivers.Insert(placements[pod].Spec.NodeName)
+		}
+		if err == nil {
+			drained.Insert(candidate.Name)
+			continue
+		}
+
+		klog.ErrorS(err, "Failed to drain the node, leaving it schedulable", "node", klog.KObj(candidate), "pod", klog.KObj(remaining[0]), "remainingPods", len(remaining))
+		if err := n.restoreNode(ctx, candidate, changes); err != nil {
+			klog.ErrorS(err, "Failed to restore the node", "node", klog.KObj(candidate))
+		}
+		remainingPlacements := make(map[*v1.Pod]*v1.Pod, len(remaining))
+		for _, pod := range remaining {
+			remainingPlacements[pod] = placements[pod]
+		}
+		packing.revert(remainingPlacements)
+		if _, ok := err.(*evictions.EvictionTotalLimitError); ok {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// drain evicts the pods in order until an eviction fails, the pods left on the node are returned with the error
+func (n *NodeConsolidation) drain(ctx context.Context, pods []*v1.Pod, score float64) ([]*v1.Pod, error) {
+	for i, pod := range pods {
+		if err := n.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName, Score: score}); err != nil {
+			return pods[i:], err
+		}
+	}
+	return nil, nil
+}
+
+// podsToMove returns the pods to evict to empty the node, largest first, or an error
+// when a pod of the node can not be evicted
+func (n *NodeConsolidation) podsToMove(node *v1.Node) ([]*v1.Pod, error) {
+	pods, err := podutil.ListPodsOnANode(node.Name, n.handle.GetPodsAssignedToNodeFunc(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pods: %v", err)
+	}
+
+	var movable []*v1.Pod
+	for _, pod := range pods {
+		// the pods recreated on the node by their controller do not keep it from being removed
+		if utils.IsDaemonsetPod(podutil.OwnerRef(pod)) || utils.IsMirrorPod(pod) || utils.IsStaticPod(pod) {
+			continue
+		}
+		if !n.podFilter(pod) {
+			return nil, fmt.Errorf("pod %s/%s can not be evicted", pod.Namespace, pod.Name)
+		}
+		movable = append(movable, pod)
+	}
+
+	sizes := nodeutil.PodSizes(movable, node, []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory}, func(pod *v1.Pod) v1.ResourceList {
+		req, _ := utils.PodRequestsAndLimits(pod)
+		return req
+	})
+	sort.SliceStable(movable, func(i, j int) bool {
+		return sizes[movable[i]] > sizes[movable[j]]
+	})
+	return movable, nil
+}
+
+// nodeChanges are the changes prepareNode made to a node
+type nodeChanges struct {
+	tainted  bool
+	cordoned bool
+}
+
+// prepareNode adds the configured taint to the node and cordons it, so the evicted pods are
+// not scheduled back to it
+func (n *NodeConsolidation) prepareNode(ctx context.Context, node *v1.Node) (nodeChanges, error) {
+	updated := node.DeepCopy()
+	changes := nodeChanges{}
+	if n.args.Taint != nil && !hasTaint(updated, n.args.Taint) {
+		updated.Spec.Taints = append(updated.Spec.Taints, *n.args.Taint)
+		changes.tainted = true
+	}
+	if n.args.Cordon && !updated.Spec.Unschedulable {
+		updated.Spec.Unschedulable = true
+		changes.cordoned = true
+	}
+	if changes == (nodeChanges{}) {
+		return changes, nil
+	}
+	_, err := n.handle.ClientSet().CoreV1().Nodes().Update(ctx, updated, metav1.UpdateOptions{})
+	if err != nil {
+		return nodeChanges{}, err
+	}
+	return changes, nil
+}
+
+// restoreNode reverts the changes prepareNode made to the node, once the node is not drained after all
+func (n *NodeConsolidation) restoreNode(ctx context.Context, node *v1.Node, changes nodeChanges) error {
+	if changes == (nodeChanges{}) {
+		return nil
+	}
+	current, err := n.handle.ClientSet().CoreV1().Nodes().Get(ctx, node.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	updated := current.DeepCopy()
+	if changes.tainted {
+		taints := []v1.Taint{}
+		for _, taint := range updated.Spec.Taints {
+			if !taint.MatchTaint(n.args.Taint) {
+				taints = append(taints, taint)
+			}
+		}
+		updated.Spec.Taints = taints
+	}
+	if changes.cordoned {
+		updated.Spec.Unschedulable = false
+	}
+	_, err = n.handle.ClientSet().CoreV1().Nodes().Update(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
+
+func hasTaint(node *v1.Node, taint *v1.Taint) bool {
+	for i := range node.Spec.Taints {
+		if node.Spec.Taints[i].MatchTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// usagePercentages returns the requests of the pods as a percentage of the node allocatable
+func usagePercentages(node *v1.Node, pods []*v1.Pod, resourceNames []v1.ResourceName) api.ResourceThresholds {
+	nodeCapacity := node.Status.Capacity
+	if len(node.Status.Allocatable) > 0 {
+		nodeCapacity = node.Status.Allocatable
+	}
+
+	usage := nodeutil.NodeUtilization(pods, resourceNames)
+	percentages := api.ResourceThresholds{}
+	for _, name := range resourceNames {
+		capacity, ok := nodeCapacity[name]
+		if !ok || capacity.IsZero() {
+			continue
+		}
+		percentages[name] = api.Percentage(float64(usage[name].MilliValue()) / float64(capacity.MilliValue()) * 100)
+	}
+	return percentages
+}
+
+func usageSum(usage api.ResourceThresholds) api.Percentage {
+	var sum api.Percentage
+	for _, percentage := range usage {
+		sum += percentage
+	}
+	return sum
+}
+
+// drainScore scores the evictions draining a node by the share of the node its most requested
+// resource leaves unused, the emptiest nodes are the cheapest to remove
+func drainScore(usage api.ResourceThresholds) float64 {
+	var highest api.Percentage
+	for _, percentage := range usage {
+		if percentage > highest {
+			highest = percentage
+		}
+	}
+	return math.Max(0, 1-float64(highest)/100)
+}
+
+// belowThresholds checks the usage is below all the thresholds, it always is without thresholds
+func belowThresholds(usage, thresholds api.ResourceThresholds) bool {
+	for name, threshold := range thresholds {
+		if usage[name] >= threshold {
+			return false
+		}
+	}
+	return true
+}
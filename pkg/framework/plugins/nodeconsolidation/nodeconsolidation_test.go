@@ -0,0 +1,367 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconsolidation
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	core "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/events"
+	utilptr "k8s.io/utils/ptr"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworkfake "github.com/amit3512/descheduler_policy_master/pkg/framework/fake"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestNodeConsolidation(t *testing.T) {
+	n1 := test.BuildTestNode("n1", 2000, 3000, 10, nil)
+	n2 := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+	n3 := test.BuildTestNode("n3", 4000, 3000, 10, nil)
+
+	// n1 is 20% utilized, n2 50% and n3 60%
+	pods := []*v1.Pod{
+		test.BuildTestPod("p1", 200, 0, n1.Name, test.SetRSOwnerRef),
+		test.BuildTestPod("p2", 200, 0, n1.Name, test.SetRSOwnerRef),
+		test.BuildTestPod("p3", 500, 0, n2.Name, test.SetRSOwnerRef),
+		test.BuildTestPod("p4", 500, 0, n2.Name, test.SetRSOwnerRef),
+		test.BuildTestPod("p5", 2400, 0, n3.Name, test.SetRSOwnerRef),
+	}
+
+	testCases := []struct {
+		name                  string
+		args                  *NodeConsolidationArgs
+		maxPodsToEvictPerNode *uint
+		maxPodsToEvictTotal   *uint
+		nodes                 []*v1.Node
+		pods                  []*v1.Pod
+		expectedEvicted       []string
+		expectedDrained       []string
+	}{
+		{
+			name:            "least utilized node is drained",
+			args:            &NodeConsolidationArgs{MaxNodesPerCycle: 1},
+			nodes:           []*v1.Node{n1, n2, n3},
+			pods:            pods,
+			expectedEvicted: []string{"p1", "p2"},
+			expectedDrained: []string{"n1"},
+		},
+		{
+			name:            "nodes are drained up to the maximum per cycle",
+			args:            &NodeConsolidationArgs{MaxNodesPerCycle: 2},
+			nodes:           []*v1.Node{n1, n2, n3},
+			pods:            pods,
+			expectedEvicted: []string{"p1", "p2", "p3", "p4"},
+			expectedDrained: []string{"n1", "n2"},
+		},
+		{
+			name:  "node with a pod that can not be evicted is skipped",
+			args:  &NodeConsolidationArgs{MaxNodesPerCycle: 1},
+			nodes: []*v1.Node{n1, n2, n3},
+			pods: append([]*v1.Pod{
+				test.BuildTestPod("bare", 100, 0, n1.Name, nil),
+			}, pods...),
+			expectedEvicted: []string{"p3", "p4"},
+			expectedDrained: []string{"n2"},
+		},
+		{
+			name:  "daemonset pods do not keep a node from being drained",
+			args:  &NodeConsolidationArgs{MaxNodesPerCycle: 1},
+			nodes: []*v1.Node{n1, n2, n3},
+			pods: append([]*v1.Pod{
+				test.BuildTestPod("ds", 100, 0, n1.Name, test.SetDSOwnerRef),
+			}, pods...),
+			expectedEvicted: []string{"p1", "p2"},
+			expectedDrained: []string{"n1"},
+		},
+		{
+			name:  "node is not drained when its pods do not all fit",
+			args:  &NodeConsolidationArgs{MaxNodesPerCycle: 1},
+			nodes: []*v1.Node{n1, n2},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 700, 0, n1.Name, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 700, 0, n1.Name, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 1500, 0, n2.Name, test.SetRSOwnerRef),
+			},
+		},
+		{
+			name: "only nodes below the thresholds are drained",
+			args: &NodeConsolidationArgs{
+				MaxNodesPerCycle: 1,
+				Thresholds:       api.ResourceThresholds{v1.ResourceCPU: 15},
+			},
+			nodes: []*v1.Node{n1, n2, n3},
+			pods:  pods,
+		},
+		{
+			name: "pods in excluded namespaces keep a node from being drained",
+			args: &NodeConsolidationArgs{
+				MaxNodesPerCycle:    1,
+				EvictableNamespaces: &api.Namespaces{Exclude: []string{"kube-system"}},
+			},
+			nodes: []*v1.Node{n1, n2, n3},
+			pods: append([]*v1.Pod{
+				test.BuildTestPod("system", 100, 0, n1.Name, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Namespace = "kube-system"
+				}),
+			}, pods...),
+			expectedEvicted: []string{"p3", "p4"},
+			expectedDrained: []string{"n2"},
+		},
+		{
+			name:                  "nodes with more pods than the per node limit are not drained",
+			args:                  &NodeConsolidationArgs{MaxNodesPerCycle: 2},
+			maxPodsToEvictPerNode: utilptr.To[uint](1),
+			nodes:                 []*v1.Node{n1, n2, n3},
+			pods:                  pods,
+		},
+		{
+			name:                "no node is drained past the total limit",
+			args:                &NodeConsolidationArgs{MaxNodesPerCycle: 2},
+			maxPodsToEvictTotal: utilptr.To[uint](3),
+			nodes:               []*v1.Node{n1, n2, n3},
+			pods:                pods,
+			expectedEvicted:     []string{"p1", "p2"},
+			expectedDrained:     []string{"n1"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var objs []runtime.Object
+			for _, node := range tc.nodes {
+				objs = append(objs, node)
+			}
+			for _, pod := range tc.pods {
+				objs = append(objs, pod)
+			}
+			fakeClient := fake.NewSimpleClientset(objs...)
+
+			evicted := sets.New[string]()
+			fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "eviction" {
+					eviction := action.(core.CreateAction).GetObject().(*policy.Eviction)
+					evicted.Insert(eviction.Name)
+					return true, nil, nil
+				}
+				return false, nil, nil
+			})
+
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+
+			getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(podInformer)
+			if err != nil {
+				t.Errorf("Build get pods assigned to node function error: %v", err)
+			}
+
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			podEvictor := evictions.NewPodEvictor(
+				fakeClient,
+				&events.FakeRecorder{},
+				evictions.NewOptions().
+					WithMaxPodsToEvictPerNode(tc.maxPodsToEvictPerNode).
+					WithMaxPodsToEvictTotal(tc.maxPodsToEvictTotal),
+			)
+
+			evictorFilter, err := defaultevictor.New(
+				&defaultevictor.DefaultEvictorArgs{},
+				&frameworkfake.HandleImpl{
+					ClientsetImpl:                 fakeClient,
+					GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+					SharedInformerFactoryImpl:     sharedInformerFactory,
+				},
+			)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			handle := &frameworkfake.HandleImpl{
+				ClientsetImpl:                 fakeClient,
+				GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+				PodEvictorImpl:                podEvictor,
+				EvictorFilterImpl:             evictorFilter.(frameworktypes.EvictorPlugin),
+				SharedInformerFactoryImpl:     sharedInformerFactory,
+			}
+
+			plugin, err := New(tc.args, handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+			status := plugin.(frameworktypes.BalancePlugin).Balance(ctx, tc.nodes)
+			if status != nil {
+				t.Fatalf("Unexpected status: %v", status.Err)
+			}
+
+			if !evicted.Equal(sets.New(tc.expectedEvicted...)) {
+				t.Errorf("Expected %v pods to be evicted, got %v", sets.List(sets.New(tc.expectedEvicted...)), sets.List(evicted))
+			}
+			drained := sets.New[string]()
+			for _, node := range tc.nodes {
+				if podEvictor.NodeEvicted(node) > 0 {
+					drained.Insert(node.Name)
+				}
+			}
+			if !drained.Equal(sets.New(tc.expectedDrained...)) {
+				t.Errorf("Expected %v nodes to be drained, got %v", tc.expectedDrained, sets.List(drained))
+			}
+		})
+	}
+}
+
+func TestNodeConsolidationPreparesNodes(t *testing.T) {
+	n1 := test.BuildTestNode("n1", 2000, 3000, 10, nil)
+	n2 := test.BuildTestNode("n2", 2000, 3000, 10, nil)
+	taint := &v1.Taint{Key: "node.example.com/consolidation", Effect: v1.TaintEffectNoSchedule}
+
+	testCases := []struct {
+		name string
+		// evictionError is returned for the eviction of p2
+		evictionError    error
+		planning         bool
+		expectedPrepared bool
+		expectedEvicted  []string
+	}{
+		{
+			name:             "drained node is cordoned and tainted",
+			expectedPrepared: true,
+			expectedEvicted:  []string{"p1", "p2"},
+		},
+		{
+			name:            "node is restored when it is not drained",
+			evictionError:   apierrors.NewTooManyRequests("the disruption budget does not allow the eviction", 10),
+			expectedEvicted: []string{"p1"},
+		},
+		{
+			name:     "nodes are not consolidated when the evictions are only planned",
+			planning: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			p1 := test.BuildTestPod("p1", 300, 0, n1.Name, test.SetRSOwnerRef)
+			p2 := test.BuildTestPod("p2", 200, 0, n1.Name, test.SetRSOwnerRef)
+			p3 := test.BuildTestPod("p3", 1000, 0, n2.Name, test.SetRSOwnerRef)
+
+			fakeClient := fake.NewSimpleClientset(n1, n2, p1, p2, p3)
+			evicted := sets.New[string]()
+			fakeClient.PrependReactor("create", "pods", func(action core.Action) (bool, runtime.Object, error) {
+				if action.GetSubresource() == "eviction" {
+					eviction := action.(core.CreateAction).GetObject().(*policy.Eviction)
+					if eviction.Name == p2.Name && tc.evictionError != nil {
+						return true, nil, tc.evictionError
+					}
+					evicted.Insert(eviction.Name)
+					return true, nil, nil
+				}
+				return false, nil, nil
+			})
+
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			podInformer := sharedInformerFactory.Core().V1().Pods().Informer()
+
+			getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(podInformer)
+			if err != nil {
+				t.Errorf("Build get pods assigned to node function error: %v", err)
+			}
+
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			evictorFilter, err := defaultevictor.New(
+				&defaultevictor.DefaultEvictorArgs{},
+				&frameworkfake.HandleImpl{
+					ClientsetImpl:                 fakeClient,
+					GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+					SharedInformerFactoryImpl:     sharedInformerFactory,
+				},
+			)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			handle := &frameworkfake.HandleImpl{
+				ClientsetImpl:                 fakeClient,
+				GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
+				PodEvictorImpl:                evictions.NewPodEvictor(fakeClient, &events.FakeRecorder{}, nil),
+				EvictorFilterImpl:             evictorFilter.(frameworktypes.EvictorPlugin),
+				SharedInformerFactoryImpl:     sharedInformerFactory,
+			}
+			if tc.planning {
+				handle.EvictionPlanImpl = evictions.NewEvictionPlan(nil, nil)
+			}
+
+			plugin, err := New(&NodeConsolidationArgs{
+				MaxNodesPerCycle: 1,
+				Taint:            taint,
+				Cordon:           true,
+			}, handle)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+			if status := plugin.(frameworktypes.BalancePlugin).Balance(ctx, []*v1.Node{n1, n2}); status != nil {
+				t.Fatalf("Unexpected status: %v", status.Err)
+			}
+
+			if !evicted.Equal(sets.New(tc.expectedEvicted...)) {
+				t.Errorf("Expected %v pods to be evicted, got %v", tc.expectedEvicted, sets.List(evicted))
+			}
+			if tc.planning && len(handle.EvictionPlanImpl.Candidates()) != 0 {
+				t.Errorf("Expected no pod to be proposed for eviction, got %v", len(handle.EvictionPlanImpl.Candidates()))
+			}
+
+			drained, err := fakeClient.CoreV1().Nodes().Get(ctx, n1.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get node: %v", err)
+			}
+			if drained.Spec.Unschedulable != tc.expectedPrepared {
+				t.Errorf("Expected node %v to be cordoned: %v, got %v", drained.Name, tc.expectedPrepared, drained.Spec.Unschedulable)
+			}
+			if hasTaint(drained, taint) != tc.expectedPrepared {
+				t.Errorf("Expected node %v to be tainted with %v: %v", drained.Name, taint.ToString(), tc.expectedPrepared)
+			}
+
+			kept, err := fakeClient.CoreV1().Nodes().Get(ctx, n2.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Unable to get node: %v", err)
+			}
+			if kept.Spec.Unschedulable || len(kept.Spec.Taints) > 0 {
+				t.Errorf("Expected node %v to be left untouched", kept.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconsolidation
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+)
+
+// packing simulates the scheduling of the evicted pods, the pods placed on a node are
+// taken into account by the fit checks of the pods placed after them.
+type packing struct {
+	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc
+	placed                map[string][]*v1.Pod
+}
+
+func newPacking(getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc) *packing {
+	return &packing{
+		getPodsAssignedToNode: getPodsAssignedToNode,
+		placed:                map[string][]*v1.Pod{},
+	}
+}
+
+// podsAssignedToNode lists the pods of the node including the pods placed on it
+func (p *packing) podsAssignedToNode(nodeName string, filter podutil.FilterFunc) ([]*v1.Pod, error) {
+	pods, err := p.getPodsAssignedToNode(nodeName, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range p.placed[nodeName] {
+		if filter == nil || filter(pod) {
+			pods = append(pods, pod)
+		}
+	}
+	return pods, nil
+}
+
+// place puts every pod on the first destination it fits, the destinations are tried in order.
+// The placed copies of the pods are returned keyed by the pods, nothing is placed when
+// a pod does not fit on any destination.
+func (p *packing) place(pods []*v1.Pod, destinations []*v1.Node) (map[*v1.Pod]*v1.Pod, bool) {
+	placements := make(map[*v1.Pod]*v1.Pod, len(pods))
+	for _, pod := range pods {
+		fits := false
+		for _, node := range destinations {
+			if err := nodeutil.NodeFit(p.podsAssignedToNode, pod, node); err != nil {
+				continue
+			}
+			placed := pod.DeepCopy()
+			placed.Spec.NodeName = node.Name
+			p.placed[node.Name] = append(p.placed[node.Name], placed)
+			placements[pod] = placed
+			fits = true
+			break
+		}
+		if !fits {
+			p.revert(placements)
+			return nil, false
+		}
+	}
+	return placements, true
+}
+
+// revert removes the placements from the simulation
+func (p *packing) revert(placements map[*v1.Pod]*v1.Pod) {
+	for _, placed := range placements {
+		pods := p.placed[placed.Spec.NodeName]
+		for i := range pods {
+			if pods[i] == placed {
+				p.placed[placed.Spec.NodeName] = append(pods[:i], pods[i+1:]...)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconsolidation
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+)
+
+// +k8s:deepcopy-gen=true
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// NodeConsolidationArgs holds arguments used to configure NodeConsolidation plugin.
+type NodeConsolidationArgs struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Thresholds select the nodes considered for draining, the nodes with a usage below all
+	// the thresholds. Every node is considered without thresholds.
+	Thresholds api.ResourceThresholds `json:"thresholds,omitempty"`
+
+	// MaxNodesPerCycle is the maximum number of nodes drained per descheduling cycle
+	MaxNodesPerCycle int `json:"maxNodesPerCycle,omitempty"`
+
+	// Taint is added to a node before it is drained, so no pod is scheduled to it anymore.
+	// Its effect must be NoSchedule.
+	Taint *v1.Taint `json:"taint,omitempty"`
+
+	// Cordon marks a node unschedulable before it is drained
+	Cordon bool `json:"cordon,omitempty"`
+
+	// Naming this one differently since namespaces are still
+	// considered while considering the pods blocking a node from being drained
+	// but then filtered out before eviction
+	EvictableNamespaces *api.Namespaces `json:"evictableNamespaces,omitempty"`
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconsolidation
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// MinResourcePercentage is the minimum value of a resource's percentage
+	MinResourcePercentage = 0
+	// MaxResourcePercentage is the maximum value of a resource's percentage
+	MaxResourcePercentage = 100
+)
+
+// ValidateNodeConsolidationArgs validates NodeConsolidation arguments
+func ValidateNodeConsolidationArgs(obj runtime.Object) error {
+	args := obj.(*NodeConsolidationArgs)
+	// only exclude can be set, or not at all
+	if args.EvictableNamespaces != nil && len(args.EvictableNamespaces.Include) > 0 {
+		return fmt.Errorf("only Exclude namespaces can be set, inclusion is not supported")
+	}
+	for name, percent := range args.Thresholds {
+		if percent < MinResourcePercentage || percent > MaxResourcePercentage {
+			return fmt.Errorf("%v threshold not in [%v, %v] range", name, MinResourcePercentage, MaxResourcePercentage)
+		}
+	}
+	if args.MaxNodesPerCycle < 0 {
+		return fmt.Errorf("maxNodesPerCycle can not be negative")
+	}
+	if args.Taint != nil {
+		if args.Taint.Key == "" {
+			return fmt.Errorf("taint key can not be empty")
+		}
+		// a NoExecute taint would evict the pods without checking the eviction limits and the disruption budgets
+		if args.Taint.Effect != v1.TaintEffectNoSchedule {
+			return fmt.Errorf("taint effect must be %v", v1.TaintEffectNoSchedule)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeconsolidation
+
+import (
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+)
+
+func TestValidateNodeConsolidationArgs(t *testing.T) {
+	testCases := []struct {
+		description string
+		args        *NodeConsolidationArgs
+		expectError bool
+	}{
+		{
+			description: "valid args, no errors",
+			args: &NodeConsolidationArgs{
+				Thresholds:          api.ResourceThresholds{v1.ResourceCPU: 30, v1.ResourceMemory: 30},
+				MaxNodesPerCycle:    2,
+				Taint:               &v1.Taint{Key: "consolidation", Effect: v1.TaintEffectNoSchedule},
+				Cordon:              true,
+				EvictableNamespaces: &api.Namespaces{Exclude: []string{"kube-system"}},
+			},
+			expectError: false,
+		},
+		{
+			description: "included namespaces, expects error",
+			args: &NodeConsolidationArgs{
+				EvictableNamespaces: &api.Namespaces{Include: []string{"default"}},
+			},
+			expectError: true,
+		},
+		{
+			description: "threshold out of range, expects error",
+			args: &NodeConsolidationArgs{
+				Thresholds: api.ResourceThresholds{v1.ResourceCPU: 120},
+			},
+			expectError: true,
+		},
+		{
+			description: "negative maxNodesPerCycle, expects error",
+			args: &NodeConsolidationArgs{
+				MaxNodesPerCycle: -1,
+			},
+			expectError: true,
+		},
+		{
+			description: "taint without key, expects error",
+			args: &NodeConsolidationArgs{
+				Taint: &v1.Taint{Effect: v1.TaintEffectNoSchedule},
+			},
+			expectError: true,
+		},
+		{
+			description: "taint with PreferNoSchedule effect, expects error",
+			args: &NodeConsolidationArgs{
+				Taint: &v1.Taint{Key: "consolidation", Effect: v1.TaintEffectPreferNoSchedule},
+			},
+			expectError: true,
+		},
+		{
+			description: "taint with NoExecute effect, expects error",
+			args: &NodeConsolidationArgs{
+				Taint: &v1.Taint{Key: "consolidation", Effect: v1.TaintEffectNoExecute},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateNodeConsolidationArgs(tc.args)
+			hasError := err != nil
+			if tc.expectError != hasError {
+				t.Error("unexpected arg validation behavior")
+			}
+		})
+	}
+}
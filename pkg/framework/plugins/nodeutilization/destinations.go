@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+)
+
+// destinationNodes keeps the capacity the destination nodes have left below their high
+// thresholds. A pod is only evicted when one of the nodes can take it, and its usage is
+// reserved on that node once evicted, so the evictions never exceed what the nodes absorb.
+type destinationNodes struct {
+	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc
+	resourceNames         []v1.ResourceName
+	// the least utilized nodes first
+	nodes     []*v1.Node
+	available map[string]map[v1.ResourceName]*resource.Quantity
+}
+
+func newDestinationNodes(nodeInfos []NodeInfo, resourceNames []v1.ResourceName, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc) *destinationNodes {
+	sorted := make([]NodeInfo, len(nodeInfos))
+	copy(sorted, nodeInfos)
+	sortNodesByUsage(sorted, true)
+
+	d := &destinationNodes{
+		getPodsAssignedToNode: getPodsAssignedToNode,
+		resourceNames:         resourceNames,
+		available:             make(map[string]map[v1.ResourceName]*resource.Quantity, len(sorted)),
+	}
+	for _, nodeInfo := range sorted {
+		available := make(map[v1.ResourceName]*resource.Quantity, len(resourceNames))
+		for _, name := range resourceNames {
+			quantity := nodeInfo.thresholds.highResourceThreshold[name].DeepCopy()
+			quantity.Sub(*nodeInfo.usage[name])
+			available[name] = &quantity
+		}
+		d.nodes = append(d.nodes, nodeInfo.node)
+		d.available[nodeInfo.node.Name] = available
+	}
+	return d
+}
+
+// find returns the first node the pod fits on with its usage
+func (d *destinationNodes) find(pod *v1.Pod, podUsage map[v1.ResourceName]*resource.Quantity) (*v1.Node, error) {
+	for _, node := range d.nodes {
+		if err := d.fits(pod, podUsage, node); err != nil {
+			klog.V(4).InfoS("Pod does not fit on destination node", "pod", klog.KObj(pod), "node", klog.KObj(node), "err", err)
+			continue
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("pod does not fit on any of the %d destination nodes", len(d.nodes))
+}
+
+func (d *destinationNodes) fits(pod *v1.Pod, podUsage map[v1.ResourceName]*resource.Quantity, node *v1.Node) error {
+	for _, name := range d.resourceNames {
+		if d.available[node.Name][name].Cmp(*podQuantity(pod, podUsage, name)) < 0 {
+			return fmt.Errorf("insufficient %v left below the threshold of the node", name)
+		}
+	}
+	return nodeutil.NodeFit(d.getPodsAssignedToNode, pod, node)
+}
+
+// reserve takes the usage of the evicted pod off the capacity left on the node
+func (d *destinationNodes) reserve(pod *v1.Pod, podUsage map[v1.ResourceName]*resource.Quantity, node *v1.Node) {
+	for _, name := range d.resourceNames {
+		d.available[node.Name][name].Sub(*podQuantity(pod, podUsage, name))
+	}
+}
+
+func podQuantity(pod *v1.Pod, podUsage map[v1.ResourceName]*resource.Quantity, name v1.ResourceName) *resource.Quantity {
+	if quantity, ok := podUsage[name]; ok {
+		return quantity
+	}
+	return podRequest(pod, name)
+}
@@ -36,7 +36,8 @@ import (
 const HighNodeUtilizationPluginName = "HighNodeUtilization"
 
 // HighNodeUtilization evicts pods from under utilized nodes so that scheduler can schedule according to its plugin.
-// Note that CPU/Memory requests are used to calculate nodes' utilization and not the actual resource usage.
+// Note that CPU/Memory requests are used to calculate nodes' utilization unless the actual resource usage
+// is selected through the metricsUtilization args.
 
 type HighNodeUtilization struct {
 	handle    frameworktypes.Handle
@@ -80,19 +81,21 @@ func (h *HighNodeUtilization) Balance(ctx context.Context, nodes []*v1.Node) *fr
 	setDefaultForThresholds(thresholds, targetThresholds)
 	resourceNames := getResourceNames(targetThresholds)
 
-	sourceNodes, highNodes := classifyNodes(
-		getNodeUsage(nodes, resourceNames, h.handle.GetPodsAssignedToNodeFunc()),
-		getNodeThresholds(nodes, thresholds, targetThresholds, resourceNames, h.handle.GetPodsAssignedToNodeFunc(), false),
-		func(node *v1.Node, usage NodeUsage, threshold NodeThresholds) bool {
-			return isNodeWithLowUtilization(usage, threshold.lowResourceThreshold)
-		},
-		func(node *v1.Node, usage NodeUsage, threshold NodeThresholds) bool {
-			if nodeutil.IsNodeUnschedulable(node) {
-				klog.V(2).InfoS("Node is unschedulable", "node", klog.KObj(node))
-				return false
-			}
-			return !isNodeWithLowUtilization(usage, threshold.lowResourceThreshold)
-		})
+	nodeGroups, err := newNodeGroups(h.args.NodeGroups, thresholds, targetThresholds)
+	if err != nil {
+		return &frameworktypes.Status{
+			Err: fmt.Errorf("error parsing node groups: %v", err),
+		}
+	}
+
+	usageClient := newUsageClient(h.args.MetricsUtilization, h.args.UtilizationMode, resourceNames, h.handle)
+	if err := usageClient.sync(ctx, nodes); err != nil {
+		return &frameworktypes.Status{
+			Err: fmt.Errorf("error getting node usage: %v", err),
+		}
+	}
+
+	sourceNodes, highNodes := classifyNodesForHNU(nodes, thresholds, targetThresholds, nodeGroups, resourceNames, h.args.OvercommitRatios, usageClient, stabilizerFor(h.handle, h.Name(), h.args.Stabilization))
 
 	// log message in one line
 	keysAndValues := []interface{}{
@@ -108,6 +111,9 @@ func (h *HighNodeUtilization) Balance(ctx context.Context, nodes []*v1.Node) *fr
 
 	klog.V(1).InfoS("Criteria for a node below target utilization", keysAndValues...)
 	klog.V(1).InfoS("Number of underutilized nodes", "totalNumber", len(sourceNodes))
+	for _, group := range nodeGroups {
+		klog.V(1).InfoS("Criteria for the nodes of a node group", "nodeSelector", group.selector.String(), "thresholds", group.thresholds)
+	}
 
 	if len(sourceNodes) == 0 {
 		klog.V(1).InfoS("No node is underutilized, nothing to do here, you might tune your thresholds further")
@@ -139,6 +145,12 @@ func (h *HighNodeUtilization) Balance(ctx context.Context, nodes []*v1.Node) *fr
 
 	// Sort the nodes by the usage in ascending order
 	sortNodesByUsage(sourceNodes, true)
+	// the most expensive nodes are emptied first, the nodes of the same cost by usage
+	score := underutilizationScore
+	if h.args.NodeCost != nil {
+		sortNodesByCost(sourceNodes, h.args.NodeCost)
+		score = costScore(sourceNodes, h.args.NodeCost, underutilizationScore)
+	}
 
 	evictPodsFromSourceNodes(
 		ctx,
@@ -146,14 +158,51 @@ func (h *HighNodeUtilization) Balance(ctx context.Context, nodes []*v1.Node) *fr
 		sourceNodes,
 		highNodes,
 		h.handle.Evictor(),
+		h.handle.Sorter(),
 		evictions.EvictOptions{StrategyName: HighNodeUtilizationPluginName},
 		h.podFilter,
+		h.args.PodSelectionStrategy,
 		resourceNames,
-		continueEvictionCond)
+		continueEvictionCond,
+		score,
+		usageClient,
+		nil)
 
 	return nil
 }
 
+// classifyNodesForHNU classifies the nodes into underutilized nodes the pods are evicted from
+// and the other schedulable nodes the pods are moved to. With a stabilizer only the nodes
+// classified the same for long enough are returned.
+func classifyNodesForHNU(
+	nodes []*v1.Node,
+	thresholds, targetThresholds api.ResourceThresholds,
+	nodeGroups []nodeGroup,
+	resourceNames []v1.ResourceName,
+	overcommitRatios map[v1.ResourceName]float64,
+	usageClient usageClient,
+	stabilizer *nodeStabilizer,
+) ([]NodeInfo, []NodeInfo) {
+	nodeUsages := getNodeUsage(nodes, usageClient)
+	nodeThresholds := getNodeThresholds(nodes, thresholds, targetThresholds, nodeGroups, resourceNames, overcommitRatios, false, usageClient)
+	classThresholds := stabilizer.hysteresisThresholds(nodes, nodeThresholds, true)
+
+	sourceNodes, highNodes := classifyNodes(
+		nodeUsages,
+		nodeThresholds,
+		func(node *v1.Node, usage NodeUsage, _ NodeThresholds) bool {
+			return isNodeWithLowUtilization(usage, classThresholds[node.Name].lowResourceThreshold)
+		},
+		func(node *v1.Node, usage NodeUsage, _ NodeThresholds) bool {
+			if nodeutil.IsNodeUnschedulable(node) {
+				klog.V(2).InfoS("Node is unschedulable", "node", klog.KObj(node))
+				return false
+			}
+			return !isNodeWithLowUtilization(usage, classThresholds[node.Name].lowResourceThreshold)
+		})
+	return stabilizer.stabilize(nodeUsages, sourceNodes, highNodes)
+}
+
 func setDefaultForThresholds(thresholds, targetThresholds api.ResourceThresholds) {
 	// check if Pods/CPU/Mem are set, if not, set them to 100
 	if _, ok := thresholds[v1.ResourcePods]; !ok {
@@ -51,6 +51,7 @@ func TestHighNodeUtilization(t *testing.T) {
 	testCases := []struct {
 		name                string
 		thresholds          api.ResourceThresholds
+		nodeCost            *NodeCost
 		nodes               []*v1.Node
 		pods                []*v1.Pod
 		expectedPodsEvicted uint
@@ -436,6 +437,34 @@ func TestHighNodeUtilization(t *testing.T) {
 			},
 			expectedPodsEvicted: 0,
 		},
+		{
+			name: "most expensive underutilized node is emptied first",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeCost: &NodeCost{
+				Label:  "karpenter.sh/capacity-type",
+				Values: map[string]float64{"on-demand": 3, "spot": 1},
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 2000, 3000, 10, func(node *v1.Node) {
+					node.ObjectMeta.Labels = map[string]string{"karpenter.sh/capacity-type": "on-demand"}
+				}),
+				test.BuildTestNode(n2NodeName, 2000, 3000, 10, func(node *v1.Node) {
+					node.ObjectMeta.Labels = map[string]string{"karpenter.sh/capacity-type": "spot"}
+				}),
+				test.BuildTestNode(n3NodeName, 2000, 3000, 10, nil),
+			},
+			// n3 can only take the pods of one of the nodes, n2 is the least utilized
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 150, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 150, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 200, 0, n2NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 1800, 0, n3NodeName, test.SetRSOwnerRef),
+			},
+			expectedPodsEvicted: 2,
+			evictedPods:         []string{"p1", "p2"},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -518,6 +547,7 @@ func TestHighNodeUtilization(t *testing.T) {
 
 			plugin, err := NewHighNodeUtilization(&HighNodeUtilizationArgs{
 				Thresholds: testCase.thresholds,
+				NodeCost:   testCase.nodeCost,
 			},
 				handle)
 			if err != nil {
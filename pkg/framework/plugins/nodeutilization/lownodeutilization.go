@@ -24,6 +24,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
 	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
 	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
 	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
@@ -33,7 +34,7 @@ import (
 const LowNodeUtilizationPluginName = "LowNodeUtilization"
 
 // LowNodeUtilization evicts pods from overutilized nodes to underutilized nodes. Note that CPU/Memory requests are used
-// to calculate nodes' utilization and not the actual resource usage.
+// to calculate nodes' utilization unless the actual resource usage is selected through the metricsUtilization args.
 
 type LowNodeUtilization struct {
 	handle    frameworktypes.Handle
@@ -75,51 +76,24 @@ func (l *LowNodeUtilization) Balance(ctx context.Context, nodes []*v1.Node) *fra
 	thresholds := l.args.Thresholds
 	targetThresholds := l.args.TargetThresholds
 
-	// check if Pods/CPU/Mem are set, if not, set them to 100
-	if _, ok := thresholds[v1.ResourcePods]; !ok {
-		if useDeviationThresholds {
-			thresholds[v1.ResourcePods] = MinResourcePercentage
-			targetThresholds[v1.ResourcePods] = MinResourcePercentage
-		} else {
-			thresholds[v1.ResourcePods] = MaxResourcePercentage
-			targetThresholds[v1.ResourcePods] = MaxResourcePercentage
-		}
-	}
-	if _, ok := thresholds[v1.ResourceCPU]; !ok {
-		if useDeviationThresholds {
-			thresholds[v1.ResourceCPU] = MinResourcePercentage
-			targetThresholds[v1.ResourceCPU] = MinResourcePercentage
-		} else {
-			thresholds[v1.ResourceCPU] = MaxResourcePercentage
-			targetThresholds[v1.ResourceCPU] = MaxResourcePercentage
+	SetDefaultForLNUThresholds(thresholds, targetThresholds, useDeviationThresholds)
+	resourceNames := getResourceNames(thresholds)
+
+	nodeGroups, err := newNodeGroups(l.args.NodeGroups, thresholds, targetThresholds)
+	if err != nil {
+		return &frameworktypes.Status{
+			Err: fmt.Errorf("error parsing node groups: %v", err),
 		}
 	}
-	if _, ok := thresholds[v1.ResourceMemory]; !ok {
-		if useDeviationThresholds {
-			thresholds[v1.ResourceMemory] = MinResourcePercentage
-			targetThresholds[v1.ResourceMemory] = MinResourcePercentage
-		} else {
-			thresholds[v1.ResourceMemory] = MaxResourcePercentage
-			targetThresholds[v1.ResourceMemory] = MaxResourcePercentage
+
+	usageClient := newUsageClient(l.args.MetricsUtilization, l.args.UtilizationMode, resourceNames, l.handle)
+	if err := usageClient.sync(ctx, nodes); err != nil {
+		return &frameworktypes.Status{
+			Err: fmt.Errorf("error getting node usage: %v", err),
 		}
 	}
-	resourceNames := getResourceNames(thresholds)
 
-	lowNodes, sourceNodes := classifyNodes(
-		getNodeUsage(nodes, resourceNames, l.handle.GetPodsAssignedToNodeFunc()),
-		getNodeThresholds(nodes, thresholds, targetThresholds, resourceNames, l.handle.GetPodsAssignedToNodeFunc(), useDeviationThresholds),
-		// The node has to be schedulable (to be able to move workload there)
-		func(node *v1.Node, usage NodeUsage, threshold NodeThresholds) bool {
-			if nodeutil.IsNodeUnschedulable(node) {
-				klog.V(2).InfoS("Node is unschedulable, thus not considered as underutilized", "node", klog.KObj(node))
-				return false
-			}
-			return isNodeWithLowUtilization(usage, threshold.lowResourceThreshold)
-		},
-		func(node *v1.Node, usage NodeUsage, threshold NodeThresholds) bool {
-			return isNodeAboveTargetUtilization(usage, threshold.highResourceThreshold)
-		},
-	)
+	lowNodes, sourceNodes := classifyNodesForLNU(nodes, thresholds, targetThresholds, nodeGroups, resourceNames, l.args.OvercommitRatios, useDeviationThresholds, usageClient, stabilizerFor(l.handle, l.Name(), l.args.Stabilization))
 
 	// log message for nodes with low utilization
 	underutilizationCriteria := []interface{}{
@@ -148,6 +122,9 @@ func (l *LowNodeUtilization) Balance(ctx context.Context, nodes []*v1.Node) *fra
 	}
 	klog.V(1).InfoS("Criteria for a node above target utilization", overutilizationCriteria...)
 	klog.V(1).InfoS("Number of overutilized nodes", "totalNumber", len(sourceNodes))
+	for _, group := range nodeGroups {
+		klog.V(1).InfoS("Criteria for the nodes of a node group", "nodeSelector", group.selector.String(), "thresholds", group.thresholds, "targetThresholds", group.targetThresholds)
+	}
 
 	if len(lowNodes) == 0 {
 		klog.V(1).InfoS("No node is underutilized, nothing to do here, you might tune your thresholds further")
@@ -192,10 +169,66 @@ func (l *LowNodeUtilization) Balance(ctx context.Context, nodes []*v1.Node) *fra
 		sourceNodes,
 		lowNodes,
 		l.handle.Evictor(),
+		l.handle.Sorter(),
 		evictions.EvictOptions{StrategyName: LowNodeUtilizationPluginName},
 		l.podFilter,
+		l.args.PodSelectionStrategy,
 		resourceNames,
-		continueEvictionCond)
+		continueEvictionCond,
+		overutilizationScore,
+		usageClient,
+		newDestinationNodes(lowNodes, resourceNames, l.handle.GetPodsAssignedToNodeFunc()))
 
 	return nil
 }
+
+// classifyNodesForLNU classifies the nodes into underutilized nodes the pods are moved to
+// and overutilized nodes the pods are evicted from. With a stabilizer only the nodes
+// classified the same for long enough are returned.
+func classifyNodesForLNU(
+	nodes []*v1.Node,
+	thresholds, targetThresholds api.ResourceThresholds,
+	nodeGroups []nodeGroup,
+	resourceNames []v1.ResourceName,
+	overcommitRatios map[v1.ResourceName]float64,
+	useDeviationThresholds bool,
+	usageClient usageClient,
+	stabilizer *nodeStabilizer,
+) ([]NodeInfo, []NodeInfo) {
+	nodeUsages := getNodeUsage(nodes, usageClient)
+	nodeThresholds := getNodeThresholds(nodes, thresholds, targetThresholds, nodeGroups, resourceNames, overcommitRatios, useDeviationThresholds, usageClient)
+	classThresholds := stabilizer.hysteresisThresholds(nodes, nodeThresholds, false)
+
+	lowNodes, highNodes := classifyNodes(
+		nodeUsages,
+		nodeThresholds,
+		// The node has to be schedulable (to be able to move workload there)
+		func(node *v1.Node, usage NodeUsage, _ NodeThresholds) bool {
+			if nodeutil.IsNodeUnschedulable(node) {
+				klog.V(2).InfoS("Node is unschedulable, thus not considered as underutilized", "node", klog.KObj(node))
+				return false
+			}
+			return isNodeWithLowUtilization(usage, classThresholds[node.Name].lowResourceThreshold)
+		},
+		func(node *v1.Node, usage NodeUsage, _ NodeThresholds) bool {
+			return isNodeAboveTargetUtilization(usage, classThresholds[node.Name].highResourceThreshold)
+		},
+	)
+	return stabilizer.stabilize(nodeUsages, lowNodes, highNodes)
+}
+
+// SetDefaultForLNUThresholds sets the Pods/CPU/Mem thresholds not configured
+// so they never classify a node as under or over utilized
+func SetDefaultForLNUThresholds(thresholds, targetThresholds api.ResourceThresholds, useDeviationThresholds bool) {
+	for _, resourceName := range []v1.ResourceName{v1.ResourcePods, v1.ResourceCPU, v1.ResourceMemory} {
+		if _, ok := thresholds[resourceName]; !ok {
+			if useDeviationThresholds {
+				thresholds[resourceName] = MinResourcePercentage
+				targetThresholds[resourceName] = MinResourcePercentage
+			} else {
+				thresholds[resourceName] = MaxResourcePercentage
+				targetThresholds[resourceName] = MaxResourcePercentage
+			}
+		}
+	}
+}
@@ -30,10 +30,13 @@ import (
 	policy "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
 	core "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/events"
+	"k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	fakemetricsclient "k8s.io/metrics/pkg/client/clientset/versioned/fake"
 
 	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
 	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
@@ -41,6 +44,11 @@ import (
 	"github.com/amit3512/descheduler_policy_master/test"
 )
 
+var (
+	nodesgvr = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+	podsgvr  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+)
+
 func TestLowNodeUtilization(t *testing.T) {
 	n1NodeName := "n1"
 	n2NodeName := "n2"
@@ -59,6 +67,14 @@ func TestLowNodeUtilization(t *testing.T) {
 		expectedPodsEvicted          uint
 		evictedPods                  []string
 		evictableNamespaces          *api.Namespaces
+		metricsUtilization           *MetricsUtilization
+		nodemetricses                []*v1beta1.NodeMetrics
+		podmetricses                 []*v1beta1.PodMetrics
+		prometheusSamples            map[string]float64
+		nodeGroups                   []NodeGroupThresholds
+		podSelectionStrategy         PodSelectionStrategy
+		utilizationMode              UtilizationMode
+		overcommitRatios             map[v1.ResourceName]float64
 	}{
 		{
 			name: "no evictable pods",
@@ -853,6 +869,314 @@ func TestLowNodeUtilization(t *testing.T) {
 			expectedPodsEvicted: 2,
 			evictedPods:         []string{},
 		},
+		{
+			name: "requests of the pods, nodes under requested",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 50,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, test.SetNodeUnschedulable),
+			},
+			pods: []*v1.Pod{
+				// requesting far less than they use
+				test.BuildTestPod("p1", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p5", 100, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+			nodemetricses: []*v1beta1.NodeMetrics{
+				test.BuildNodeMetrics(n1NodeName, 2900, 0),
+				test.BuildNodeMetrics(n2NodeName, 100, 0),
+			},
+			podmetricses: []*v1beta1.PodMetrics{
+				// p1 has no metrics and is never evicted
+				test.BuildPodMetrics("p2", 700, 0),
+				test.BuildPodMetrics("p3", 700, 0),
+				test.BuildPodMetrics("p4", 700, 0),
+				test.BuildPodMetrics("p5", 100, 0),
+			},
+			expectedPodsEvicted: 0,
+		},
+		{
+			name: "actual usage from the metrics server",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 50,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, test.SetNodeUnschedulable),
+			},
+			pods: []*v1.Pod{
+				// requesting far less than they use
+				test.BuildTestPod("p1", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 100, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p5", 100, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+			nodemetricses: []*v1beta1.NodeMetrics{
+				test.BuildNodeMetrics(n1NodeName, 2900, 0),
+				test.BuildNodeMetrics(n2NodeName, 100, 0),
+			},
+			podmetricses: []*v1beta1.PodMetrics{
+				// p1 has no metrics and is never evicted
+				test.BuildPodMetrics("p2", 700, 0),
+				test.BuildPodMetrics("p3", 700, 0),
+				test.BuildPodMetrics("p4", 700, 0),
+				test.BuildPodMetrics("p5", 100, 0),
+			},
+			metricsUtilization: &MetricsUtilization{MetricsServer: true},
+			// n1 drops below the target utilization once 2 pods are gone
+			expectedPodsEvicted: 2,
+			evictedPods:         []string{"p2", "p3", "p4"},
+		},
+		{
+			name: "actual usage from prometheus",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 50,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, test.SetNodeUnschedulable),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p5", 400, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+			// n1 is at 40% by the requests, but its p95 usage is 65%
+			prometheusSamples: map[string]float64{
+				n1NodeName: 0.65,
+				n2NodeName: 0.1,
+				n3NodeName: 0.1,
+			},
+			// the pods are accounted with their requests, n1 drops below 50% after 2 evictions
+			expectedPodsEvicted: 2,
+			evictedPods:         []string{"p1", "p2", "p3", "p4"},
+		},
+		{
+			name: "lower target thresholds of a node group",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 60,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{
+					NodeSelector:     "pool=compute",
+					TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 30},
+				},
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, func(node *v1.Node) {
+					node.Labels = map[string]string{"pool": "compute"}
+				}),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, test.SetNodeUnschedulable),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 400, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p5", 400, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+			// n1 is at 40%, above the 30% of its group but below the 60% of the plugin
+			expectedPodsEvicted: 1,
+			evictedPods:         []string{"p1", "p2", "p3", "p4"},
+		},
+		{
+			name: "pods not fitting on a single underutilized node are not evicted",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 40,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				// evicted first, but only 600m are left below the target of n2 and n3 each
+				test.BuildTestPod("p1", 1200, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					test.SetPodPriority(pod, lowPriority)
+				}),
+				test.BuildTestPod("p2", 400, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					test.SetPodPriority(pod, highPriority)
+				}),
+				test.BuildTestPod("p3", 400, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					test.SetPodPriority(pod, highPriority)
+				}),
+				test.BuildTestPod("p4", 400, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					test.SetPodPriority(pod, highPriority)
+				}),
+				test.BuildTestPod("p5", 1000, 0, n2NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p6", 1000, 0, n3NodeName, test.SetRSOwnerRef),
+			},
+			expectedPodsEvicted: 2,
+			evictedPods:         []string{"p2", "p3", "p4"},
+		},
+		{
+			name: "evictions limited by the capacity reserved on the only matching node",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 50,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, func(node *v1.Node) {
+					node.Labels = map[string]string{nodeSelectorKey: nodeSelectorValue}
+				}),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 600, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.NodeSelector = map[string]string{nodeSelectorKey: nodeSelectorValue}
+				}),
+				test.BuildTestPod("p2", 600, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.NodeSelector = map[string]string{nodeSelectorKey: nodeSelectorValue}
+				}),
+				test.BuildTestPod("p3", 600, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.NodeSelector = map[string]string{nodeSelectorKey: nodeSelectorValue}
+				}),
+				test.BuildTestPod("p4", 600, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.NodeSelector = map[string]string{nodeSelectorKey: nodeSelectorValue}
+				}),
+				test.BuildTestPod("p5", 600, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.NodeSelector = map[string]string{nodeSelectorKey: nodeSelectorValue}
+				}),
+				test.BuildTestPod("p6", 600, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.NodeSelector = map[string]string{nodeSelectorKey: nodeSelectorValue}
+				}),
+				test.BuildTestPod("p7", 1000, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+			// n2 has 1000m left below its target, n1 stays overutilized as n3 can not take the pods
+			expectedPodsEvicted: 1,
+			evictedPods:         []string{"p1", "p2", "p3", "p4", "p5", "p6"},
+		},
+		{
+			name: "largest pod evicted first",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 50,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				test.BuildTestPod("p1", 200, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 200, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 200, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p4", 200, 0, n1NodeName, test.SetRSOwnerRef),
+				// evicted after the burstable pods by the QoS tiers
+				test.BuildTestPod("p5", 1600, 100, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					test.MakeGuaranteedPod(pod)
+				}),
+			},
+			podSelectionStrategy: LargestFirst,
+			// a single eviction gets n1 below its target thresholds, instead of two
+			expectedPodsEvicted: 1,
+			evictedPods:         []string{"p5"},
+		},
+		{
+			name: "utilization from the limits of the pods",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 50,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				// 15% of the cpu of n1 is requested, 75% is in limits
+				test.BuildTestPod("p1", 200, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.Containers[0].Resources.Limits[v1.ResourceCPU] = *resource.NewMilliQuantity(1000, resource.DecimalSI)
+				}),
+				test.BuildTestPod("p2", 200, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.Containers[0].Resources.Limits[v1.ResourceCPU] = *resource.NewMilliQuantity(1000, resource.DecimalSI)
+				}),
+				test.BuildTestPod("p3", 200, 0, n1NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.Containers[0].Resources.Limits[v1.ResourceCPU] = *resource.NewMilliQuantity(1000, resource.DecimalSI)
+				}),
+				test.BuildTestPod("p4", 200, 0, n2NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.Containers[0].Resources.Limits[v1.ResourceCPU] = *resource.NewMilliQuantity(200, resource.DecimalSI)
+				}),
+				test.BuildTestPod("p5", 200, 0, n3NodeName, func(pod *v1.Pod) {
+					test.SetRSOwnerRef(pod)
+					pod.Spec.Containers[0].Resources.Limits[v1.ResourceCPU] = *resource.NewMilliQuantity(200, resource.DecimalSI)
+				}),
+			},
+			utilizationMode:     Limits,
+			expectedPodsEvicted: 1,
+			evictedPods:         []string{"p1", "p2", "p3"},
+		},
+		{
+			name: "no overutilized node with a cpu overcommit ratio",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 30,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 50,
+			},
+			nodes: []*v1.Node{
+				test.BuildTestNode(n1NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n2NodeName, 4000, 3000, 10, nil),
+				test.BuildTestNode(n3NodeName, 4000, 3000, 10, nil),
+			},
+			pods: []*v1.Pod{
+				// 60% of the allocatable cpu of n1, 40% once overcommitted
+				test.BuildTestPod("p1", 1200, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p2", 1200, 0, n1NodeName, test.SetRSOwnerRef),
+				test.BuildTestPod("p3", 200, 0, n2NodeName, test.SetRSOwnerRef),
+			},
+			overcommitRatios:    map[v1.ResourceName]float64{v1.ResourceCPU: 1.5},
+			expectedPodsEvicted: 0,
+			evictedPods:         []string{},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -898,6 +1222,22 @@ func TestLowNodeUtilization(t *testing.T) {
 				})
 			}
 
+			metricsClientset := fakemetricsclient.NewSimpleClientset()
+			for _, nodemetrics := range tc.nodemetricses {
+				metricsClientset.Tracker().Create(nodesgvr, nodemetrics, "")
+			}
+			for _, podmetrics := range tc.podmetricses {
+				metricsClientset.Tracker().Create(podsgvr, podmetrics, podmetrics.Namespace)
+			}
+
+			metricsUtilization := tc.metricsUtilization
+			if tc.prometheusSamples != nil {
+				server := newPrometheusServer(t, tc.prometheusSamples)
+				metricsUtilization = &MetricsUtilization{
+					Prometheus: &Prometheus{URL: server.URL, Query: testPrometheusQuery},
+				}
+			}
+
 			sharedInformerFactory.Start(ctx.Done())
 			sharedInformerFactory.WaitForCacheSync(ctx.Done())
 
@@ -927,6 +1267,7 @@ func TestLowNodeUtilization(t *testing.T) {
 
 			handle := &frameworkfake.HandleImpl{
 				ClientsetImpl:                 fakeClient,
+				MetricsClientsetImpl:          metricsClientset,
 				GetPodsAssignedToNodeFuncImpl: getPodsAssignedToNode,
 				PodEvictorImpl:                podEvictor,
 				EvictorFilterImpl:             evictorFilter.(frameworktypes.EvictorPlugin),
@@ -938,6 +1279,11 @@ func TestLowNodeUtilization(t *testing.T) {
 				TargetThresholds:       tc.targetThresholds,
 				UseDeviationThresholds: tc.useDeviationThresholds,
 				EvictableNamespaces:    tc.evictableNamespaces,
+				MetricsUtilization:     metricsUtilization,
+				NodeGroups:             tc.nodeGroups,
+				PodSelectionStrategy:   tc.podSelectionStrategy,
+				UtilizationMode:        tc.utilizationMode,
+				OvercommitRatios:       tc.overcommitRatios,
 			},
 				handle)
 			if err != nil {
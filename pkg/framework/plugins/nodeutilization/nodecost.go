@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// sortNodesByCost orders the nodes by their cost in descending order. The nodes of the same
+// cost keep their order, so the nodes sorted by usage before are still ordered by usage.
+func sortNodesByCost(nodes []NodeInfo, nodeCost *NodeCost) {
+	costs := make(map[string]float64, len(nodes))
+	for _, nodeInfo := range nodes {
+		costs[nodeInfo.node.Name] = nodeCostOf(nodeInfo.node, nodeCost)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return costs[nodes[i].node.Name] > costs[nodes[j].node.Name]
+	})
+}
+
+// costScore scores the evictions from a node by the average of its score and its cost, normalized by the
+// cost of the most expensive of the nodes, so the pods of the expensive nodes are ranked first in an eviction plan
+func costScore(nodes []NodeInfo, nodeCost *NodeCost, score func(NodeInfo) float64) func(NodeInfo) float64 {
+	costs := make(map[string]float64, len(nodes))
+	maxCost := 0.0
+	for _, nodeInfo := range nodes {
+		costs[nodeInfo.node.Name] = nodeCostOf(nodeInfo.node, nodeCost)
+		maxCost = math.Max(maxCost, costs[nodeInfo.node.Name])
+	}
+	return func(nodeInfo NodeInfo) float64 {
+		normalized := 0.0
+		if maxCost > 0 {
+			normalized = costs[nodeInfo.node.Name] / maxCost
+		}
+		return (score(nodeInfo) + normalized) / 2
+	}
+}
+
+// nodeCostOf returns the cost of the node, the default cost when the node has no valid value
+func nodeCostOf(node *v1.Node, nodeCost *NodeCost) float64 {
+	var value string
+	var ok bool
+	if nodeCost.Label != "" {
+		value, ok = node.Labels[nodeCost.Label]
+	} else {
+		value, ok = node.Annotations[nodeCost.Annotation]
+	}
+	if !ok {
+		return nodeCost.Default
+	}
+
+	if cost, ok := nodeCost.Values[value]; ok {
+		return cost
+	}
+	cost, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(cost) || math.IsInf(cost, 0) || cost < 0 {
+		klog.V(3).InfoS("Invalid node cost, using the default", "node", klog.KObj(node), "value", value)
+		return nodeCost.Default
+	}
+	return cost
+}
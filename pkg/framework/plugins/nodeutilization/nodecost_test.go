@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"math"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestNodeCostOf(t *testing.T) {
+	capacityType := &NodeCost{
+		Label:   "karpenter.sh/capacity-type",
+		Values:  map[string]float64{"on-demand": 3, "spot": 1},
+		Default: 2,
+	}
+	hourlyPrice := &NodeCost{
+		Annotation: "example.com/hourly-price",
+		Default:    1,
+	}
+
+	testCases := []struct {
+		name        string
+		nodeCost    *NodeCost
+		labels      map[string]string
+		annotations map[string]string
+		expected    float64
+	}{
+		{
+			name:     "mapped label value",
+			nodeCost: capacityType,
+			labels:   map[string]string{"karpenter.sh/capacity-type": "spot"},
+			expected: 1,
+		},
+		{
+			name:     "numeric label value",
+			nodeCost: capacityType,
+			labels:   map[string]string{"karpenter.sh/capacity-type": "2.5"},
+			expected: 2.5,
+		},
+		{
+			name:     "missing label",
+			nodeCost: capacityType,
+			expected: 2,
+		},
+		{
+			name:        "annotation value",
+			nodeCost:    hourlyPrice,
+			annotations: map[string]string{"example.com/hourly-price": "0.384"},
+			expected:    0.384,
+		},
+		{
+			name:     "label is ignored when the annotation is configured",
+			nodeCost: hourlyPrice,
+			labels:   map[string]string{"example.com/hourly-price": "0.384"},
+			expected: 1,
+		},
+		{
+			name:        "invalid annotation value",
+			nodeCost:    hourlyPrice,
+			annotations: map[string]string{"example.com/hourly-price": "expensive"},
+			expected:    1,
+		},
+		{
+			name:        "negative annotation value",
+			nodeCost:    hourlyPrice,
+			annotations: map[string]string{"example.com/hourly-price": "-3"},
+			expected:    1,
+		},
+		{
+			name:        "NaN annotation value",
+			nodeCost:    hourlyPrice,
+			annotations: map[string]string{"example.com/hourly-price": "NaN"},
+			expected:    1,
+		},
+		{
+			name:        "infinite annotation value",
+			nodeCost:    hourlyPrice,
+			annotations: map[string]string{"example.com/hourly-price": "+Inf"},
+			expected:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			node := test.BuildTestNode("n1", 2000, 3000, 10, func(node *v1.Node) {
+				node.Labels = tc.labels
+				node.Annotations = tc.annotations
+			})
+			if cost := nodeCostOf(node, tc.nodeCost); cost != tc.expected {
+				t.Errorf("Expected node cost %v, got %v", tc.expected, cost)
+			}
+		})
+	}
+}
+
+func TestCostScore(t *testing.T) {
+	nodeCost := &NodeCost{
+		Label:  "karpenter.sh/capacity-type",
+		Values: map[string]float64{"on-demand": 4, "spot": 1},
+	}
+	nodeInfo := func(name, capacityType string) NodeInfo {
+		return NodeInfo{
+			NodeUsage: NodeUsage{
+				node: test.BuildTestNode(name, 2000, 3000, 10, func(node *v1.Node) {
+					if capacityType != "" {
+						node.Labels = map[string]string{"karpenter.sh/capacity-type": capacityType}
+					}
+				}),
+			},
+		}
+	}
+	onDemand := nodeInfo("on-demand", "on-demand")
+	spot := nodeInfo("spot", "spot")
+	unknown := nodeInfo("unknown", "")
+
+	// the spot node is the emptiest
+	utilizationScores := map[string]float64{"on-demand": 0.2, "spot": 0.6, "unknown": 0.4}
+	score := costScore([]NodeInfo{onDemand, spot, unknown}, nodeCost, func(nodeInfo NodeInfo) float64 {
+		return utilizationScores[nodeInfo.node.Name]
+	})
+
+	for _, tc := range []struct {
+		nodeInfo NodeInfo
+		expected float64
+	}{
+		{nodeInfo: onDemand, expected: 0.6},
+		{nodeInfo: spot, expected: 0.425},
+		{nodeInfo: unknown, expected: 0.2},
+	} {
+		if got := score(tc.nodeInfo); math.Abs(got-tc.expected) > 1e-9 {
+			t.Errorf("Expected score %v for node %v, got %v", tc.expected, tc.nodeInfo.node.Name, got)
+		}
+	}
+
+	// without any cost the score is halved for every node, keeping their order
+	free := costScore([]NodeInfo{unknown}, nodeCost, func(NodeInfo) float64 { return 0.4 })
+	if got := free(unknown); math.Abs(got-0.2) > 1e-9 {
+		t.Errorf("Expected score 0.2 for node %v, got %v", unknown.node.Name, got)
+	}
+}
+
+func TestSortNodesByCost(t *testing.T) {
+	nodeCost := &NodeCost{
+		Label:  "karpenter.sh/capacity-type",
+		Values: map[string]float64{"on-demand": 3, "spot": 1},
+	}
+	nodeInfo := func(name, capacityType string) NodeInfo {
+		return NodeInfo{
+			NodeUsage: NodeUsage{
+				node: test.BuildTestNode(name, 2000, 3000, 10, func(node *v1.Node) {
+					node.Labels = map[string]string{"karpenter.sh/capacity-type": capacityType}
+				}),
+			},
+		}
+	}
+
+	// the nodes are sorted by usage already
+	nodes := []NodeInfo{
+		nodeInfo("spot-1", "spot"),
+		nodeInfo("on-demand-1", "on-demand"),
+		nodeInfo("spot-2", "spot"),
+		nodeInfo("on-demand-2", "on-demand"),
+	}
+	sortNodesByCost(nodes, nodeCost)
+
+	expected := []string{"on-demand-1", "on-demand-2", "spot-1", "spot-2"}
+	for i, name := range nodeInfoNames(nodes) {
+		if name != expected[i] {
+			t.Fatalf("Expected nodes %v, got %v", expected, nodeInfoNames(nodes))
+		}
+	}
+}
@@ -18,6 +18,7 @@ package nodeutilization
 
 import (
 	"context"
+	"fmt"
 	"math"
 	"sort"
 
@@ -25,6 +26,8 @@ import (
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
@@ -54,6 +57,9 @@ type NodeInfo struct {
 
 type continueEvictionCond func(nodeInfo NodeInfo, totalAvailableUsage map[v1.ResourceName]*resource.Quantity) bool
 
+// evictionScoreFunc scores the next eviction from the node, see evictions.EvictOptions.Score
+type evictionScoreFunc func(nodeInfo NodeInfo) float64
+
 // NodePodsMap is a set of (node, pods) pairs
 type NodePodsMap map[*v1.Node][]*v1.Pod
 
@@ -62,6 +68,8 @@ const (
 	MinResourcePercentage = 0
 	// MaxResourcePercentage is the maximum value of a resource's percentage
 	MaxResourcePercentage = 100
+	// MaxOvercommitRatio is the maximum value of a resource's overcommit ratio
+	MaxOvercommitRatio = 100
 )
 
 func normalizePercentage(percent api.Percentage) api.Percentage {
@@ -74,75 +82,154 @@ func normalizePercentage(percent api.Percentage) api.Percentage {
 	return percent
 }
 
+// nodeGroup holds the thresholds of the nodes matching the selector, merged with the ones of the plugin
+type nodeGroup struct {
+	selector                     labels.Selector
+	thresholds, targetThresholds api.ResourceThresholds
+}
+
+// newNodeGroups parses the node selectors of the groups and overrides the thresholds of the plugin,
+// with their defaults already set, with the thresholds of every group
+func newNodeGroups(groups []NodeGroupThresholds, thresholds, targetThresholds api.ResourceThresholds) ([]nodeGroup, error) {
+	nodeGroups := make([]nodeGroup, 0, len(groups))
+	for i, group := range groups {
+		selector, err := labels.Parse(group.NodeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node selector of node group %d: %v", i, err)
+		}
+		nodeGroups = append(nodeGroups, nodeGroup{
+			selector:         selector,
+			thresholds:       mergeThresholds(thresholds, group.Thresholds),
+			targetThresholds: mergeThresholds(targetThresholds, group.TargetThresholds),
+		})
+	}
+	return nodeGroups, nil
+}
+
+func mergeThresholds(thresholds, overrides api.ResourceThresholds) api.ResourceThresholds {
+	merged := copyThresholds(thresholds)
+	for name, percentage := range overrides {
+		merged[name] = percentage
+	}
+	return merged
+}
+
+// nodeGroupIndex returns the index of the first group matching the node, len(nodeGroups) if none does
+func nodeGroupIndex(nodeGroups []nodeGroup, node *v1.Node) int {
+	for i, group := range nodeGroups {
+		if group.selector.Matches(labels.Set(node.Labels)) {
+			return i
+		}
+	}
+	return len(nodeGroups)
+}
+
 func getNodeThresholds(
 	nodes []*v1.Node,
 	lowThreshold, highThreshold api.ResourceThresholds,
+	nodeGroups []nodeGroup,
 	resourceNames []v1.ResourceName,
-	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc,
+	overcommitRatios map[v1.ResourceName]float64,
 	useDeviationThresholds bool,
+	usageClient usageClient,
 ) map[string]NodeThresholds {
 	nodeThresholdsMap := map[string]NodeThresholds{}
 
-	averageResourceUsagePercent := api.ResourceThresholds{}
-	if useDeviationThresholds {
-		averageResourceUsagePercent = averageNodeBasicresources(nodes, getPodsAssignedToNode, resourceNames)
+	// the nodes matching none of the groups come last
+	groupNodes := make([][]*v1.Node, len(nodeGroups)+1)
+	for _, node := range nodes {
+		i := nodeGroupIndex(nodeGroups, node)
+		groupNodes[i] = append(groupNodes[i], node)
 	}
 
-	for _, node := range nodes {
-		nodeCapacity := node.Status.Capacity
-		if len(node.Status.Allocatable) > 0 {
-			nodeCapacity = node.Status.Allocatable
+	for i, nodes := range groupNodes {
+		lowThreshold, highThreshold := lowThreshold, highThreshold
+		if i < len(nodeGroups) {
+			lowThreshold, highThreshold = nodeGroups[i].thresholds, nodeGroups[i].targetThresholds
 		}
 
-		nodeThresholdsMap[node.Name] = NodeThresholds{
-			lowResourceThreshold:  map[v1.ResourceName]*resource.Quantity{},
-			highResourceThreshold: map[v1.ResourceName]*resource.Quantity{},
+		// the deviation is computed from the average utilization of the nodes of the same group
+		averageResourceUsagePercent := api.ResourceThresholds{}
+		if useDeviationThresholds {
+			averageResourceUsagePercent = averageNodeBasicresources(nodes, overcommitRatios, usageClient)
 		}
 
-		for _, resourceName := range resourceNames {
-			if useDeviationThresholds {
-				cap := nodeCapacity[resourceName]
-				if lowThreshold[resourceName] == MinResourcePercentage {
-					nodeThresholdsMap[node.Name].lowResourceThreshold[resourceName] = &cap
-					nodeThresholdsMap[node.Name].highResourceThreshold[resourceName] = &cap
+		for _, node := range nodes {
+			nodeCapacity := overcommittedCapacity(node, overcommitRatios)
+
+			nodeThresholdsMap[node.Name] = NodeThresholds{
+				lowResourceThreshold:  map[v1.ResourceName]*resource.Quantity{},
+				highResourceThreshold: map[v1.ResourceName]*resource.Quantity{},
+			}
+
+			for _, resourceName := range resourceNames {
+				if useDeviationThresholds {
+					cap := nodeCapacity[resourceName]
+					if lowThreshold[resourceName] == MinResourcePercentage {
+						nodeThresholdsMap[node.Name].lowResourceThreshold[resourceName] = &cap
+						nodeThresholdsMap[node.Name].highResourceThreshold[resourceName] = &cap
+					} else {
+						nodeThresholdsMap[node.Name].lowResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, normalizePercentage(averageResourceUsagePercent[resourceName]-lowThreshold[resourceName]))
+						nodeThresholdsMap[node.Name].highResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, normalizePercentage(averageResourceUsagePercent[resourceName]+highThreshold[resourceName]))
+					}
 				} else {
-					nodeThresholdsMap[node.Name].lowResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, normalizePercentage(averageResourceUsagePercent[resourceName]-lowThreshold[resourceName]))
-					nodeThresholdsMap[node.Name].highResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, normalizePercentage(averageResourceUsagePercent[resourceName]+highThreshold[resourceName]))
+					nodeThresholdsMap[node.Name].lowResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, lowThreshold[resourceName])
+					nodeThresholdsMap[node.Name].highResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, highThreshold[resourceName])
 				}
-			} else {
-				nodeThresholdsMap[node.Name].lowResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, lowThreshold[resourceName])
-				nodeThresholdsMap[node.Name].highResourceThreshold[resourceName] = resourceThreshold(nodeCapacity, resourceName, highThreshold[resourceName])
 			}
 		}
-
 	}
 	return nodeThresholdsMap
 }
 
 func getNodeUsage(
 	nodes []*v1.Node,
-	resourceNames []v1.ResourceName,
-	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc,
+	usageClient usageClient,
 ) []NodeUsage {
 	var nodeUsageList []NodeUsage
 
 	for _, node := range nodes {
-		pods, err := podutil.ListPodsOnANode(node.Name, getPodsAssignedToNode, nil)
-		if err != nil {
-			klog.V(2).InfoS("Node will not be processed, error accessing its pods", "node", klog.KObj(node), "err", err)
+		usage := usageClient.nodeUtilization(node.Name)
+		if usage == nil {
 			continue
 		}
 
 		nodeUsageList = append(nodeUsageList, NodeUsage{
 			node:    node,
-			usage:   nodeutil.NodeUtilization(pods, resourceNames),
-			allPods: pods,
+			usage:   usage,
+			allPods: usageClient.pods(node.Name),
 		})
 	}
 
 	return nodeUsageList
 }
 
+// overcommittedCapacity returns the allocatable of the node, or its capacity if not set,
+// multiplied by the overcommit ratio of every resource
+func overcommittedCapacity(node *v1.Node, overcommitRatios map[v1.ResourceName]float64) v1.ResourceList {
+	nodeCapacity := node.Status.Capacity
+	if len(node.Status.Allocatable) > 0 {
+		nodeCapacity = node.Status.Allocatable
+	}
+	if len(overcommitRatios) == 0 {
+		return nodeCapacity
+	}
+
+	overcommitted := nodeCapacity.DeepCopy()
+	for name, ratio := range overcommitRatios {
+		quantity, ok := overcommitted[name]
+		if !ok {
+			continue
+		}
+		if name == v1.ResourceCPU {
+			overcommitted[name] = *resource.NewMilliQuantity(int64(ratio*float64(quantity.MilliValue())), quantity.Format)
+		} else {
+			overcommitted[name] = *resource.NewQuantity(int64(ratio*float64(quantity.Value())), quantity.Format)
+		}
+	}
+	return overcommitted
+}
+
 func resourceThreshold(nodeCapacity v1.ResourceList, resourceName v1.ResourceName, threshold api.Percentage) *resource.Quantity {
 	defaultFormat := resource.DecimalSI
 	if resourceName == v1.ResourceMemory {
@@ -214,18 +301,79 @@ func classifyNodes(
 	return lowNodes, highNodes
 }
 
+// ClassifyNodes returns the names of the nodes the LowNodeUtilization or HighNodeUtilization
+// plugin configured with the given args classifies as underutilized and overutilized.
+// The utilization is always computed from the resources of the pods, never from metrics. The args are not modified.
+func ClassifyNodes(args runtime.Object, nodes []*v1.Node, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc) ([]string, []string, error) {
+	var lowNodes, highNodes []NodeInfo
+	switch t := args.(type) {
+	case *LowNodeUtilizationArgs:
+		thresholds, targetThresholds := copyThresholds(t.Thresholds), copyThresholds(t.TargetThresholds)
+		SetDefaultForLNUThresholds(thresholds, targetThresholds, t.UseDeviationThresholds)
+		resourceNames := getResourceNames(thresholds)
+		nodeGroups, err := newNodeGroups(t.NodeGroups, thresholds, targetThresholds)
+		if err != nil {
+			return nil, nil, err
+		}
+		usageClient := newRequestedUsageClient(resourceNames, getPodsAssignedToNode, t.UtilizationMode)
+		if err := usageClient.sync(context.TODO(), nodes); err != nil {
+			return nil, nil, err
+		}
+		lowNodes, highNodes = classifyNodesForLNU(nodes, thresholds, targetThresholds, nodeGroups, resourceNames, t.OvercommitRatios, t.UseDeviationThresholds, usageClient, nil)
+	case *HighNodeUtilizationArgs:
+		thresholds, targetThresholds := copyThresholds(t.Thresholds), api.ResourceThresholds{}
+		setDefaultForThresholds(thresholds, targetThresholds)
+		resourceNames := getResourceNames(targetThresholds)
+		nodeGroups, err := newNodeGroups(t.NodeGroups, thresholds, targetThresholds)
+		if err != nil {
+			return nil, nil, err
+		}
+		usageClient := newRequestedUsageClient(resourceNames, getPodsAssignedToNode, t.UtilizationMode)
+		if err := usageClient.sync(context.TODO(), nodes); err != nil {
+			return nil, nil, err
+		}
+		lowNodes, highNodes = classifyNodesForHNU(nodes, thresholds, targetThresholds, nodeGroups, resourceNames, t.OvercommitRatios, usageClient, nil)
+	default:
+		return nil, nil, fmt.Errorf("want args to be of type LowNodeUtilizationArgs or HighNodeUtilizationArgs, got %T", args)
+	}
+	return nodeInfoNames(lowNodes), nodeInfoNames(highNodes), nil
+}
+
+func copyThresholds(thresholds api.ResourceThresholds) api.ResourceThresholds {
+	thresholdsCopy := api.ResourceThresholds{}
+	for name, percentage := range thresholds {
+		thresholdsCopy[name] = percentage
+	}
+	return thresholdsCopy
+}
+
+func nodeInfoNames(nodeInfos []NodeInfo) []string {
+	names := []string{}
+	for _, nodeInfo := range nodeInfos {
+		names = append(names, nodeInfo.node.Name)
+	}
+	return names
+}
+
 // evictPodsFromSourceNodes evicts pods based on priority, if all the pods on the node have priority, if not
-// evicts them based on QoS as fallback option.
+// evicts them based on QoS as fallback option. The pods of the same priority are ordered by the pod
+// selection strategy first, if any. With destinations, a pod is only evicted when one of the
+// destination nodes can take it.
 // TODO: @ravig Break this function into smaller functions.
 func evictPodsFromSourceNodes(
 	ctx context.Context,
 	evictableNamespaces *api.Namespaces,
 	sourceNodes, destinationNodes []NodeInfo,
 	podEvictor frameworktypes.Evictor,
+	podSorter frameworktypes.Sorter,
 	evictOptions evictions.EvictOptions,
 	podFilter func(pod *v1.Pod) bool,
+	podSelectionStrategy PodSelectionStrategy,
 	resourceNames []v1.ResourceName,
 	continueEviction continueEvictionCond,
+	evictionScore evictionScoreFunc,
+	usageClient usageClient,
+	destinations *destinationNodes,
 ) {
 	// upper bound on total number of pods/cpu/memory and optional extended resources to be moved
 	totalAvailableUsage := map[v1.ResourceName]*resource.Quantity{
@@ -274,7 +422,11 @@ func evictPodsFromSourceNodes(
 		klog.V(1).InfoS("Evicting pods based on priority, if they have same priority, they'll be evicted based on QoS tiers")
 		// sort the evictable Pods based on priority. This also sorts them based on QoS. If there are multiple pods with same priority, they are sorted based on QoS tiers.
 		podutil.SortPodsBasedOnPriorityLowToHigh(removablePods)
-		err := evictPods(ctx, evictableNamespaces, removablePods, node, totalAvailableUsage, taintsOfDestinationNodes, podEvictor, evictOptions, continueEviction)
+		// the pod selection strategy takes precedence over the QoS tiers
+		sortPodsBySelectionStrategy(removablePods, podSelectionStrategy, node, resourceNames, usageClient)
+		// sort plugins enabled in the profile take precedence, the priority order above breaks ties
+		podSorter.Sort(removablePods)
+		err := evictPods(ctx, evictableNamespaces, removablePods, node, totalAvailableUsage, taintsOfDestinationNodes, podEvictor, evictOptions, continueEviction, evictionScore, usageClient, destinations)
 		if err != nil {
 			switch err.(type) {
 			case *evictions.EvictionTotalLimitError:
@@ -295,6 +447,9 @@ func evictPods(
 	podEvictor frameworktypes.Evictor,
 	evictOptions evictions.EvictOptions,
 	continueEviction continueEvictionCond,
+	evictionScore evictionScoreFunc,
+	usageClient usageClient,
+	destinations *destinationNodes,
 ) error {
 	var excludedNamespaces sets.Set[string]
 	if evictableNamespaces != nil {
@@ -320,19 +475,40 @@ func evictPods(
 			if !preEvictionFilterWithOptions(pod) {
 				continue
 			}
-			err = podEvictor.Evict(ctx, pod, evictOptions)
+
+			// the usage of the pod is subtracted once it is evicted, it has to be known upfront
+			podUsage, err := usageClient.podUsage(pod)
+			if err != nil {
+				klog.ErrorS(err, "unable to get the usage of the pod, skipping its eviction", "pod", klog.KObj(pod))
+				continue
+			}
+
+			var destination *v1.Node
+			if destinations != nil {
+				destination, err = destinations.find(pod, podUsage)
+				if err != nil {
+					klog.V(3).InfoS("Skipping eviction for pod, no destination node can take it", "pod", klog.KObj(pod), "err", err)
+					continue
+				}
+			}
+
+			opts := evictOptions
+			opts.Score = evictionScore(nodeInfo)
+			if destination != nil {
+				opts.TargetNode = destination.Name
+			}
+			err = podEvictor.Evict(ctx, pod, opts)
 			if err == nil {
 				klog.V(3).InfoS("Evicted pods", "pod", klog.KObj(pod))
 
 				for name := range totalAvailableUsage {
-					if name == v1.ResourcePods {
-						nodeInfo.usage[name].Sub(*resource.NewQuantity(1, resource.DecimalSI))
-						totalAvailableUsage[name].Sub(*resource.NewQuantity(1, resource.DecimalSI))
-					} else {
-						quantity := utils.GetResourceRequestQuantity(pod, name)
-						nodeInfo.usage[name].Sub(quantity)
-						totalAvailableUsage[name].Sub(quantity)
-					}
+					quantity := podQuantity(pod, podUsage, name)
+					nodeInfo.usage[name].Sub(*quantity)
+					totalAvailableUsage[name].Sub(*quantity)
+				}
+				if destination != nil {
+					klog.V(3).InfoS("Reserved the usage of the evicted pod on the destination node", "pod", klog.KObj(pod), "node", klog.KObj(destination))
+					destinations.reserve(pod, podUsage, destination)
 				}
 
 				keysAndValues := []interface{}{
@@ -389,12 +565,57 @@ func sortNodesByUsage(nodes []NodeInfo, ascending bool) {
 	})
 }
 
+// overutilizationScore scores the evictions from an overutilized node by how far the most used resource
+// is above the high threshold, as a fraction of the capacity of the node
+func overutilizationScore(nodeInfo NodeInfo) float64 {
+	return OverutilizationScore(nodeInfo.node, nodeInfo.usage, nodeInfo.thresholds.highResourceThreshold)
+}
+
+// OverutilizationScore scores the evictions from a node with the given usage by how far the most used
+// resource is above the high threshold, as a fraction of the capacity of the node
+func OverutilizationScore(node *v1.Node, usage, highThreshold map[v1.ResourceName]*resource.Quantity) float64 {
+	score := 0.0
+	for _, fraction := range thresholdDistances(node, usage, highThreshold) {
+		score = math.Max(score, -fraction)
+	}
+	return math.Min(score, 1)
+}
+
+// underutilizationScore scores the evictions from an underutilized node by how far the most used resource
+// is below the low threshold, as a fraction of the capacity of the node, so the emptiest nodes are drained first
+func underutilizationScore(nodeInfo NodeInfo) float64 {
+	score := 1.0
+	for _, fraction := range thresholdDistances(nodeInfo.node, nodeInfo.usage, nodeInfo.thresholds.lowResourceThreshold) {
+		score = math.Min(score, fraction)
+	}
+	return math.Max(score, 0)
+}
+
+// thresholdDistances returns, per resource, the distance of the usage of the node below the threshold
+// as a fraction of the capacity of the node, negative when the usage is above the threshold
+func thresholdDistances(node *v1.Node, usages, threshold map[v1.ResourceName]*resource.Quantity) map[v1.ResourceName]float64 {
+	nodeCapacity := node.Status.Capacity
+	if len(node.Status.Allocatable) > 0 {
+		nodeCapacity = node.Status.Allocatable
+	}
+
+	distances := map[v1.ResourceName]float64{}
+	for name, usage := range usages {
+		capacity, ok := nodeCapacity[name]
+		if !ok || capacity.IsZero() || threshold[name] == nil {
+			continue
+		}
+		distances[name] = float64(threshold[name].MilliValue()-usage.MilliValue()) / float64(capacity.MilliValue())
+	}
+	return distances
+}
+
 // isNodeAboveTargetUtilization checks if a node is overutilized
 // At least one resource has to be above the high threshold
 func isNodeAboveTargetUtilization(usage NodeUsage, threshold map[v1.ResourceName]*resource.Quantity) bool {
 	for name, nodeValue := range usage.usage {
 		// usage.highResourceThreshold[name] < nodeValue
-		if threshold[name].Cmp(*nodeValue) == -1 {
+		if threshold[name] != nil && threshold[name].Cmp(*nodeValue) == -1 {
 			return true
 		}
 	}
@@ -406,7 +627,7 @@ func isNodeAboveTargetUtilization(usage NodeUsage, threshold map[v1.ResourceName
 func isNodeWithLowUtilization(usage NodeUsage, threshold map[v1.ResourceName]*resource.Quantity) bool {
 	for name, nodeValue := range usage.usage {
 		// usage.lowResourceThreshold[name] < nodeValue
-		if threshold[name].Cmp(*nodeValue) == -1 {
+		if threshold[name] != nil && threshold[name].Cmp(*nodeValue) == -1 {
 			return false
 		}
 	}
@@ -437,21 +658,17 @@ func classifyPods(pods []*v1.Pod, filter func(pod *v1.Pod) bool) ([]*v1.Pod, []*
 	return nonRemovablePods, removablePods
 }
 
-func averageNodeBasicresources(nodes []*v1.Node, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc, resourceNames []v1.ResourceName) api.ResourceThresholds {
+func averageNodeBasicresources(nodes []*v1.Node, overcommitRatios map[v1.ResourceName]float64, usageClient usageClient) api.ResourceThresholds {
 	total := api.ResourceThresholds{}
 	average := api.ResourceThresholds{}
 	numberOfNodes := len(nodes)
 	for _, node := range nodes {
-		pods, err := podutil.ListPodsOnANode(node.Name, getPodsAssignedToNode, nil)
-		if err != nil {
+		usage := usageClient.nodeUtilization(node.Name)
+		if usage == nil {
 			numberOfNodes--
 			continue
 		}
-		usage := nodeutil.NodeUtilization(pods, resourceNames)
-		nodeCapacity := node.Status.Capacity
-		if len(node.Status.Allocatable) > 0 {
-			nodeCapacity = node.Status.Allocatable
-		}
+		nodeCapacity := overcommittedCapacity(node, overcommitRatios)
 		for resource, value := range usage {
 			nodeCapacityValue := nodeCapacity[resource]
 			if resource == v1.ResourceCPU {
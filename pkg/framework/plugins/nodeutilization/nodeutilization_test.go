@@ -20,9 +20,15 @@ import (
 	"math"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/test"
 )
 
 var (
@@ -164,3 +170,139 @@ func TestSortNodesByUsageAscendingOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestClassifyNodes(t *testing.T) {
+	nodes := []*v1.Node{
+		test.BuildTestNode("n1", 1000, 3000, 10, nil),
+		test.BuildTestNode("n2", 1000, 3000, 10, func(node *v1.Node) {
+			node.Labels = map[string]string{"pool": "spot"}
+		}),
+		test.BuildTestNode("n3", 1000, 3000, 10, func(node *v1.Node) {
+			node.Labels = map[string]string{"pool": "memory"}
+		}),
+	}
+	pods := map[string][]*v1.Pod{
+		"n1": {test.BuildTestPod("p1", 100, 0, "n1", nil)},
+		"n2": {test.BuildTestPod("p2", 450, 0, "n2", nil)},
+		"n3": {test.BuildTestPod("p3", 800, 0, "n3", nil)},
+	}
+	getPodsAssignedToNode := func(nodeName string, filter podutil.FilterFunc) ([]*v1.Pod, error) {
+		return pods[nodeName], nil
+	}
+
+	tests := []struct {
+		name          string
+		args          runtime.Object
+		expectedLow   []string
+		expectedHigh  []string
+		expectedError bool
+	}{
+		{
+			name: "low node utilization",
+			args: &LowNodeUtilizationArgs{
+				Thresholds:       api.ResourceThresholds{v1.ResourceCPU: 20},
+				TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 60},
+			},
+			expectedLow:  []string{"n1"},
+			expectedHigh: []string{"n3"},
+		},
+		{
+			name: "high node utilization",
+			args: &HighNodeUtilizationArgs{
+				Thresholds: api.ResourceThresholds{v1.ResourceCPU: 50},
+			},
+			expectedLow:  []string{"n1", "n2"},
+			expectedHigh: []string{"n3"},
+		},
+		{
+			name: "low node utilization with node groups",
+			args: &LowNodeUtilizationArgs{
+				Thresholds:       api.ResourceThresholds{v1.ResourceCPU: 20},
+				TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 60},
+				NodeGroups: []NodeGroupThresholds{
+					{
+						NodeSelector: "pool=spot",
+						Thresholds:   api.ResourceThresholds{v1.ResourceCPU: 50},
+					},
+					{
+						NodeSelector:     "pool in (memory,compute)",
+						TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 90},
+					},
+					{
+						// n3 already matches the group above
+						NodeSelector:     "pool=memory",
+						TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 70},
+					},
+				},
+			},
+			expectedLow:  []string{"n1", "n2"},
+			expectedHigh: []string{},
+		},
+		{
+			name: "low node utilization with deviation thresholds per node group",
+			args: &LowNodeUtilizationArgs{
+				UseDeviationThresholds: true,
+				Thresholds:             api.ResourceThresholds{v1.ResourceCPU: 10},
+				TargetThresholds:       api.ResourceThresholds{v1.ResourceCPU: 10},
+				NodeGroups: []NodeGroupThresholds{
+					{
+						// n1 is the only node of the group and at the average of the group
+						NodeSelector: "!pool",
+					},
+				},
+			},
+			// the average of n2 and n3 is 62.5%
+			expectedLow:  []string{"n2"},
+			expectedHigh: []string{"n3"},
+		},
+		{
+			name: "high node utilization with node groups",
+			args: &HighNodeUtilizationArgs{
+				Thresholds: api.ResourceThresholds{v1.ResourceCPU: 50},
+				NodeGroups: []NodeGroupThresholds{
+					{
+						NodeSelector: "pool=memory",
+						Thresholds:   api.ResourceThresholds{v1.ResourceCPU: 90},
+					},
+				},
+			},
+			expectedLow:  []string{"n1", "n2", "n3"},
+			expectedHigh: []string{},
+		},
+		{
+			name: "invalid node group selector",
+			args: &LowNodeUtilizationArgs{
+				Thresholds:       api.ResourceThresholds{v1.ResourceCPU: 20},
+				TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 60},
+				NodeGroups:       []NodeGroupThresholds{{NodeSelector: "pool in spot"}},
+			},
+			expectedError: true,
+		},
+		{
+			name:          "unsupported args",
+			args:          &v1.Pod{},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			low, high, err := ClassifyNodes(tc.args, nodes, getPodsAssignedToNode)
+			if tc.expectedError {
+				if err == nil {
+					t.Fatalf("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedLow, low); diff != "" {
+				t.Errorf("Unexpected low nodes (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedHigh, high); diff != "" {
+				t.Errorf("Unexpected high nodes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
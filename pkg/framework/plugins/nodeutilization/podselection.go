@@ -0,0 +1,59 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	v1 "k8s.io/api/core/v1"
+
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/podsorting"
+)
+
+// sortPodsBySelectionStrategy orders the pods of the same priority by the strategy. The pods are
+// expected to be sorted by priority already, the pods the strategy considers equal keep their order.
+func sortPodsBySelectionStrategy(pods []*v1.Pod, strategy PodSelectionStrategy, nodeInfo NodeInfo, resourceNames []v1.ResourceName, usageClient usageClient) {
+	var less podutil.LessFunc
+	switch strategy {
+	case LargestFirst, SmallestFirst:
+		sizes := podSizes(pods, nodeInfo, resourceNames, usageClient)
+		if strategy == LargestFirst {
+			less = func(a, b *v1.Pod) bool { return sizes[a] > sizes[b] }
+		} else {
+			less = func(a, b *v1.Pod) bool { return sizes[a] < sizes[b] }
+		}
+	case YoungestFirst:
+		less = func(a, b *v1.Pod) bool { return b.CreationTimestamp.Before(&a.CreationTimestamp) }
+	case LowestDeletionCostFirst:
+		less = func(a, b *v1.Pod) bool { return podsorting.GetPodDeletionCost(a) < podsorting.GetPodDeletionCost(b) }
+	default:
+		return
+	}
+	podutil.SortPods(pods, podutil.WrapLessFuncs(podutil.LessByPriority, less))
+}
+
+// podSizes sizes the pods by their usage, by their requests when their usage is not known
+func podSizes(pods []*v1.Pod, nodeInfo NodeInfo, resourceNames []v1.ResourceName, usageClient usageClient) map[*v1.Pod]float64 {
+	return nodeutil.PodSizes(pods, nodeInfo.node, resourceNames, func(pod *v1.Pod) v1.ResourceList {
+		podUsage, _ := usageClient.podUsage(pod)
+		resources := v1.ResourceList{}
+		for _, name := range resourceNames {
+			resources[name] = *podQuantity(pod, podUsage, name)
+		}
+		return resources
+	})
+}
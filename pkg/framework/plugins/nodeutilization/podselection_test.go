@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/podsorting"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestSortPodsBySelectionStrategy(t *testing.T) {
+	node := test.BuildTestNode("n1", 4000, 3000, 10, nil)
+	created := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	buildPod := func(name string, milliCPU, memory int64, priority int32, age time.Duration, deletionCost string) *v1.Pod {
+		return test.BuildTestPod(name, milliCPU, memory, node.Name, func(pod *v1.Pod) {
+			test.SetPodPriority(pod, priority)
+			pod.CreationTimestamp = metav1.NewTime(created.Add(-age))
+			if deletionCost != "" {
+				pod.Annotations = map[string]string{podsorting.PodDeletionCostAnnotation: deletionCost}
+			}
+		})
+	}
+	newPods := func() []*v1.Pod {
+		return []*v1.Pod{
+			buildPod("small-old", 100, 0, 0, 3*time.Hour, "10"),
+			// takes most of the memory, the largest of the low priority pods
+			buildPod("large-memory", 100, 2000, 0, 2*time.Hour, "-5"),
+			buildPod("large-cpu", 1000, 0, 0, time.Hour, ""),
+			// always last, whatever its size, age and cost
+			buildPod("high-priority", 2000, 0, 100, 0, "-100"),
+		}
+	}
+
+	tests := []struct {
+		strategy PodSelectionStrategy
+		expected []string
+	}{
+		{
+			strategy: "",
+			expected: []string{"small-old", "large-memory", "large-cpu", "high-priority"},
+		},
+		{
+			strategy: LargestFirst,
+			expected: []string{"large-memory", "large-cpu", "small-old", "high-priority"},
+		},
+		{
+			strategy: SmallestFirst,
+			expected: []string{"small-old", "large-cpu", "large-memory", "high-priority"},
+		},
+		{
+			strategy: YoungestFirst,
+			expected: []string{"large-cpu", "large-memory", "small-old", "high-priority"},
+		},
+		{
+			strategy: LowestDeletionCostFirst,
+			expected: []string{"large-memory", "large-cpu", "small-old", "high-priority"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(string(tc.strategy), func(t *testing.T) {
+			pods := newPods()
+			getPodsAssignedToNode := func(nodeName string, filter podutil.FilterFunc) ([]*v1.Pod, error) {
+				return pods, nil
+			}
+			resourceNames := []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods}
+			usageClient := newRequestedUsageClient(resourceNames, getPodsAssignedToNode, "")
+
+			sortPodsBySelectionStrategy(pods, tc.strategy, NodeInfo{NodeUsage: NodeUsage{node: node}}, resourceNames, usageClient)
+
+			got := []string{}
+			for _, pod := range pods {
+				got = append(got, pod.Name)
+			}
+			if diff := cmp.Diff(tc.expected, got); diff != "" {
+				t.Errorf("Unexpected order of the pods (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
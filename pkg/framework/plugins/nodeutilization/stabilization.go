@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+)
+
+type nodeClass string
+
+const (
+	appropriatelyUtilized nodeClass = "appropriatelyUtilized"
+	underutilized         nodeClass = "underutilized"
+	overutilized          nodeClass = "overutilized"
+)
+
+// nodeClassRecord tells since when a node has been classified the same
+type nodeClassRecord struct {
+	class  nodeClass
+	since  time.Time
+	cycles int
+}
+
+// nodeStabilizer remembers the class of every node across descheduling cycles
+type nodeStabilizer struct {
+	sync.Mutex
+	stabilization Stabilization
+	nodes         map[string]nodeClassRecord
+	now           func() time.Time
+}
+
+// stabilizerFor returns the stabilizer of the plugin kept in the plugin state of the profile,
+// nil without stabilization
+func stabilizerFor(handle frameworktypes.Handle, pluginName string, stabilization *Stabilization) *nodeStabilizer {
+	if stabilization == nil {
+		return nil
+	}
+	return handle.PluginState().LoadOrStore(pluginName, func() interface{} {
+		return newNodeStabilizer(*stabilization)
+	}).(*nodeStabilizer)
+}
+
+func newNodeStabilizer(stabilization Stabilization) *nodeStabilizer {
+	return &nodeStabilizer{
+		stabilization: stabilization,
+		nodes:         map[string]nodeClassRecord{},
+		now:           time.Now,
+	}
+}
+
+// hysteresisThresholds returns the thresholds the nodes are classified with. The threshold a node
+// leaves its last class at is moved away from the class by the hysteresis margin. With lowThresholdOnly
+// the nodes not underutilized are the overutilized ones, as in HighNodeUtilization.
+func (s *nodeStabilizer) hysteresisThresholds(nodes []*v1.Node, nodeThresholds map[string]NodeThresholds, lowThresholdOnly bool) map[string]NodeThresholds {
+	if s == nil || s.stabilization.HysteresisMargin == 0 {
+		return nodeThresholds
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	classThresholds := make(map[string]NodeThresholds, len(nodeThresholds))
+	for name, thresholds := range nodeThresholds {
+		classThresholds[name] = thresholds
+	}
+	for _, node := range nodes {
+		thresholds, ok := nodeThresholds[node.Name]
+		if !ok {
+			continue
+		}
+		nodeCapacity := node.Status.Capacity
+		if len(node.Status.Allocatable) > 0 {
+			nodeCapacity = node.Status.Allocatable
+		}
+
+		switch s.nodes[node.Name].class {
+		case underutilized:
+			classThresholds[node.Name] = NodeThresholds{
+				lowResourceThreshold:  moveThresholds(thresholds.lowResourceThreshold, nodeCapacity, s.stabilization.HysteresisMargin, true),
+				highResourceThreshold: thresholds.highResourceThreshold,
+			}
+		case overutilized:
+			if lowThresholdOnly {
+				classThresholds[node.Name] = NodeThresholds{
+					lowResourceThreshold:  moveThresholds(thresholds.lowResourceThreshold, nodeCapacity, s.stabilization.HysteresisMargin, false),
+					highResourceThreshold: thresholds.highResourceThreshold,
+				}
+			} else {
+				classThresholds[node.Name] = NodeThresholds{
+					lowResourceThreshold:  thresholds.lowResourceThreshold,
+					highResourceThreshold: moveThresholds(thresholds.highResourceThreshold, nodeCapacity, s.stabilization.HysteresisMargin, false),
+				}
+			}
+		}
+	}
+	return classThresholds
+}
+
+// moveThresholds raises or lowers every threshold by the margin percentage of the node capacity
+func moveThresholds(thresholds map[v1.ResourceName]*resource.Quantity, nodeCapacity v1.ResourceList, margin api.Percentage, raise bool) map[v1.ResourceName]*resource.Quantity {
+	moved := make(map[v1.ResourceName]*resource.Quantity, len(thresholds))
+	for name, threshold := range thresholds {
+		delta := resourceThreshold(nodeCapacity, name, margin)
+		quantity := threshold.DeepCopy()
+		if raise {
+			quantity.Add(*delta)
+		} else if quantity.Cmp(*delta) > 0 {
+			quantity.Sub(*delta)
+		} else {
+			quantity.Set(0)
+		}
+		moved[name] = &quantity
+	}
+	return moved
+}
+
+// stabilize records the classes of the nodes and returns the underutilized and overutilized nodes
+// that have been classified the same for the configured cycles and window. The nodes missing
+// from the usages are forgotten.
+func (s *nodeStabilizer) stabilize(nodeUsages []NodeUsage, lowNodes, highNodes []NodeInfo) ([]NodeInfo, []NodeInfo) {
+	if s == nil {
+		return lowNodes, highNodes
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+
+	classes := make(map[string]nodeClass, len(nodeUsages))
+	for _, nodeUsage := range nodeUsages {
+		classes[nodeUsage.node.Name] = appropriatelyUtilized
+	}
+	for _, nodeInfo := range lowNodes {
+		classes[nodeInfo.node.Name] = underutilized
+	}
+	for _, nodeInfo := range highNodes {
+		classes[nodeInfo.node.Name] = overutilized
+	}
+
+	for name := range s.nodes {
+		if _, ok := classes[name]; !ok {
+			delete(s.nodes, name)
+		}
+	}
+	for name, class := range classes {
+		record, ok := s.nodes[name]
+		if ok && record.class == class {
+			record.cycles++
+		} else {
+			record = nodeClassRecord{class: class, since: now, cycles: 1}
+		}
+		s.nodes[name] = record
+	}
+
+	return s.stableNodes(lowNodes, now), s.stableNodes(highNodes, now)
+}
+
+func (s *nodeStabilizer) stableNodes(nodeInfos []NodeInfo, now time.Time) []NodeInfo {
+	stable := []NodeInfo{}
+	for _, nodeInfo := range nodeInfos {
+		record := s.nodes[nodeInfo.node.Name]
+		if record.cycles < s.stabilization.Cycles ||
+			(s.stabilization.Window != nil && now.Sub(record.since) < s.stabilization.Window.Duration) {
+			klog.V(2).InfoS("Node is not acted on until its classification is stable", "node", klog.KObj(nodeInfo.node), "class", record.class, "cycles", record.cycles, "since", record.since)
+			continue
+		}
+		stable = append(stable, nodeInfo)
+	}
+	return stable
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworkfake "github.com/amit3512/descheduler_policy_master/pkg/framework/fake"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestNodeStabilizer(t *testing.T) {
+	nodes := []*v1.Node{
+		test.BuildTestNode("n1", 1000, 3000, 10, nil),
+		test.BuildTestNode("n2", 1000, 3000, 10, nil),
+		test.BuildTestNode("n3", 1000, 3000, 10, nil),
+	}
+	start := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	// a descheduling cycle, with the cpu requested on every node
+	type cycle struct {
+		milliCPU     map[string]int64
+		after        time.Duration
+		expectedLow  []string
+		expectedHigh []string
+	}
+
+	tests := []struct {
+		name          string
+		stabilization Stabilization
+		cycles        []cycle
+	}{
+		{
+			name:          "nodes acted on after the configured cycles",
+			stabilization: Stabilization{Cycles: 2},
+			cycles: []cycle{
+				{
+					milliCPU:     map[string]int64{"n1": 100, "n2": 500, "n3": 800},
+					expectedLow:  []string{},
+					expectedHigh: []string{},
+				},
+				{
+					milliCPU:     map[string]int64{"n1": 100, "n2": 500, "n3": 800},
+					expectedLow:  []string{"n1"},
+					expectedHigh: []string{"n3"},
+				},
+				{
+					// a short spike starts the count again
+					milliCPU:     map[string]int64{"n1": 100, "n2": 700, "n3": 500},
+					expectedLow:  []string{"n1"},
+					expectedHigh: []string{},
+				},
+				{
+					milliCPU:     map[string]int64{"n1": 100, "n2": 700, "n3": 500},
+					expectedLow:  []string{"n1"},
+					expectedHigh: []string{"n2"},
+				},
+			},
+		},
+		{
+			name:          "nodes acted on after the window",
+			stabilization: Stabilization{Window: &metav1.Duration{Duration: 5 * time.Minute}},
+			cycles: []cycle{
+				{
+					milliCPU:     map[string]int64{"n1": 100, "n2": 500, "n3": 800},
+					expectedLow:  []string{},
+					expectedHigh: []string{},
+				},
+				{
+					after:        4 * time.Minute,
+					milliCPU:     map[string]int64{"n1": 100, "n2": 500, "n3": 800},
+					expectedLow:  []string{},
+					expectedHigh: []string{},
+				},
+				{
+					after:        5 * time.Minute,
+					milliCPU:     map[string]int64{"n1": 100, "n2": 500, "n3": 800},
+					expectedLow:  []string{"n1"},
+					expectedHigh: []string{"n3"},
+				},
+			},
+		},
+		{
+			name:          "nodes keep their class within the hysteresis margin",
+			stabilization: Stabilization{HysteresisMargin: 10},
+			cycles: []cycle{
+				{
+					milliCPU:     map[string]int64{"n1": 100, "n2": 500, "n3": 800},
+					expectedLow:  []string{"n1"},
+					expectedHigh: []string{"n3"},
+				},
+				{
+					// n1 is above the 20% threshold and n3 below the 60% target
+					// threshold, both by less than the margin
+					milliCPU:     map[string]int64{"n1": 250, "n2": 500, "n3": 550},
+					expectedLow:  []string{"n1"},
+					expectedHigh: []string{"n3"},
+				},
+				{
+					milliCPU:     map[string]int64{"n1": 350, "n2": 500, "n3": 450},
+					expectedLow:  []string{},
+					expectedHigh: []string{},
+				},
+				{
+					// n2 is not overutilized yet, entering a class takes no margin
+					milliCPU:     map[string]int64{"n1": 250, "n2": 650, "n3": 450},
+					expectedLow:  []string{},
+					expectedHigh: []string{"n2"},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			stabilizer := newNodeStabilizer(tc.stabilization)
+			now := start
+			for i, c := range tc.cycles {
+				now = now.Add(c.after)
+				pods := map[string][]*v1.Pod{}
+				for _, node := range nodes {
+					pods[node.Name] = []*v1.Pod{test.BuildTestPod("p-"+node.Name, c.milliCPU[node.Name], 0, node.Name, nil)}
+				}
+				getPodsAssignedToNode := func(nodeName string, filter podutil.FilterFunc) ([]*v1.Pod, error) {
+					return pods[nodeName], nil
+				}
+
+				thresholds, targetThresholds := api.ResourceThresholds{v1.ResourceCPU: 20}, api.ResourceThresholds{v1.ResourceCPU: 60}
+				SetDefaultForLNUThresholds(thresholds, targetThresholds, false)
+				resourceNames := getResourceNames(thresholds)
+				usageClient := newRequestedUsageClient(resourceNames, getPodsAssignedToNode, "")
+				if err := usageClient.sync(context.Background(), nodes); err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+
+				stabilizer.now = func() time.Time { return now }
+				lowNodes, highNodes := classifyNodesForLNU(nodes, thresholds, targetThresholds, nil, resourceNames, nil, false, usageClient, stabilizer)
+
+				if diff := cmp.Diff(c.expectedLow, nodeInfoNames(lowNodes)); diff != "" {
+					t.Errorf("Cycle %d: unexpected low nodes (-want +got):\n%s", i, diff)
+				}
+				if diff := cmp.Diff(c.expectedHigh, nodeInfoNames(highNodes)); diff != "" {
+					t.Errorf("Cycle %d: unexpected high nodes (-want +got):\n%s", i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestStabilizerFor(t *testing.T) {
+	stabilization := &Stabilization{Cycles: 2}
+	handle := &frameworkfake.HandleImpl{PluginStateImpl: frameworktypes.NewPluginState()}
+	if stabilizerFor(handle, LowNodeUtilizationPluginName, stabilization) != stabilizerFor(handle, LowNodeUtilizationPluginName, stabilization) {
+		t.Errorf("Expected the plugins built in successive cycles to share the stabilizer")
+	}
+	if stabilizerFor(handle, LowNodeUtilizationPluginName, stabilization) == stabilizerFor(handle, HighNodeUtilizationPluginName, stabilization) {
+		t.Errorf("Expected every plugin to have its own stabilizer")
+	}
+	otherHandle := &frameworkfake.HandleImpl{PluginStateImpl: frameworktypes.NewPluginState()}
+	if stabilizerFor(handle, LowNodeUtilizationPluginName, stabilization) == stabilizerFor(otherHandle, LowNodeUtilizationPluginName, stabilization) {
+		t.Errorf("Expected the plugins of every profile to have their own stabilizer")
+	}
+	if stabilizerFor(&frameworkfake.HandleImpl{}, LowNodeUtilizationPluginName, stabilization) == stabilizerFor(&frameworkfake.HandleImpl{}, LowNodeUtilizationPluginName, stabilization) {
+		t.Errorf("Expected no stabilizer to be kept without a plugin state")
+	}
+	if stabilizerFor(handle, LowNodeUtilizationPluginName, nil) != nil {
+		t.Errorf("Expected no stabilizer without stabilization")
+	}
+}
@@ -14,6 +14,7 @@ limitations under the License.
 package nodeutilization
 
 import (
+	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"github.com/amit3512/descheduler_policy_master/pkg/api"
 )
@@ -33,6 +34,30 @@ type LowNodeUtilizationArgs struct {
 	// considered while considering resources used by pods
 	// but then filtered out before eviction
 	EvictableNamespaces *api.Namespaces `json:"evictableNamespaces"`
+
+	// MetricsUtilization selects the actual resource usage as the node utilization
+	// instead of the resource requests of the pods
+	MetricsUtilization *MetricsUtilization `json:"metricsUtilization,omitempty"`
+
+	// NodeGroups override the thresholds of the nodes matching their node selector.
+	// The first matching group applies, the other nodes are classified with the
+	// thresholds above.
+	NodeGroups []NodeGroupThresholds `json:"nodeGroups,omitempty"`
+
+	// Stabilization only acts on the nodes classified the same for a while
+	Stabilization *Stabilization `json:"stabilization,omitempty"`
+
+	// PodSelectionStrategy orders the pods of the same priority evicted from a node
+	PodSelectionStrategy PodSelectionStrategy `json:"podSelectionStrategy,omitempty"`
+
+	// UtilizationMode selects the resources of the pods the utilization is computed from,
+	// the requests by default
+	UtilizationMode UtilizationMode `json:"utilizationMode,omitempty"`
+
+	// OvercommitRatios multiply the allocatable of the nodes the thresholds are computed from,
+	// e.g. with a cpu ratio of 2 a node with 4 allocatable cpus is 50% utilized with 4 cpus of limits.
+	// The ratios must be in (0, MaxOvercommitRatio].
+	OvercommitRatios map[v1.ResourceName]float64 `json:"overcommitRatios,omitempty"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -47,4 +72,128 @@ type HighNodeUtilizationArgs struct {
 	// considered while considering resources used by pods
 	// but then filtered out before eviction
 	EvictableNamespaces *api.Namespaces `json:"evictableNamespaces"`
+
+	// MetricsUtilization selects the actual resource usage as the node utilization
+	// instead of the resource requests of the pods
+	MetricsUtilization *MetricsUtilization `json:"metricsUtilization,omitempty"`
+
+	// NodeGroups override the thresholds of the nodes matching their node selector.
+	// The first matching group applies, the other nodes are classified with the
+	// thresholds above. Only the thresholds of a group can be set.
+	NodeGroups []NodeGroupThresholds `json:"nodeGroups,omitempty"`
+
+	// Stabilization only acts on the nodes classified the same for a while
+	Stabilization *Stabilization `json:"stabilization,omitempty"`
+
+	// PodSelectionStrategy orders the pods of the same priority evicted from a node
+	PodSelectionStrategy PodSelectionStrategy `json:"podSelectionStrategy,omitempty"`
+
+	// UtilizationMode selects the resources of the pods the utilization is computed from,
+	// the requests by default
+	UtilizationMode UtilizationMode `json:"utilizationMode,omitempty"`
+
+	// OvercommitRatios multiply the allocatable of the nodes the thresholds are computed from,
+	// e.g. with a cpu ratio of 2 a node with 4 allocatable cpus is 50% utilized with 4 cpus of limits.
+	// The ratios must be in (0, MaxOvercommitRatio].
+	OvercommitRatios map[v1.ResourceName]float64 `json:"overcommitRatios,omitempty"`
+
+	// NodeCost orders the underutilized nodes by their cost, the most expensive nodes are emptied first.
+	// The cost is also part of the score of the evictions.
+	NodeCost *NodeCost `json:"nodeCost,omitempty"`
+}
+
+// UtilizationMode is the resources of the pods a node is utilized by
+type UtilizationMode string
+
+const (
+	// Requests computes the utilization from the resource requests of the pods
+	Requests UtilizationMode = "Requests"
+	// Limits computes the utilization from the resource limits of the pods,
+	// the resources without a limit are not counted
+	Limits UtilizationMode = "Limits"
+	// MaxRequestsLimits computes the utilization from the larger of the resource
+	// requests and limits of every pod
+	MaxRequestsLimits UtilizationMode = "MaxRequestsLimits"
+)
+
+// PodSelectionStrategy is the order the pods of the same priority are evicted from a node in
+type PodSelectionStrategy string
+
+const (
+	// LargestFirst evicts the pods with the largest requests first, so the node gets below
+	// its thresholds with the fewest evictions
+	LargestFirst PodSelectionStrategy = "LargestFirst"
+	// SmallestFirst evicts the pods with the smallest requests first
+	SmallestFirst PodSelectionStrategy = "SmallestFirst"
+	// YoungestFirst evicts the most recently created pods first
+	YoungestFirst PodSelectionStrategy = "YoungestFirst"
+	// LowestDeletionCostFirst evicts the pods with the lowest
+	// controller.kubernetes.io/pod-deletion-cost annotation first
+	LowestDeletionCostFirst PodSelectionStrategy = "LowestDeletionCostFirst"
+)
+
+// NodeGroupThresholds are the thresholds of the nodes matching a node selector. The resources
+// a group does not configure keep the thresholds of the plugin.
+// +k8s:deepcopy-gen=true
+type NodeGroupThresholds struct {
+	// NodeSelector selects the nodes of the group, in the syntax of the policy-wide
+	// nodeSelector, e.g. "node.kubernetes.io/instance-type in (r6i.2xlarge,r6i.4xlarge)"
+	NodeSelector     string                 `json:"nodeSelector"`
+	Thresholds       api.ResourceThresholds `json:"thresholds,omitempty"`
+	TargetThresholds api.ResourceThresholds `json:"targetThresholds,omitempty"`
+}
+
+// Stabilization keeps the class of every node across descheduling cycles, so the nodes
+// briefly crossing the thresholds, e.g. during a rollout, are not acted on
+// +k8s:deepcopy-gen=true
+type Stabilization struct {
+	// Cycles is the number of consecutive descheduling cycles a node has to be classified
+	// the same before the pods are evicted from or moved to it
+	Cycles int `json:"cycles,omitempty"`
+	// Window is the duration a node has to be classified the same before the pods are
+	// evicted from or moved to it. Both the cycles and the window need to pass when set.
+	Window *metav1.Duration `json:"window,omitempty"`
+	// HysteresisMargin is the percentage a node has to get past the thresholds by to leave
+	// its class, e.g. with 5 a node overutilized at a 50% target threshold stays overutilized
+	// until its usage drops below 45%
+	HysteresisMargin api.Percentage `json:"hysteresisMargin,omitempty"`
+}
+
+// MetricsUtilization is the source of the actual resource usage of nodes and pods
+// +k8s:deepcopy-gen=true
+type MetricsUtilization struct {
+	// MetricsServer reads the cpu and memory usage from the metrics.k8s.io API,
+	// served e.g. by the kubernetes metrics server
+	MetricsServer bool `json:"metricsServer,omitempty"`
+	// Prometheus reads the utilization of a resource of the nodes from the result of a PromQL query
+	Prometheus *Prometheus `json:"prometheus,omitempty"`
+}
+
+// Prometheus configures the PromQL query the utilization of the nodes is read from
+// +k8s:deepcopy-gen=true
+type Prometheus struct {
+	// URL of the Prometheus HTTP API, e.g. http://prometheus.monitoring.svc:9090
+	URL string `json:"url,omitempty"`
+	// Query is evaluated once per descheduling cycle and must return an instant vector with
+	// a sample per node, the value being the utilization of the resource as a ratio in [0, 1],
+	// e.g. quantile_over_time(0.95, instance:node_cpu_utilisation:rate5m[15m])
+	Query string `json:"query,omitempty"`
+	// NodeLabel is the label of the samples holding the node name, defaults to "node"
+	NodeLabel string `json:"nodeLabel,omitempty"`
+	// Resource is the resource the query measures, defaults to cpu
+	Resource v1.ResourceName `json:"resource,omitempty"`
+}
+
+// NodeCost reads the cost of the nodes from a label or an annotation of the nodes
+// +k8s:deepcopy-gen=true
+type NodeCost struct {
+	// Label holding the cost of a node, e.g. an hourly price
+	Label string `json:"label,omitempty"`
+	// Annotation holding the cost of a node, only one of the label and the annotation can be set
+	Annotation string `json:"annotation,omitempty"`
+	// Values maps the values of the label or annotation to a cost, e.g. "on-demand": 3 and
+	// "spot": 1 for the karpenter.sh/capacity-type label. The values not mapped are parsed as numbers.
+	Values map[string]float64 `json:"values,omitempty"`
+	// Default is the cost of the nodes without a valid value
+	Default float64 `json:"default,omitempty"`
 }
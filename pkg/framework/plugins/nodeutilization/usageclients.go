@@ -0,0 +1,412 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
+
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/pkg/utils"
+)
+
+// usageClient provides the resource usage of nodes and pods the nodes are classified
+// and the pods are accounted with
+type usageClient interface {
+	// sync lists the pods of the nodes and refreshes their usage. Nodes whose usage
+	// is not available are left out.
+	sync(ctx context.Context, nodes []*v1.Node) error
+	nodeUtilization(node string) map[v1.ResourceName]*resource.Quantity
+	pods(node string) []*v1.Pod
+	podUsage(pod *v1.Pod) (map[v1.ResourceName]*resource.Quantity, error)
+}
+
+// newUsageClient returns the usage client selected by the metricsUtilization args, the
+// utilization mode only applies without metrics
+func newUsageClient(metricsUtilization *MetricsUtilization, mode UtilizationMode, resourceNames []v1.ResourceName, handle frameworktypes.Handle) usageClient {
+	if metricsUtilization != nil && metricsUtilization.MetricsServer {
+		return newActualUsageClient(resourceNames, handle.GetPodsAssignedToNodeFunc(), handle.MetricsClientSet())
+	}
+	if metricsUtilization != nil && metricsUtilization.Prometheus != nil {
+		return newPrometheusUsageClient(resourceNames, handle.GetPodsAssignedToNodeFunc(), metricsUtilization.Prometheus)
+	}
+	return newRequestedUsageClient(resourceNames, handle.GetPodsAssignedToNodeFunc(), mode)
+}
+
+// requestedUsageClient computes the usage from the resource requests of the pods,
+// or from their limits depending on the utilization mode
+type requestedUsageClient struct {
+	resourceNames         []v1.ResourceName
+	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc
+	mode                  UtilizationMode
+
+	_pods            map[string][]*v1.Pod
+	_nodeUtilization map[string]map[v1.ResourceName]*resource.Quantity
+}
+
+var _ usageClient = &requestedUsageClient{}
+
+func newRequestedUsageClient(resourceNames []v1.ResourceName, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc, mode UtilizationMode) *requestedUsageClient {
+	return &requestedUsageClient{
+		resourceNames:         resourceNames,
+		getPodsAssignedToNode: getPodsAssignedToNode,
+		mode:                  mode,
+	}
+}
+
+func (c *requestedUsageClient) nodeUtilization(node string) map[v1.ResourceName]*resource.Quantity {
+	return c._nodeUtilization[node]
+}
+
+func (c *requestedUsageClient) pods(node string) []*v1.Pod {
+	return c._pods[node]
+}
+
+func (c *requestedUsageClient) podUsage(pod *v1.Pod) (map[v1.ResourceName]*resource.Quantity, error) {
+	usage := make(map[v1.ResourceName]*resource.Quantity)
+	if c.mode == "" || c.mode == Requests {
+		for _, name := range c.resourceNames {
+			usage[name] = podRequest(pod, name)
+		}
+		return usage, nil
+	}
+
+	resources := podResources(pod, c.mode)
+	for _, name := range c.resourceNames {
+		if name == v1.ResourcePods {
+			usage[name] = resource.NewQuantity(1, resource.DecimalSI)
+			continue
+		}
+		quantity := resources[name]
+		usage[name] = &quantity
+	}
+	return usage, nil
+}
+
+func (c *requestedUsageClient) sync(ctx context.Context, nodes []*v1.Node) error {
+	c._pods = make(map[string][]*v1.Pod)
+	c._nodeUtilization = make(map[string]map[v1.ResourceName]*resource.Quantity)
+
+	for _, node := range nodes {
+		pods, err := podutil.ListPodsOnANode(node.Name, c.getPodsAssignedToNode, nil)
+		if err != nil {
+			klog.V(2).InfoS("Node will not be processed, error accessing its pods", "node", klog.KObj(node), "err", err)
+			continue
+		}
+
+		c._pods[node.Name] = pods
+		c._nodeUtilization[node.Name] = nodeutil.NodeUtilizationOf(pods, c.resourceNames, func(pod *v1.Pod) v1.ResourceList {
+			return podResources(pod, c.mode)
+		})
+	}
+
+	return nil
+}
+
+// podResources returns the resources of the pod the utilization is computed from in the mode
+func podResources(pod *v1.Pod, mode UtilizationMode) v1.ResourceList {
+	requests, limits := utils.PodRequestsAndLimits(pod)
+	switch mode {
+	case Limits:
+		return limits
+	case MaxRequestsLimits:
+		for name, limit := range limits {
+			if request, ok := requests[name]; !ok || limit.Cmp(request) > 0 {
+				requests[name] = limit
+			}
+		}
+	}
+	return requests
+}
+
+// actualUsageClient reads the cpu and memory usage of the nodes and pods from the
+// metrics.k8s.io API. Any other resource is accounted with the requests of the pods.
+type actualUsageClient struct {
+	resourceNames         []v1.ResourceName
+	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc
+	metricsClient         metricsclient.Interface
+
+	_pods            map[string][]*v1.Pod
+	_nodeUtilization map[string]map[v1.ResourceName]*resource.Quantity
+	_podUsage        map[string]v1.ResourceList
+}
+
+var _ usageClient = &actualUsageClient{}
+
+func newActualUsageClient(resourceNames []v1.ResourceName, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc, metricsClient metricsclient.Interface) *actualUsageClient {
+	return &actualUsageClient{
+		resourceNames:         resourceNames,
+		getPodsAssignedToNode: getPodsAssignedToNode,
+		metricsClient:         metricsClient,
+	}
+}
+
+func (c *actualUsageClient) nodeUtilization(node string) map[v1.ResourceName]*resource.Quantity {
+	return c._nodeUtilization[node]
+}
+
+func (c *actualUsageClient) pods(node string) []*v1.Pod {
+	return c._pods[node]
+}
+
+func (c *actualUsageClient) podUsage(pod *v1.Pod) (map[v1.ResourceName]*resource.Quantity, error) {
+	podUsage, ok := c._podUsage[pod.Namespace+"/"+pod.Name]
+	if !ok {
+		return nil, fmt.Errorf("no metrics of pod %s/%s", pod.Namespace, pod.Name)
+	}
+
+	usage := make(map[v1.ResourceName]*resource.Quantity)
+	for _, name := range c.resourceNames {
+		if quantity, ok := podUsage[name]; ok {
+			quantity = quantity.DeepCopy()
+			usage[name] = &quantity
+		} else {
+			usage[name] = podRequest(pod, name)
+		}
+	}
+	return usage, nil
+}
+
+func (c *actualUsageClient) sync(ctx context.Context, nodes []*v1.Node) error {
+	if c.metricsClient == nil {
+		return fmt.Errorf("metrics client not available, the metrics.k8s.io API can not be used")
+	}
+
+	c._pods = make(map[string][]*v1.Pod)
+	c._nodeUtilization = make(map[string]map[v1.ResourceName]*resource.Quantity)
+	c._podUsage = make(map[string]v1.ResourceList)
+
+	podMetricsList, err := c.metricsClient.MetricsV1beta1().PodMetricses(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list pod metrics: %v", err)
+	}
+	for _, podMetrics := range podMetricsList.Items {
+		podUsage := v1.ResourceList{}
+		for _, container := range podMetrics.Containers {
+			for name, quantity := range container.Usage {
+				total := podUsage[name]
+				total.Add(quantity)
+				podUsage[name] = total
+			}
+		}
+		c._podUsage[podMetrics.Namespace+"/"+podMetrics.Name] = podUsage
+	}
+
+	nodeMetricsList, err := c.metricsClient.MetricsV1beta1().NodeMetricses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list node metrics: %v", err)
+	}
+	nodeMetricses := make(map[string]v1.ResourceList, len(nodeMetricsList.Items))
+	for _, nodeMetrics := range nodeMetricsList.Items {
+		nodeMetricses[nodeMetrics.Name] = nodeMetrics.Usage
+	}
+
+	for _, node := range nodes {
+		pods, err := podutil.ListPodsOnANode(node.Name, c.getPodsAssignedToNode, nil)
+		if err != nil {
+			klog.V(2).InfoS("Node will not be processed, error accessing its pods", "node", klog.KObj(node), "err", err)
+			continue
+		}
+
+		nodeMetrics, ok := nodeMetricses[node.Name]
+		if !ok {
+			klog.V(2).InfoS("Node will not be processed, no metrics of the node", "node", klog.KObj(node))
+			continue
+		}
+
+		// the pods and the extended resources are still counted from the pods
+		nodeUsage := nodeutil.NodeUtilization(pods, c.resourceNames)
+		for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+			if quantity, ok := nodeMetrics[name]; ok {
+				quantity = quantity.DeepCopy()
+				nodeUsage[name] = &quantity
+			}
+		}
+
+		c._pods[node.Name] = pods
+		c._nodeUtilization[node.Name] = nodeUsage
+	}
+
+	return nil
+}
+
+const (
+	defaultPrometheusNodeLabel = "node"
+	prometheusQueryTimeout     = 30 * time.Second
+)
+
+// prometheusUsageClient reads the utilization of a single resource of the nodes from the
+// result of a PromQL query. The pods and any other resource are accounted with the requests
+// of the pods, the query says nothing about the usage of the individual pods.
+type prometheusUsageClient struct {
+	resourceNames         []v1.ResourceName
+	getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc
+	prometheus            *Prometheus
+
+	_pods            map[string][]*v1.Pod
+	_nodeUtilization map[string]map[v1.ResourceName]*resource.Quantity
+}
+
+var _ usageClient = &prometheusUsageClient{}
+
+func newPrometheusUsageClient(resourceNames []v1.ResourceName, getPodsAssignedToNode podutil.GetPodsAssignedToNodeFunc, prometheus *Prometheus) *prometheusUsageClient {
+	return &prometheusUsageClient{
+		resourceNames:         resourceNames,
+		getPodsAssignedToNode: getPodsAssignedToNode,
+		prometheus:            prometheus,
+	}
+}
+
+func (c *prometheusUsageClient) nodeUtilization(node string) map[v1.ResourceName]*resource.Quantity {
+	return c._nodeUtilization[node]
+}
+
+func (c *prometheusUsageClient) pods(node string) []*v1.Pod {
+	return c._pods[node]
+}
+
+func (c *prometheusUsageClient) podUsage(pod *v1.Pod) (map[v1.ResourceName]*resource.Quantity, error) {
+	usage := make(map[v1.ResourceName]*resource.Quantity)
+	for _, name := range c.resourceNames {
+		usage[name] = podRequest(pod, name)
+	}
+	return usage, nil
+}
+
+func (c *prometheusUsageClient) sync(ctx context.Context, nodes []*v1.Node) error {
+	nodeLabel := c.prometheus.NodeLabel
+	if nodeLabel == "" {
+		nodeLabel = defaultPrometheusNodeLabel
+	}
+	resourceName := c.prometheus.Resource
+	if resourceName == "" {
+		resourceName = v1.ResourceCPU
+	}
+	if !hasResourceName(c.resourceNames, resourceName) {
+		return fmt.Errorf("prometheus resource %v has no threshold", resourceName)
+	}
+
+	ratios, err := c.queryNodeRatios(ctx, nodeLabel)
+	if err != nil {
+		return err
+	}
+
+	c._pods = make(map[string][]*v1.Pod)
+	c._nodeUtilization = make(map[string]map[v1.ResourceName]*resource.Quantity)
+
+	for _, node := range nodes {
+		pods, err := podutil.ListPodsOnANode(node.Name, c.getPodsAssignedToNode, nil)
+		if err != nil {
+			klog.V(2).InfoS("Node will not be processed, error accessing its pods", "node", klog.KObj(node), "err", err)
+			continue
+		}
+
+		ratio, ok := ratios[node.Name]
+		if !ok {
+			klog.V(2).InfoS("Node will not be processed, the prometheus query returned no sample for it", "node", klog.KObj(node), "nodeLabel", nodeLabel)
+			continue
+		}
+
+		nodeCapacity := node.Status.Capacity
+		if len(node.Status.Allocatable) > 0 {
+			nodeCapacity = node.Status.Allocatable
+		}
+		capacity, ok := nodeCapacity[resourceName]
+		if !ok {
+			klog.V(2).InfoS("Node will not be processed, the resource measured by the prometheus query is not available", "node", klog.KObj(node), "resource", resourceName)
+			continue
+		}
+
+		// the pods and the other resources are still counted from the pods
+		nodeUsage := nodeutil.NodeUtilization(pods, c.resourceNames)
+		if resourceName == v1.ResourceCPU {
+			nodeUsage[resourceName] = resource.NewMilliQuantity(int64(ratio*float64(capacity.MilliValue())), capacity.Format)
+		} else {
+			nodeUsage[resourceName] = resource.NewQuantity(int64(ratio*float64(capacity.Value())), capacity.Format)
+		}
+
+		c._pods[node.Name] = pods
+		c._nodeUtilization[node.Name] = nodeUsage
+	}
+
+	return nil
+}
+
+// queryNodeRatios evaluates the query and returns the sample of every node, keyed by the node label
+func (c *prometheusUsageClient) queryNodeRatios(ctx context.Context, nodeLabel string) (map[string]float64, error) {
+	client, err := promapi.NewClient(promapi.Config{Address: c.prometheus.URL})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create prometheus client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, prometheusQueryTimeout)
+	defer cancel()
+	result, warnings, err := promv1.NewAPI(client).Query(ctx, c.prometheus.Query, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate prometheus query %q: %v", c.prometheus.Query, err)
+	}
+	if len(warnings) > 0 {
+		klog.V(2).InfoS("Prometheus query returned warnings", "query", c.prometheus.Query, "warnings", warnings)
+	}
+
+	vector, ok := result.(model.Vector)
+	if !ok {
+		return nil, fmt.Errorf("prometheus query %q returned a %v, an instant vector is expected", c.prometheus.Query, result.Type())
+	}
+
+	ratios := make(map[string]float64, len(vector))
+	for _, sample := range vector {
+		node := string(sample.Metric[model.LabelName(nodeLabel)])
+		value := float64(sample.Value)
+		if node == "" || math.IsNaN(value) || value < 0 {
+			klog.V(3).InfoS("Ignoring prometheus sample", "metric", sample.Metric.String(), "value", value)
+			continue
+		}
+		ratios[node] = value
+	}
+	return ratios, nil
+}
+
+func hasResourceName(resourceNames []v1.ResourceName, name v1.ResourceName) bool {
+	for _, resourceName := range resourceNames {
+		if resourceName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func podRequest(pod *v1.Pod, name v1.ResourceName) *resource.Quantity {
+	if name == v1.ResourcePods {
+		return resource.NewQuantity(1, resource.DecimalSI)
+	}
+	quantity := utils.GetResourceRequestQuantity(pod, name)
+	return &quantity
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeutilization
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+const testPrometheusQuery = "quantile_over_time(0.95, instance:node_cpu_utilisation:rate5m[15m])"
+
+// newPrometheusServer serves the query API of prometheus, answering testPrometheusQuery
+// with a sample per entry of samples, labeled with the node name under the node label
+func newPrometheusServer(t *testing.T, samples map[string]float64) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			http.NotFound(w, r)
+			return
+		}
+		if query := r.FormValue("query"); query != testPrometheusQuery {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"status":"error","errorType":"bad_data","error":"unexpected query %q"}`, query)
+			return
+		}
+
+		result := []interface{}{}
+		for node, value := range samples {
+			result = append(result, map[string]interface{}{
+				"metric": map[string]string{"__name__": "node_cpu", "node": node},
+				"value":  []interface{}{1700000000, strconv.FormatFloat(value, 'f', -1, 64)},
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"resultType": "vector", "result": result},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPrometheusUsageClient(t *testing.T) {
+	n1 := test.BuildTestNode("n1", 4000, 3000, 10, nil)
+	n2 := test.BuildTestNode("n2", 4000, 3000, 10, nil)
+	n3 := test.BuildTestNode("n3", 4000, 3000, 10, nil)
+	p1 := test.BuildTestPod("p1", 400, 100, n1.Name, test.SetRSOwnerRef)
+	p2 := test.BuildTestPod("p2", 400, 100, n2.Name, test.SetRSOwnerRef)
+
+	testCases := []struct {
+		name        string
+		query       string
+		resource    v1.ResourceName
+		samples     map[string]float64
+		expectedErr bool
+		// expectedUsage is in millicpu for cpu and in units for the other resources
+		expectedUsage map[string]int64
+	}{
+		{
+			name:  "a sample per node",
+			query: testPrometheusQuery,
+			samples: map[string]float64{
+				"n1": 0.75,
+				"n2": 0.1,
+			},
+			// n3 has no sample and is left out
+			expectedUsage: map[string]int64{
+				"n1": 3000,
+				"n2": 400,
+			},
+		},
+		{
+			name:     "memory measured by the query",
+			query:    testPrometheusQuery,
+			resource: v1.ResourceMemory,
+			samples: map[string]float64{
+				"n1": 0.5,
+			},
+			expectedUsage: map[string]int64{
+				"n1": 1500,
+			},
+		},
+		{
+			name:     "resource measured by the query without a threshold",
+			query:    testPrometheusQuery,
+			resource: v1.ResourceName("nvidia.com/gpu"),
+			samples: map[string]float64{
+				"n1": 0.5,
+			},
+			expectedErr: true,
+		},
+		{
+			name:        "query rejected by prometheus",
+			query:       "not_the_expected_query",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			fakeClient := fake.NewSimpleClientset([]runtime.Object{n1, n2, n3, p1, p2}...)
+			sharedInformerFactory := informers.NewSharedInformerFactory(fakeClient, 0)
+			getPodsAssignedToNode, err := podutil.BuildGetPodsAssignedToNodeFunc(sharedInformerFactory.Core().V1().Pods().Informer())
+			if err != nil {
+				t.Fatalf("Build get pods assigned to node function error: %v", err)
+			}
+			sharedInformerFactory.Start(ctx.Done())
+			sharedInformerFactory.WaitForCacheSync(ctx.Done())
+
+			server := newPrometheusServer(t, tc.samples)
+			resourceNames := []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory, v1.ResourcePods}
+			client := newPrometheusUsageClient(resourceNames, getPodsAssignedToNode, &Prometheus{URL: server.URL, Query: tc.query, Resource: tc.resource})
+
+			err = client.sync(ctx, []*v1.Node{n1, n2, n3})
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatalf("Expected the sync to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			for _, node := range []*v1.Node{n1, n2, n3} {
+				usage := client.nodeUtilization(node.Name)
+				expected, ok := tc.expectedUsage[node.Name]
+				if !ok {
+					if usage != nil {
+						t.Errorf("Expected node %v to be left out, got usage %v", node.Name, usage)
+					}
+					continue
+				}
+				if usage == nil {
+					t.Fatalf("Expected usage of node %v", node.Name)
+				}
+				if tc.resource == v1.ResourceMemory {
+					if got := usage[v1.ResourceMemory].Value(); got != expected {
+						t.Errorf("Expected memory usage %v on node %v, got %v", expected, node.Name, got)
+					}
+					// cpu is still counted from the pods
+					if got := usage[v1.ResourceCPU].MilliValue(); got != 400 {
+						t.Errorf("Expected 400 millicpu on node %v, got %v", node.Name, got)
+					}
+				} else {
+					if got := usage[v1.ResourceCPU].MilliValue(); got != expected {
+						t.Errorf("Expected %v millicpu on node %v, got %v", expected, node.Name, got)
+					}
+					// memory is still counted from the pods
+					if got := usage[v1.ResourceMemory].Value(); got != 100 {
+						t.Errorf("Expected memory usage 100 on node %v, got %v", node.Name, got)
+					}
+				}
+				// pods are always counted from the pods
+				if got := usage[v1.ResourcePods].Value(); got != 1 {
+					t.Errorf("Expected 1 pod on node %v, got %v", node.Name, got)
+				}
+			}
+
+			podUsage, err := client.podUsage(p1)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := podUsage[v1.ResourceCPU].MilliValue(); got != 400 {
+				t.Errorf("Expected the pod usage to be its request of 400 millicpu, got %v", got)
+			}
+		})
+	}
+}
+
+func TestPodResources(t *testing.T) {
+	pod := test.BuildTestPod("p1", 200, 100, "n1", func(pod *v1.Pod) {
+		pod.Spec.Containers[0].Resources.Limits[v1.ResourceCPU] = *resource.NewMilliQuantity(1000, resource.DecimalSI)
+	})
+
+	testCases := []struct {
+		mode     UtilizationMode
+		expected v1.ResourceList
+	}{
+		{
+			mode: Requests,
+			expected: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewMilliQuantity(200, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+		},
+		{
+			// the memory has no limit
+			mode: Limits,
+			expected: v1.ResourceList{
+				v1.ResourceCPU: *resource.NewMilliQuantity(1000, resource.DecimalSI),
+			},
+		},
+		{
+			mode: MaxRequestsLimits,
+			expected: v1.ResourceList{
+				v1.ResourceCPU:    *resource.NewMilliQuantity(1000, resource.DecimalSI),
+				v1.ResourceMemory: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.mode), func(t *testing.T) {
+			resources := podResources(pod, tc.mode)
+			if len(resources) != len(tc.expected) {
+				t.Fatalf("Expected resources %v, got %v", tc.expected, resources)
+			}
+			for name, expected := range tc.expected {
+				if got := resources[name]; got.Cmp(expected) != 0 {
+					t.Errorf("Expected %v of %v, got %v", name, expected.String(), got.String())
+				}
+			}
+		})
+	}
+}
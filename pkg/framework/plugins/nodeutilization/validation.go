@@ -15,9 +15,14 @@ package nodeutilization
 
 import (
 	"fmt"
+	"math"
+	"net/url"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"github.com/amit3512/descheduler_policy_master/pkg/api"
+	nodeutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/node"
 )
 
 func ValidateHighNodeUtilizationArgs(obj runtime.Object) error {
@@ -30,6 +35,32 @@ func ValidateHighNodeUtilizationArgs(obj runtime.Object) error {
 	if err != nil {
 		return err
 	}
+	if err := validateMetricsUtilization(args.MetricsUtilization, args.Thresholds); err != nil {
+		return err
+	}
+	if err := validateStabilization(args.Stabilization); err != nil {
+		return err
+	}
+	if err := validatePodSelectionStrategy(args.PodSelectionStrategy); err != nil {
+		return err
+	}
+	if err := validateUtilizationMode(args.UtilizationMode, args.MetricsUtilization); err != nil {
+		return err
+	}
+	if err := validateOvercommitRatios(args.OvercommitRatios); err != nil {
+		return err
+	}
+	if err := validateNodeCost(args.NodeCost); err != nil {
+		return err
+	}
+	for i, group := range args.NodeGroups {
+		if len(group.TargetThresholds) > 0 {
+			return fmt.Errorf("nodeGroups[%d]: targetThresholds can not be set", i)
+		}
+		if err := validateNodeGroup(group, args.Thresholds); err != nil {
+			return fmt.Errorf("nodeGroups[%d]: %v", i, err)
+		}
+	}
 
 	return nil
 }
@@ -44,6 +75,177 @@ func ValidateLowNodeUtilizationArgs(obj runtime.Object) error {
 	if err != nil {
 		return err
 	}
+	if err := validateMetricsUtilization(args.MetricsUtilization, args.Thresholds); err != nil {
+		return err
+	}
+	if err := validateStabilization(args.Stabilization); err != nil {
+		return err
+	}
+	if err := validatePodSelectionStrategy(args.PodSelectionStrategy); err != nil {
+		return err
+	}
+	if err := validateUtilizationMode(args.UtilizationMode, args.MetricsUtilization); err != nil {
+		return err
+	}
+	if err := validateOvercommitRatios(args.OvercommitRatios); err != nil {
+		return err
+	}
+	for i, group := range args.NodeGroups {
+		if err := validateNodeGroup(group, args.Thresholds); err != nil {
+			return fmt.Errorf("nodeGroups[%d]: %v", i, err)
+		}
+		if args.UseDeviationThresholds {
+			continue
+		}
+		// the resources the group does not configure keep the thresholds of the plugin
+		thresholds, targetThresholds := copyThresholds(args.Thresholds), copyThresholds(args.TargetThresholds)
+		SetDefaultForLNUThresholds(thresholds, targetThresholds, false)
+		thresholds, targetThresholds = mergeThresholds(thresholds, group.Thresholds), mergeThresholds(targetThresholds, group.TargetThresholds)
+		for resourceName, value := range thresholds {
+			if value > targetThresholds[resourceName] {
+				return fmt.Errorf("nodeGroups[%d]: thresholds' %v percentage is greater than targetThresholds'", i, resourceName)
+			}
+		}
+	}
+	return nil
+}
+
+func validateUtilizationMode(mode UtilizationMode, metricsUtilization *MetricsUtilization) error {
+	switch mode {
+	case "", Requests:
+		return nil
+	case Limits, MaxRequestsLimits:
+		if metricsUtilization != nil {
+			return fmt.Errorf("utilizationMode %v can not be set with metricsUtilization", mode)
+		}
+		return nil
+	}
+	return fmt.Errorf("utilizationMode %q is not supported, must be one of %v, %v or %v", mode, Requests, Limits, MaxRequestsLimits)
+}
+
+func validateOvercommitRatios(overcommitRatios map[v1.ResourceName]float64) error {
+	for name, ratio := range overcommitRatios {
+		if math.IsNaN(ratio) || math.IsInf(ratio, 0) {
+			return fmt.Errorf("overcommitRatios: %v ratio must be a finite number", name)
+		}
+		if ratio <= 0 {
+			return fmt.Errorf("overcommitRatios: %v ratio must be greater than 0", name)
+		}
+		if ratio > MaxOvercommitRatio {
+			return fmt.Errorf("overcommitRatios: %v ratio must not be greater than %v", name, MaxOvercommitRatio)
+		}
+	}
+	return nil
+}
+
+func validateNodeCost(nodeCost *NodeCost) error {
+	if nodeCost == nil {
+		return nil
+	}
+	if (nodeCost.Label == "") == (nodeCost.Annotation == "") {
+		return fmt.Errorf("nodeCost: exactly one of label and annotation must be set")
+	}
+	for value, cost := range nodeCost.Values {
+		if math.IsNaN(cost) || math.IsInf(cost, 0) {
+			return fmt.Errorf("nodeCost: cost of %q must be a finite number", value)
+		}
+		if cost < 0 {
+			return fmt.Errorf("nodeCost: cost of %q can not be negative", value)
+		}
+	}
+	if math.IsNaN(nodeCost.Default) || math.IsInf(nodeCost.Default, 0) {
+		return fmt.Errorf("nodeCost: default must be a finite number")
+	}
+	if nodeCost.Default < 0 {
+		return fmt.Errorf("nodeCost: default can not be negative")
+	}
+	return nil
+}
+
+func validatePodSelectionStrategy(strategy PodSelectionStrategy) error {
+	switch strategy {
+	case "", LargestFirst, SmallestFirst, YoungestFirst, LowestDeletionCostFirst:
+		return nil
+	}
+	return fmt.Errorf("podSelectionStrategy %q is not supported, must be one of %v, %v, %v or %v", strategy, LargestFirst, SmallestFirst, YoungestFirst, LowestDeletionCostFirst)
+}
+
+func validateStabilization(stabilization *Stabilization) error {
+	if stabilization == nil {
+		return nil
+	}
+	if stabilization.Cycles < 0 {
+		return fmt.Errorf("stabilization cycles can not be negative")
+	}
+	if stabilization.Window != nil && stabilization.Window.Duration < 0 {
+		return fmt.Errorf("stabilization window can not be negative")
+	}
+	if stabilization.HysteresisMargin < MinResourcePercentage || stabilization.HysteresisMargin > MaxResourcePercentage {
+		return fmt.Errorf("stabilization hysteresisMargin not in [%v, %v] range", MinResourcePercentage, MaxResourcePercentage)
+	}
+	return nil
+}
+
+// validateNodeGroup checks the node selector and the thresholds of a node group. Extended resources
+// can only be overridden when the thresholds of the plugin configure them.
+func validateNodeGroup(group NodeGroupThresholds, thresholds api.ResourceThresholds) error {
+	if group.NodeSelector == "" {
+		return fmt.Errorf("nodeSelector is not set")
+	}
+	if _, err := labels.Parse(group.NodeSelector); err != nil {
+		return fmt.Errorf("nodeSelector is not valid: %v", err)
+	}
+	if len(group.Thresholds) == 0 && len(group.TargetThresholds) == 0 {
+		return fmt.Errorf("no resource threshold is configured")
+	}
+	for _, groupThresholds := range []api.ResourceThresholds{group.Thresholds, group.TargetThresholds} {
+		for name, percent := range groupThresholds {
+			if _, ok := thresholds[name]; !ok && !nodeutil.IsBasicResource(name) {
+				return fmt.Errorf("%v is not configured in the thresholds of the plugin", name)
+			}
+			if percent < MinResourcePercentage || percent > MaxResourcePercentage {
+				return fmt.Errorf("%v threshold not in [%v, %v] range", name, MinResourcePercentage, MaxResourcePercentage)
+			}
+		}
+	}
+	return nil
+}
+
+// validateMetricsUtilization checks exactly one source of the actual resource usage is selected, if configured
+func validateMetricsUtilization(metricsUtilization *MetricsUtilization, thresholds api.ResourceThresholds) error {
+	if metricsUtilization == nil {
+		return nil
+	}
+	if !metricsUtilization.MetricsServer && metricsUtilization.Prometheus == nil {
+		return fmt.Errorf("metricsUtilization is configured without any source, either metricsServer or prometheus needs to be set")
+	}
+	if metricsUtilization.MetricsServer && metricsUtilization.Prometheus != nil {
+		return fmt.Errorf("metricsUtilization can only have one source, metricsServer and prometheus are both set")
+	}
+	if metricsUtilization.Prometheus != nil {
+		return validatePrometheus(metricsUtilization.Prometheus, thresholds)
+	}
+	return nil
+}
+
+func validatePrometheus(prometheus *Prometheus, thresholds api.ResourceThresholds) error {
+	if prometheus.URL == "" {
+		return fmt.Errorf("prometheus url is not set")
+	}
+	u, err := url.Parse(prometheus.URL)
+	if err != nil {
+		return fmt.Errorf("prometheus url is not valid: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("prometheus url scheme must be http or https, got %q", u.Scheme)
+	}
+	if prometheus.Query == "" {
+		return fmt.Errorf("prometheus query is not set")
+	}
+	// the pods, cpu and memory thresholds are defaulted, any other resource needs one to compare the usage to
+	if _, ok := thresholds[prometheus.Resource]; prometheus.Resource != "" && !nodeutil.IsBasicResource(prometheus.Resource) && !ok {
+		return fmt.Errorf("prometheus resource %v has no threshold", prometheus.Resource)
+	}
 	return nil
 }
 
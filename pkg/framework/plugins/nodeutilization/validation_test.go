@@ -18,19 +18,28 @@ package nodeutilization
 
 import (
 	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"github.com/amit3512/descheduler_policy_master/pkg/api"
 )
 
 func TestValidateLowNodeUtilizationPluginConfig(t *testing.T) {
 	extendedResource := v1.ResourceName("example.com/foo")
 	tests := []struct {
-		name             string
-		thresholds       api.ResourceThresholds
-		targetThresholds api.ResourceThresholds
-		errInfo          error
+		name               string
+		thresholds         api.ResourceThresholds
+		targetThresholds   api.ResourceThresholds
+		metricsUtilization *MetricsUtilization
+		nodeGroups         []NodeGroupThresholds
+		stabilization      *Stabilization
+		strategy           PodSelectionStrategy
+		utilizationMode    UtilizationMode
+		overcommitRatios   map[v1.ResourceName]float64
+		errInfo            error
 	}{
 		{
 			name: "passing invalid thresholds",
@@ -162,14 +171,339 @@ func TestValidateLowNodeUtilizationPluginConfig(t *testing.T) {
 			},
 			errInfo: nil,
 		},
+		{
+			name: "passing metricsUtilization without any source",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{},
+			errInfo:            fmt.Errorf("metricsUtilization is configured without any source, either metricsServer or prometheus needs to be set"),
+		},
+		{
+			name: "passing valid plugin config with metrics server",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{MetricsServer: true},
+			errInfo:            nil,
+		},
+		{
+			name: "passing metricsUtilization with both sources",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{
+				MetricsServer: true,
+				Prometheus:    &Prometheus{URL: "http://prometheus:9090", Query: "node_cpu_utilisation"},
+			},
+			errInfo: fmt.Errorf("metricsUtilization can only have one source, metricsServer and prometheus are both set"),
+		},
+		{
+			name: "passing prometheus without query",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{
+				Prometheus: &Prometheus{URL: "http://prometheus:9090"},
+			},
+			errInfo: fmt.Errorf("prometheus query is not set"),
+		},
+		{
+			name: "passing prometheus with invalid url scheme",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{
+				Prometheus: &Prometheus{URL: "prometheus:9090", Query: "node_cpu_utilisation"},
+			},
+			errInfo: fmt.Errorf("prometheus url scheme must be http or https, got \"prometheus\""),
+		},
+		{
+			name: "passing valid plugin config with prometheus",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{
+				Prometheus: &Prometheus{URL: "http://prometheus:9090", Query: "node_cpu_utilisation"},
+			},
+			errInfo: nil,
+		},
+		{
+			name: "passing prometheus measuring memory without a memory threshold",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{
+				Prometheus: &Prometheus{URL: "http://prometheus:9090", Query: "node_memory_utilisation", Resource: v1.ResourceMemory},
+			},
+			errInfo: nil,
+		},
+		{
+			name: "passing prometheus measuring an extended resource without a threshold",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{
+				Prometheus: &Prometheus{URL: "http://prometheus:9090", Query: "node_foo_utilisation", Resource: extendedResource},
+			},
+			errInfo: fmt.Errorf("prometheus resource example.com/foo has no threshold"),
+		},
+		{
+			name: "passing prometheus measuring an extended resource with a threshold",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU:   20,
+				extendedResource: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU:   80,
+				extendedResource: 80,
+			},
+			metricsUtilization: &MetricsUtilization{
+				Prometheus: &Prometheus{URL: "http://prometheus:9090", Query: "node_foo_utilisation", Resource: extendedResource},
+			},
+			errInfo: nil,
+		},
+		{
+			name: "passing valid node groups",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU:   20,
+				extendedResource: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU:   80,
+				extendedResource: 80,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{
+					NodeSelector:     "pool=memory",
+					Thresholds:       api.ResourceThresholds{v1.ResourceMemory: 40, extendedResource: 10},
+					TargetThresholds: api.ResourceThresholds{v1.ResourceMemory: 90},
+				},
+			},
+			errInfo: nil,
+		},
+		{
+			name: "passing node group without node selector",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{Thresholds: api.ResourceThresholds{v1.ResourceCPU: 10}},
+			},
+			errInfo: fmt.Errorf("nodeGroups[0]: nodeSelector is not set"),
+		},
+		{
+			name: "passing node group with extended resource not configured by the plugin",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{NodeSelector: "pool=gpu", Thresholds: api.ResourceThresholds{extendedResource: 10}},
+			},
+			errInfo: fmt.Errorf("nodeGroups[0]: example.com/foo is not configured in the thresholds of the plugin"),
+		},
+		{
+			name: "passing node group with thresholds greater than the target thresholds of the plugin",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{NodeSelector: "pool=spot", Thresholds: api.ResourceThresholds{v1.ResourceCPU: 90}},
+			},
+			errInfo: fmt.Errorf("nodeGroups[0]: thresholds' cpu percentage is greater than targetThresholds'"),
+		},
+		{
+			name: "passing valid stabilization",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			stabilization: &Stabilization{Cycles: 3, Window: &metav1.Duration{Duration: 10 * time.Minute}, HysteresisMargin: 5},
+			errInfo:       nil,
+		},
+		{
+			name: "passing negative stabilization window",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			stabilization: &Stabilization{Window: &metav1.Duration{Duration: -time.Minute}},
+			errInfo:       fmt.Errorf("stabilization window can not be negative"),
+		},
+		{
+			name: "passing stabilization hysteresisMargin out of range",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			stabilization: &Stabilization{HysteresisMargin: 120},
+			errInfo:       fmt.Errorf("stabilization hysteresisMargin not in [0, 100] range"),
+		},
+		{
+			name: "passing valid pod selection strategy",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			strategy: LargestFirst,
+			errInfo:  nil,
+		},
+		{
+			name: "passing unknown pod selection strategy",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			strategy: "OldestFirst",
+			errInfo:  fmt.Errorf(`podSelectionStrategy "OldestFirst" is not supported, must be one of LargestFirst, SmallestFirst, YoungestFirst or LowestDeletionCostFirst`),
+		},
+		{
+			name: "passing limits utilization mode with overcommit ratios",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			utilizationMode:  Limits,
+			overcommitRatios: map[v1.ResourceName]float64{v1.ResourceCPU: 2, v1.ResourceMemory: 1.2},
+			errInfo:          nil,
+		},
+		{
+			name: "passing unknown utilization mode",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			utilizationMode: "Usage",
+			errInfo:         fmt.Errorf(`utilizationMode "Usage" is not supported, must be one of Requests, Limits or MaxRequestsLimits`),
+		},
+		{
+			name: "passing limits utilization mode with metrics utilization",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			metricsUtilization: &MetricsUtilization{MetricsServer: true},
+			utilizationMode:    MaxRequestsLimits,
+			errInfo:            fmt.Errorf("utilizationMode MaxRequestsLimits can not be set with metricsUtilization"),
+		},
+		{
+			name: "passing zero overcommit ratio",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			overcommitRatios: map[v1.ResourceName]float64{v1.ResourceMemory: 0},
+			errInfo:          fmt.Errorf("overcommitRatios: memory ratio must be greater than 0"),
+		},
+		{
+			name: "passing NaN overcommit ratio",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			overcommitRatios: map[v1.ResourceName]float64{v1.ResourceMemory: math.NaN()},
+			errInfo:          fmt.Errorf("overcommitRatios: memory ratio must be a finite number"),
+		},
+		{
+			name: "passing infinite overcommit ratio",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			overcommitRatios: map[v1.ResourceName]float64{v1.ResourceMemory: math.Inf(1)},
+			errInfo:          fmt.Errorf("overcommitRatios: memory ratio must be a finite number"),
+		},
+		{
+			name: "passing overcommit ratio above the maximum",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			overcommitRatios: map[v1.ResourceName]float64{v1.ResourceMemory: MaxOvercommitRatio + 1},
+			errInfo:          fmt.Errorf("overcommitRatios: memory ratio must not be greater than 100"),
+		},
+		{
+			name: "passing maximum overcommit ratio",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			targetThresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 80,
+			},
+			overcommitRatios: map[v1.ResourceName]float64{v1.ResourceMemory: MaxOvercommitRatio},
+			errInfo:          nil,
+		},
 	}
 
 	for _, testCase := range tests {
 		args := &LowNodeUtilizationArgs{
-			Thresholds:       testCase.thresholds,
-			TargetThresholds: testCase.targetThresholds,
+			Thresholds:           testCase.thresholds,
+			TargetThresholds:     testCase.targetThresholds,
+			MetricsUtilization:   testCase.metricsUtilization,
+			NodeGroups:           testCase.nodeGroups,
+			Stabilization:        testCase.stabilization,
+			PodSelectionStrategy: testCase.strategy,
+			UtilizationMode:      testCase.utilizationMode,
+			OvercommitRatios:     testCase.overcommitRatios,
 		}
-		validateErr := validateLowNodeUtilizationThresholds(args.Thresholds, args.TargetThresholds, false)
+		validateErr := ValidateLowNodeUtilizationArgs(args)
 
 		if validateErr == nil || testCase.errInfo == nil {
 			if validateErr != testCase.errInfo {
@@ -182,3 +516,105 @@ func TestValidateLowNodeUtilizationPluginConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateHighNodeUtilizationPluginConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		thresholds api.ResourceThresholds
+		nodeGroups []NodeGroupThresholds
+		nodeCost   *NodeCost
+		errInfo    error
+	}{
+		{
+			name: "passing valid node groups",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{NodeSelector: "pool=spot", Thresholds: api.ResourceThresholds{v1.ResourceCPU: 40}},
+			},
+			errInfo: nil,
+		},
+		{
+			name: "passing node group with target thresholds",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{NodeSelector: "pool=spot", TargetThresholds: api.ResourceThresholds{v1.ResourceCPU: 40}},
+			},
+			errInfo: fmt.Errorf("nodeGroups[0]: targetThresholds can not be set"),
+		},
+		{
+			name: "passing node group with invalid node selector",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeGroups: []NodeGroupThresholds{
+				{NodeSelector: "pool in spot", Thresholds: api.ResourceThresholds{v1.ResourceCPU: 40}},
+			},
+			errInfo: fmt.Errorf("nodeGroups[0]: nodeSelector is not valid: unable to parse requirement: found 'spot' expected: '('"),
+		},
+		{
+			name: "passing valid node cost",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeCost: &NodeCost{
+				Label:  "karpenter.sh/capacity-type",
+				Values: map[string]float64{"on-demand": 3, "spot": 1},
+			},
+			errInfo: nil,
+		},
+		{
+			name: "passing node cost with label and annotation",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeCost: &NodeCost{
+				Label:      "karpenter.sh/capacity-type",
+				Annotation: "example.com/hourly-price",
+			},
+			errInfo: fmt.Errorf("nodeCost: exactly one of label and annotation must be set"),
+		},
+		{
+			name: "passing node cost with negative value",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeCost: &NodeCost{
+				Annotation: "example.com/hourly-price",
+				Values:     map[string]float64{"free": -1},
+			},
+			errInfo: fmt.Errorf("nodeCost: cost of \"free\" can not be negative"),
+		},
+		{
+			name: "passing node cost with infinite default",
+			thresholds: api.ResourceThresholds{
+				v1.ResourceCPU: 20,
+			},
+			nodeCost: &NodeCost{
+				Annotation: "example.com/hourly-price",
+				Default:    math.Inf(1),
+			},
+			errInfo: fmt.Errorf("nodeCost: default must be a finite number"),
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			validateErr := ValidateHighNodeUtilizationArgs(&HighNodeUtilizationArgs{
+				Thresholds: testCase.thresholds,
+				NodeGroups: testCase.nodeGroups,
+				NodeCost:   testCase.nodeCost,
+			})
+			if validateErr == nil || testCase.errInfo == nil {
+				if validateErr != testCase.errInfo {
+					t.Errorf("expected validity of plugin config to be %v but got %v instead", testCase.errInfo, validateErr)
+				}
+			} else if validateErr.Error() != testCase.errInfo.Error() {
+				t.Errorf("expected validity of plugin config to be %v but got %v instead", testCase.errInfo, validateErr)
+			}
+		})
+	}
+}
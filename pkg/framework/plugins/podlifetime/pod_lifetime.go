@@ -19,6 +19,7 @@ package podlifetime
 import (
 	"context"
 	"fmt"
+	"math"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -148,10 +149,11 @@ func (d *PodLifeTime) Deschedule(ctx context.Context, nodes []*v1.Node) *framewo
 	// Should sort Pods so that the oldest can be evicted first
 	// in the event that PDB or settings such maxNoOfPodsToEvictPer* prevent too much eviction
 	podutil.SortPodsBasedOnAge(podsToEvict)
+	d.handle.Sorter().Sort(podsToEvict)
 
 loop:
 	for _, pod := range podsToEvict {
-		err := d.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName})
+		err := d.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName, Score: d.score(pod)})
 		if err == nil {
 			continue
 		}
@@ -167,3 +169,16 @@ loop:
 
 	return nil
 }
+
+// score is the share of the age of the pod past the maximum lifetime, the pods running
+// the longest past it are worth evicting most. Without a maximum lifetime the pods are not scored.
+func (d *PodLifeTime) score(pod *v1.Pod) float64 {
+	if d.args.MaxPodLifeTimeSeconds == nil {
+		return 0
+	}
+	podAgeSeconds := metav1.Now().Sub(pod.GetCreationTimestamp().Local()).Seconds()
+	if podAgeSeconds <= 0 {
+		return 0
+	}
+	return math.Max(0, 1-float64(*d.args.MaxPodLifeTimeSeconds)/podAgeSeconds)
+}
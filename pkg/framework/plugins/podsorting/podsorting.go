@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podsorting
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/pkg/utils"
+)
+
+const (
+	SortByPriorityPluginName     = "SortByPriority"
+	SortByQoSClassPluginName     = "SortByQoSClass"
+	SortByAgePluginName          = "SortByAge"
+	SortByDeletionCostPluginName = "SortByDeletionCost"
+
+	// PodDeletionCostAnnotation is the annotation ReplicaSets consult when scaling down
+	PodDeletionCostAnnotation = "controller.kubernetes.io/pod-deletion-cost"
+)
+
+var (
+	_ frameworktypes.PreSortPlugin = &SortByPriority{}
+	_ frameworktypes.SortPlugin    = &SortByPriority{}
+	_ frameworktypes.PreSortPlugin = &SortByQoSClass{}
+	_ frameworktypes.SortPlugin    = &SortByQoSClass{}
+	_ frameworktypes.PreSortPlugin = &SortByAge{}
+	_ frameworktypes.SortPlugin    = &SortByAge{}
+	_ frameworktypes.PreSortPlugin = &SortByDeletionCost{}
+	_ frameworktypes.SortPlugin    = &SortByDeletionCost{}
+)
+
+// podSorter implements both the PreSort and the Sort extension points
+// with a single ordering so every sort plugin can be used in either
+type podSorter struct {
+	name string
+	less podutil.LessFunc
+}
+
+func newPodSorter(name string, args runtime.Object, less podutil.LessFunc) (podSorter, error) {
+	sortingArgs, ok := args.(*PodSortingArgs)
+	if !ok {
+		return podSorter{}, fmt.Errorf("want args to be of type PodSortingArgs, got %T", args)
+	}
+	if sortingArgs.Reverse {
+		return podSorter{name: name, less: func(a, b *v1.Pod) bool { return less(b, a) }}, nil
+	}
+	return podSorter{name: name, less: less}, nil
+}
+
+// Name retrieves the plugin name
+func (s *podSorter) Name() string {
+	return s.name
+}
+
+// PreLess orders pods before they are processed by a plugin
+func (s *podSorter) PreLess(a, b *v1.Pod) bool {
+	return s.less(a, b)
+}
+
+// Less orders pods right before they are evicted
+func (s *podSorter) Less(a, b *v1.Pod) bool {
+	return s.less(a, b)
+}
+
+// SortByPriority orders pods by priority, lowest first. Pods without priority come first.
+type SortByPriority struct {
+	podSorter
+}
+
+// NewSortByPriority builds plugin from its arguments while passing a handle
+func NewSortByPriority(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	sorter, err := newPodSorter(SortByPriorityPluginName, args, podutil.LessByPriority)
+	if err != nil {
+		return nil, err
+	}
+	return &SortByPriority{podSorter: sorter}, nil
+}
+
+// SortByQoSClass orders pods by QoS class: BestEffort, Burstable and Guaranteed last.
+type SortByQoSClass struct {
+	podSorter
+}
+
+// NewSortByQoSClass builds plugin from its arguments while passing a handle
+func NewSortByQoSClass(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	sorter, err := newPodSorter(SortByQoSClassPluginName, args, lessByQoSClass)
+	if err != nil {
+		return nil, err
+	}
+	return &SortByQoSClass{podSorter: sorter}, nil
+}
+
+func qosClassRank(pod *v1.Pod) int {
+	switch utils.GetPodQOS(pod) {
+	case v1.PodQOSBestEffort:
+		return 0
+	case v1.PodQOSBurstable:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func lessByQoSClass(a, b *v1.Pod) bool {
+	return qosClassRank(a) < qosClassRank(b)
+}
+
+// SortByAge orders pods by creation time, oldest first.
+type SortByAge struct {
+	podSorter
+}
+
+// NewSortByAge builds plugin from its arguments while passing a handle
+func NewSortByAge(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	sorter, err := newPodSorter(SortByAgePluginName, args, lessByAge)
+	if err != nil {
+		return nil, err
+	}
+	return &SortByAge{podSorter: sorter}, nil
+}
+
+func lessByAge(a, b *v1.Pod) bool {
+	return a.CreationTimestamp.Before(&b.CreationTimestamp)
+}
+
+// SortByDeletionCost orders pods by the controller.kubernetes.io/pod-deletion-cost
+// annotation, lowest cost first. Pods without a valid cost are treated as cost 0.
+type SortByDeletionCost struct {
+	podSorter
+}
+
+// NewSortByDeletionCost builds plugin from its arguments while passing a handle
+func NewSortByDeletionCost(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error) {
+	sorter, err := newPodSorter(SortByDeletionCostPluginName, args, lessByDeletionCost)
+	if err != nil {
+		return nil, err
+	}
+	return &SortByDeletionCost{podSorter: sorter}, nil
+}
+
+// GetPodDeletionCost returns the deletion cost of the pod, 0 when unset or invalid
+func GetPodDeletionCost(pod *v1.Pod) int32 {
+	value, ok := pod.Annotations[PodDeletionCostAnnotation]
+	if !ok {
+		return 0
+	}
+	cost, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0
+	}
+	return int32(cost)
+}
+
+func lessByDeletionCost(a, b *v1.Pod) bool {
+	return GetPodDeletionCost(a) < GetPodDeletionCost(b)
+}
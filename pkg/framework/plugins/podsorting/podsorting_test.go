@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package podsorting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
+	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
+	"github.com/amit3512/descheduler_policy_master/test"
+)
+
+func TestPodSorting(t *testing.T) {
+	n1 := test.BuildTestNode("n1", 4000, 3000, 10, nil)
+	now := time.Now()
+
+	// Guaranteed, high priority, created first, high deletion cost
+	p1 := test.BuildTestPod("p1", 100, 100, n1.Name, func(pod *v1.Pod) {
+		test.SetPodPriority(pod, 1000)
+		test.MakeGuaranteedPod(pod)
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-3 * time.Hour))
+		pod.Annotations = map[string]string{PodDeletionCostAnnotation: "100"}
+	})
+	// BestEffort, no priority, created last, invalid deletion cost
+	p2 := test.BuildTestPod("p2", 0, 0, n1.Name, func(pod *v1.Pod) {
+		test.MakeBestEffortPod(pod)
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-1 * time.Hour))
+		pod.Annotations = map[string]string{PodDeletionCostAnnotation: "invalid"}
+	})
+	// Burstable, low priority, negative deletion cost
+	p3 := test.BuildTestPod("p3", 100, 0, n1.Name, func(pod *v1.Pod) {
+		test.SetPodPriority(pod, 0)
+		test.MakeBurstablePod(pod)
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+		pod.Annotations = map[string]string{PodDeletionCostAnnotation: "-10"}
+	})
+
+	tests := []struct {
+		description string
+		builder     func(args runtime.Object, handle frameworktypes.Handle) (frameworktypes.Plugin, error)
+		args        *PodSortingArgs
+		expected    []string
+	}{
+		{
+			description: "sort by priority",
+			builder:     NewSortByPriority,
+			args:        &PodSortingArgs{},
+			expected:    []string{"p2", "p3", "p1"},
+		},
+		{
+			description: "sort by priority reversed",
+			builder:     NewSortByPriority,
+			args:        &PodSortingArgs{Reverse: true},
+			expected:    []string{"p1", "p3", "p2"},
+		},
+		{
+			description: "sort by QoS class",
+			builder:     NewSortByQoSClass,
+			args:        &PodSortingArgs{},
+			expected:    []string{"p2", "p3", "p1"},
+		},
+		{
+			description: "sort by age",
+			builder:     NewSortByAge,
+			args:        &PodSortingArgs{},
+			expected:    []string{"p1", "p3", "p2"},
+		},
+		{
+			description: "sort by deletion cost",
+			builder:     NewSortByDeletionCost,
+			args:        &PodSortingArgs{},
+			expected:    []string{"p3", "p2", "p1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			plugin, err := tc.builder(tc.args, nil)
+			if err != nil {
+				t.Fatalf("Unable to initialize the plugin: %v", err)
+			}
+
+			for _, extensionPoint := range []frameworktypes.ExtensionPoint{frameworktypes.PreSortExtensionPoint, frameworktypes.SortExtensionPoint} {
+				pods := []*v1.Pod{p1, p2, p3}
+				if extensionPoint == frameworktypes.PreSortExtensionPoint {
+					podutil.SortPods(pods, plugin.(frameworktypes.PreSortPlugin).PreLess)
+				} else {
+					podutil.SortPods(pods, plugin.(frameworktypes.SortPlugin).Less)
+				}
+				names := []string{}
+				for _, pod := range pods {
+					names = append(names, pod.Name)
+				}
+				if diff := cmp.Diff(tc.expected, names); diff != "" {
+					t.Errorf("%v: unexpected pod order (-want +got):\n%s", extensionPoint, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestPodSortingArgsType(t *testing.T) {
+	if _, err := NewSortByAge(&metav1.Status{}, nil); err == nil {
+		t.Errorf("Expected an error for args of unexpected type")
+	}
+}
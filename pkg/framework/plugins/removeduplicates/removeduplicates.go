@@ -110,6 +110,7 @@ func (r *RemoveDuplicates) Balance(ctx context.Context, nodes []*v1.Node) *frame
 			klog.ErrorS(err, "Error listing evictable pods on node", "node", klog.KObj(node))
 			continue
 		}
+		r.handle.Sorter().PreSort(pods)
 		nodeMap[node.Name] = node
 		nodeCount++
 		// Each pod has a list of owners and a list of containers, and each container has 1 image spec.
@@ -209,8 +210,10 @@ func (r *RemoveDuplicates) Balance(ctx context.Context, nodes []*v1.Node) *frame
 			if len(pods)+1 > upperAvg {
 				// It's assumed all duplicated pods are in the same priority class
 				// TODO(jchaloup): check if the pod has a different node to lend to
-				for _, pod := range pods[upperAvg-1:] {
-					err := r.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName})
+				podsToEvict := append([]*v1.Pod{}, pods[upperAvg-1:]...)
+				r.handle.Sorter().Sort(podsToEvict)
+				for _, pod := range podsToEvict {
+					err := r.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName, Score: evictions.MaxScore})
 					if err == nil {
 						continue
 					}
@@ -101,10 +101,11 @@ func (d *RemoveFailedPods) Deschedule(ctx context.Context, nodes []*v1.Node) *fr
 				Err: fmt.Errorf("error listing pods on a node: %v", err),
 			}
 		}
+		d.handle.Sorter().Sort(pods)
 		totalPods := len(pods)
 	loop:
 		for i := 0; i < totalPods; i++ {
-			err := d.handle.Evictor().Evict(ctx, pods[i], evictions.EvictOptions{StrategyName: PluginName})
+			err := d.handle.Evictor().Evict(ctx, pods[i], evictions.EvictOptions{StrategyName: PluginName, Score: evictions.MaxScore})
 			if err == nil {
 				continue
 			}
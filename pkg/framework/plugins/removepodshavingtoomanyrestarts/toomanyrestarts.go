@@ -19,6 +19,7 @@ package removepodshavingtoomanyrestarts
 import (
 	"context"
 	"fmt"
+	"math"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -121,10 +122,11 @@ func (d *RemovePodsHavingTooManyRestarts) Deschedule(ctx context.Context, nodes
 				Err: fmt.Errorf("error listing pods on a node: %v", err),
 			}
 		}
+		d.handle.Sorter().Sort(pods)
 		totalPods := len(pods)
 	loop:
 		for i := 0; i < totalPods; i++ {
-			err := d.handle.Evictor().Evict(ctx, pods[i], evictions.EvictOptions{StrategyName: PluginName})
+			err := d.handle.Evictor().Evict(ctx, pods[i], evictions.EvictOptions{StrategyName: PluginName, Score: d.score(pods[i])})
 			if err == nil {
 				continue
 			}
@@ -165,3 +167,16 @@ func calcContainerRestartsFromStatuses(statuses []v1.ContainerStatus) int32 {
 	}
 	return restarts
 }
+
+// score is the share of the restarts of the pod past the threshold, the pods restarting
+// the most are worth evicting most
+func (d *RemovePodsHavingTooManyRestarts) score(pod *v1.Pod) float64 {
+	restarts := calcContainerRestartsFromStatuses(pod.Status.ContainerStatuses)
+	if d.args.IncludingInitContainers {
+		restarts += calcContainerRestartsFromStatuses(pod.Status.InitContainerStatuses)
+	}
+	if restarts <= 0 {
+		return 0
+	}
+	return math.Max(0, 1-float64(d.args.PodRestartThreshold)/float64(restarts))
+}
@@ -133,11 +133,12 @@ func (d *RemovePodsViolatingNodeAffinity) processNodes(ctx context.Context, node
 				Err: fmt.Errorf("error listing pods on a node: %v", err),
 			}
 		}
+		d.handle.Sorter().Sort(pods)
 
 	loop:
 		for _, pod := range pods {
 			klog.V(1).InfoS("Evicting pod", "pod", klog.KObj(pod))
-			err := d.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName})
+			err := d.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName, Score: evictions.MaxScore})
 			if err == nil {
 				continue
 			}
@@ -113,6 +113,7 @@ func (d *RemovePodsViolatingNodeTaints) Deschedule(ctx context.Context, nodes []
 				Err: fmt.Errorf("error listing pods on a node: %v", err),
 			}
 		}
+		d.handle.Sorter().Sort(pods)
 		totalPods := len(pods)
 	loop:
 		for i := 0; i < totalPods; i++ {
@@ -122,7 +123,7 @@ func (d *RemovePodsViolatingNodeTaints) Deschedule(ctx context.Context, nodes []
 				d.taintFilterFnc,
 			) {
 				klog.V(2).InfoS("Not all taints with NoSchedule effect are tolerated after update for pod on node", "pod", klog.KObj(pods[i]), "node", klog.KObj(node))
-				err := d.handle.Evictor().Evict(ctx, pods[i], evictions.EvictOptions{StrategyName: PluginName})
+				err := d.handle.Evictor().Evict(ctx, pods[i], evictions.EvictOptions{StrategyName: PluginName, Score: evictions.MaxScore})
 				if err == nil {
 					continue
 				}
@@ -225,8 +225,14 @@ func (d *RemovePodsViolatingTopologySpreadConstraint) Balance(ctx context.Contex
 		}
 	}
 
-	nodeLimitExceeded := map[string]bool{}
+	podsToEvict := make([]*v1.Pod, 0, len(podsForEviction))
 	for pod := range podsForEviction {
+		podsToEvict = append(podsToEvict, pod)
+	}
+	d.handle.Sorter().Sort(podsToEvict)
+
+	nodeLimitExceeded := map[string]bool{}
+	for _, pod := range podsToEvict {
 		if nodeLimitExceeded[pod.Spec.NodeName] {
 			continue
 		}
@@ -235,7 +241,7 @@ func (d *RemovePodsViolatingTopologySpreadConstraint) Balance(ctx context.Contex
 		}
 
 		if d.handle.Evictor().PreEvictionFilter(pod) {
-			err := d.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName})
+			err := d.handle.Evictor().Evict(ctx, pod, evictions.EvictOptions{StrategyName: PluginName, Score: evictions.MaxScore})
 			if err == nil {
 				continue
 			}
@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"k8s.io/klog/v2"
 )
@@ -42,6 +43,7 @@ import (
 type evictorImpl struct {
 	profileName       string
 	podEvictor        *evictions.PodEvictor
+	evictionPlan      *evictions.EvictionPlan
 	filter            podutil.FilterFunc
 	preEvictionFilter podutil.FilterFunc
 }
@@ -58,18 +60,58 @@ func (ei *evictorImpl) PreEvictionFilter(pod *v1.Pod) bool {
 	return ei.preEvictionFilter(pod)
 }
 
-// Evict evicts a pod (no pre-check performed)
+// Evict evicts a pod (no pre-check performed).
+// With an eviction plan the pod is only proposed for eviction.
 func (ei *evictorImpl) Evict(ctx context.Context, pod *v1.Pod, opts evictions.EvictOptions) error {
 	opts.ProfileName = ei.profileName
+	if ei.evictionPlan != nil {
+		return ei.evictionPlan.Propose(pod, opts)
+	}
 	return ei.podEvictor.EvictPod(ctx, pod, opts)
 }
 
+// CanEvict checks the pods can be evicted, or proposed with an eviction plan, within the limits
+func (ei *evictorImpl) CanEvict(pods []*v1.Pod) error {
+	if ei.evictionPlan != nil {
+		return ei.evictionPlan.CanPropose(pods)
+	}
+	return ei.podEvictor.CanEvict(pods)
+}
+
+// Planning tells whether the pods are only proposed for eviction
+func (ei *evictorImpl) Planning() bool {
+	return ei.evictionPlan != nil
+}
+
+// sorterImpl implements the Sorter interface so plugins
+// can sort pods without knowing which sort plugins are enabled
+type sorterImpl struct {
+	preLess podutil.LessFunc
+	less    podutil.LessFunc
+}
+
+var _ frameworktypes.Sorter = &sorterImpl{}
+
+// PreSort sorts pods before they are processed
+func (si *sorterImpl) PreSort(pods []*v1.Pod) {
+	podutil.SortPods(pods, si.preLess)
+}
+
+// Sort sorts pods right before they are evicted
+func (si *sorterImpl) Sort(pods []*v1.Pod) {
+	podutil.SortPods(pods, si.less)
+}
+
 // handleImpl implements the framework handle which gets passed to plugins
 type handleImpl struct {
 	clientSet                 clientset.Interface
+	metricsClientSet          metricsclient.Interface
 	getPodsAssignedToNodeFunc podutil.GetPodsAssignedToNodeFunc
 	sharedInformerFactory     informers.SharedInformerFactory
 	evictor                   *evictorImpl
+	sorter                    *sorterImpl
+	pluginState               *frameworktypes.PluginState
+	ctx                       context.Context
 }
 
 var _ frameworktypes.Handle = &handleImpl{}
@@ -79,6 +121,11 @@ func (hi *handleImpl) ClientSet() clientset.Interface {
 	return hi.clientSet
 }
 
+// MetricsClientSet retrieves the metrics.k8s.io client set, if any
+func (hi *handleImpl) MetricsClientSet() metricsclient.Interface {
+	return hi.metricsClientSet
+}
+
 // GetPodsAssignedToNodeFunc retrieves GetPodsAssignedToNodeFunc implementation
 func (hi *handleImpl) GetPodsAssignedToNodeFunc() podutil.GetPodsAssignedToNodeFunc {
 	return hi.getPodsAssignedToNodeFunc
@@ -94,6 +141,24 @@ func (hi *handleImpl) Evictor() frameworktypes.Evictor {
 	return hi.evictor
 }
 
+// Sorter retrieves sorter so plugins can order pods as configured in the profile
+func (hi *handleImpl) Sorter() frameworktypes.Sorter {
+	return hi.sorter
+}
+
+// PluginState retrieves the state the plugins keep across descheduling cycles
+func (hi *handleImpl) PluginState() *frameworktypes.PluginState {
+	return hi.pluginState
+}
+
+// Context retrieves the context of the descheduling cycle, the background context when not set
+func (hi *handleImpl) Context() context.Context {
+	if hi.ctx == nil {
+		return context.Background()
+	}
+	return hi.ctx
+}
+
 type filterPlugin interface {
 	frameworktypes.Plugin
 	Filter(pod *v1.Pod) bool
@@ -108,12 +173,16 @@ type profileImpl struct {
 	profileName string
 	podEvictor  *evictions.PodEvictor
 
+	preSortPlugins           []frameworktypes.PreSortPlugin
+	sortPlugins              []frameworktypes.SortPlugin
 	deschedulePlugins        []frameworktypes.DeschedulePlugin
 	balancePlugins           []frameworktypes.BalancePlugin
 	filterPlugins            []filterPlugin
 	preEvictionFilterPlugins []preEvictionFilterPlugin
 
 	// Each extension point with a list of plugins implementing the extension point.
+	preSort           sets.Set[string]
+	sort              sets.Set[string]
 	deschedule        sets.Set[string]
 	balance           sets.Set[string]
 	filter            sets.Set[string]
@@ -125,9 +194,13 @@ type Option func(*handleImplOpts)
 
 type handleImplOpts struct {
 	clientSet                 clientset.Interface
+	metricsClientSet          metricsclient.Interface
 	sharedInformerFactory     informers.SharedInformerFactory
 	getPodsAssignedToNodeFunc podutil.GetPodsAssignedToNodeFunc
 	podEvictor                *evictions.PodEvictor
+	evictionPlan              *evictions.EvictionPlan
+	pluginState               *frameworktypes.PluginState
+	ctx                       context.Context
 }
 
 // WithClientSet sets clientSet for the scheduling frameworkImpl.
@@ -137,6 +210,13 @@ func WithClientSet(clientSet clientset.Interface) Option {
 	}
 }
 
+// WithMetricsClientSet sets the client plugins read the actual resource usage with.
+func WithMetricsClientSet(metricsClientSet metricsclient.Interface) Option {
+	return func(o *handleImplOpts) {
+		o.metricsClientSet = metricsClientSet
+	}
+}
+
 func WithSharedInformerFactory(sharedInformerFactory informers.SharedInformerFactory) Option {
 	return func(o *handleImplOpts) {
 		o.sharedInformerFactory = sharedInformerFactory
@@ -149,6 +229,27 @@ func WithPodEvictor(podEvictor *evictions.PodEvictor) Option {
 	}
 }
 
+// WithEvictionPlan makes plugins propose pods into the plan instead of evicting them.
+func WithEvictionPlan(evictionPlan *evictions.EvictionPlan) Option {
+	return func(o *handleImplOpts) {
+		o.evictionPlan = evictionPlan
+	}
+}
+
+// WithPluginState sets the state the plugins keep across descheduling cycles, none is kept without it.
+func WithPluginState(pluginState *frameworktypes.PluginState) Option {
+	return func(o *handleImplOpts) {
+		o.pluginState = pluginState
+	}
+}
+
+// WithContext sets the context of the descheduling cycle the plugins run in
+func WithContext(ctx context.Context) Option {
+	return func(o *handleImplOpts) {
+		o.ctx = ctx
+	}
+}
+
 func WithGetPodsAssignedToNodeFnc(getPodsAssignedToNodeFunc podutil.GetPodsAssignedToNodeFunc) Option {
 	return func(o *handleImplOpts) {
 		o.getPodsAssignedToNodeFunc = getPodsAssignedToNodeFunc
@@ -185,12 +286,20 @@ func buildPlugin(config api.DeschedulerProfile, pluginName string, handle *handl
 }
 
 func (p *profileImpl) registryToExtensionPoints(registry pluginregistry.Registry) {
+	p.preSort = sets.New[string]()
+	p.sort = sets.New[string]()
 	p.deschedule = sets.New[string]()
 	p.balance = sets.New[string]()
 	p.filter = sets.New[string]()
 	p.preEvictionFilter = sets.New[string]()
 
 	for plugin, pluginUtilities := range registry {
+		if _, ok := pluginUtilities.PluginType.(frameworktypes.PreSortPlugin); ok {
+			p.preSort.Insert(plugin)
+		}
+		if _, ok := pluginUtilities.PluginType.(frameworktypes.SortPlugin); ok {
+			p.sort.Insert(plugin)
+		}
 		if _, ok := pluginUtilities.PluginType.(frameworktypes.DeschedulePlugin); ok {
 			p.deschedule.Insert(plugin)
 		}
@@ -225,6 +334,8 @@ func NewProfile(config api.DeschedulerProfile, reg pluginregistry.Registry, opts
 	pi := &profileImpl{
 		profileName:              config.Name,
 		podEvictor:               hOpts.podEvictor,
+		preSortPlugins:           []frameworktypes.PreSortPlugin{},
+		sortPlugins:              []frameworktypes.SortPlugin{},
 		deschedulePlugins:        []frameworktypes.DeschedulePlugin{},
 		balancePlugins:           []frameworktypes.BalancePlugin{},
 		filterPlugins:            []filterPlugin{},
@@ -232,6 +343,12 @@ func NewProfile(config api.DeschedulerProfile, reg pluginregistry.Registry, opts
 	}
 	pi.registryToExtensionPoints(reg)
 
+	if !pi.preSort.HasAll(config.Plugins.PreSort.Enabled...) {
+		return nil, fmt.Errorf("profile %q configures preSort extension point of non-existing plugins: %v", config.Name, sets.New(config.Plugins.PreSort.Enabled...).Difference(pi.preSort))
+	}
+	if !pi.sort.HasAll(config.Plugins.Sort.Enabled...) {
+		return nil, fmt.Errorf("profile %q configures sort extension point of non-existing plugins: %v", config.Name, sets.New(config.Plugins.Sort.Enabled...).Difference(pi.sort))
+	}
 	if !pi.deschedule.HasAll(config.Plugins.Deschedule.Enabled...) {
 		return nil, fmt.Errorf("profile %q configures deschedule extension point of non-existing plugins: %v", config.Name, sets.New(config.Plugins.Deschedule.Enabled...).Difference(pi.deschedule))
 	}
@@ -247,15 +364,22 @@ func NewProfile(config api.DeschedulerProfile, reg pluginregistry.Registry, opts
 
 	handle := &handleImpl{
 		clientSet:                 hOpts.clientSet,
+		metricsClientSet:          hOpts.metricsClientSet,
 		getPodsAssignedToNodeFunc: hOpts.getPodsAssignedToNodeFunc,
 		sharedInformerFactory:     hOpts.sharedInformerFactory,
 		evictor: &evictorImpl{
-			profileName: config.Name,
-			podEvictor:  hOpts.podEvictor,
+			profileName:  config.Name,
+			podEvictor:   hOpts.podEvictor,
+			evictionPlan: hOpts.evictionPlan,
 		},
+		sorter:      &sorterImpl{},
+		pluginState: hOpts.pluginState,
+		ctx:         hOpts.ctx,
 	}
 
 	pluginNames := append(config.Plugins.Deschedule.Enabled, config.Plugins.Balance.Enabled...)
+	pluginNames = append(pluginNames, config.Plugins.PreSort.Enabled...)
+	pluginNames = append(pluginNames, config.Plugins.Sort.Enabled...)
 	pluginNames = append(pluginNames, config.Plugins.Filter.Enabled...)
 	pluginNames = append(pluginNames, config.Plugins.PreEvictionFilter.Enabled...)
 
@@ -293,8 +417,27 @@ func NewProfile(config api.DeschedulerProfile, reg pluginregistry.Registry, opts
 		preEvictionFilters = append(preEvictionFilters, plugins[pluginName].(preEvictionFilterPlugin).PreEvictionFilter)
 	}
 
+	preLessFuncs := []podutil.LessFunc{}
+	for _, pluginName := range config.Plugins.PreSort.Enabled {
+		pi.preSortPlugins = append(pi.preSortPlugins, plugins[pluginName].(frameworktypes.PreSortPlugin))
+		preLessFuncs = append(preLessFuncs, plugins[pluginName].(frameworktypes.PreSortPlugin).PreLess)
+	}
+
+	lessFuncs := []podutil.LessFunc{}
+	for _, pluginName := range config.Plugins.Sort.Enabled {
+		pi.sortPlugins = append(pi.sortPlugins, plugins[pluginName].(frameworktypes.SortPlugin))
+		lessFuncs = append(lessFuncs, plugins[pluginName].(frameworktypes.SortPlugin).Less)
+	}
+
 	handle.evictor.filter = podutil.WrapFilterFuncs(filters...)
 	handle.evictor.preEvictionFilter = podutil.WrapFilterFuncs(preEvictionFilters...)
+	// Without any sort plugin enabled the sorter keeps the order plugins produce on their own
+	if len(preLessFuncs) > 0 {
+		handle.sorter.preLess = podutil.WrapLessFuncs(preLessFuncs...)
+	}
+	if len(lessFuncs) > 0 {
+		handle.sorter.less = podutil.WrapLessFuncs(lessFuncs...)
+	}
 
 	return pi, nil
 }
@@ -354,3 +497,33 @@ func (d profileImpl) RunBalancePlugins(ctx context.Context, nodes []*v1.Node) *f
 		Err: fmt.Errorf("%v", aggrErr.Error()),
 	}
 }
+
+// EvictorVerdict is the outcome of a Filter or PreEvictionFilter plugin for a pod
+type EvictorVerdict struct {
+	Plugin         string
+	ExtensionPoint frameworktypes.ExtensionPoint
+	Passed         bool
+	// Checks are the outcomes of the individual checks of the plugin, if it reports them
+	Checks []frameworktypes.Check
+}
+
+// ExplainPod runs every Filter and PreEvictionFilter plugin of the profile against the pod,
+// unlike the evictor which stops at the first plugin rejecting it
+func (d profileImpl) ExplainPod(pod *v1.Pod) []EvictorVerdict {
+	verdicts := []EvictorVerdict{}
+	for _, pl := range d.filterPlugins {
+		verdict := EvictorVerdict{Plugin: pl.Name(), ExtensionPoint: frameworktypes.FilterExtensionPoint, Passed: pl.Filter(pod)}
+		if explainer, ok := pl.(frameworktypes.ExplainableEvictorPlugin); ok {
+			verdict.Checks = explainer.FilterChecks(pod)
+		}
+		verdicts = append(verdicts, verdict)
+	}
+	for _, pl := range d.preEvictionFilterPlugins {
+		verdict := EvictorVerdict{Plugin: pl.Name(), ExtensionPoint: frameworktypes.PreEvictionFilterExtensionPoint, Passed: pl.PreEvictionFilter(pod)}
+		if explainer, ok := pl.(frameworktypes.ExplainableEvictorPlugin); ok {
+			verdict.Checks = explainer.PreEvictionFilterChecks(pod)
+		}
+		verdicts = append(verdicts, verdict)
+	}
+	return verdicts
+}
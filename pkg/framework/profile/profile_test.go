@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -23,6 +24,7 @@ import (
 	fakeplugin "github.com/amit3512/descheduler_policy_master/pkg/framework/fake/plugin"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/pluginregistry"
 	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/defaultevictor"
+	"github.com/amit3512/descheduler_policy_master/pkg/framework/plugins/podsorting"
 	frameworktypes "github.com/amit3512/descheduler_policy_master/pkg/framework/types"
 	"github.com/amit3512/descheduler_policy_master/pkg/utils"
 	testutils "github.com/amit3512/descheduler_policy_master/test"
@@ -694,3 +696,135 @@ func TestProfileExtensionPointOrdering(t *testing.T) {
 		t.Errorf("check for balance invocation order failed. Results are not deep equal. mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestProfileSortExtensionPoints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	n1 := testutils.BuildTestNode("n1", 2000, 3000, 10, nil)
+	nodes := []*v1.Node{n1}
+
+	now := time.Now()
+	p1 := testutils.BuildTestPod("p1", 100, 0, n1.Name, func(pod *v1.Pod) {
+		testutils.SetPodPriority(pod, 100)
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-1 * time.Hour))
+	})
+	p2 := testutils.BuildTestPod("p2", 100, 0, n1.Name, func(pod *v1.Pod) {
+		testutils.SetPodPriority(pod, 0)
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-1 * time.Hour))
+	})
+	p3 := testutils.BuildTestPod("p3", 100, 0, n1.Name, func(pod *v1.Pod) {
+		testutils.SetPodPriority(pod, 0)
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+	})
+
+	tests := []struct {
+		name            string
+		preSort         []string
+		sort            []string
+		expectedPreSort []string
+		expectedSort    []string
+		expectedErr     bool
+	}{
+		{
+			name:            "no sort plugins keep the order",
+			expectedPreSort: []string{"p1", "p2", "p3"},
+			expectedSort:    []string{"p1", "p2", "p3"},
+		},
+		{
+			name:            "sort plugins compose in the enabled order",
+			preSort:         []string{podsorting.SortByAgePluginName},
+			sort:            []string{podsorting.SortByPriorityPluginName, podsorting.SortByAgePluginName},
+			expectedPreSort: []string{"p3", "p1", "p2"},
+			expectedSort:    []string{"p3", "p2", "p1"},
+		},
+		{
+			name:        "plugin not implementing the sort extension point",
+			sort:        []string{"FakePlugin"},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var preSorted, sorted []string
+			fakePlugin := fakeplugin.FakePlugin{}
+			fakePlugin.AddReactor(string(frameworktypes.DescheduleExtensionPoint), func(action fakeplugin.Action) (handled, filter bool, err error) {
+				if dAction, ok := action.(fakeplugin.DescheduleAction); ok {
+					pods := []*v1.Pod{p1, p2, p3}
+					dAction.Handle().Sorter().PreSort(pods)
+					preSorted = podNames(pods)
+					pods = []*v1.Pod{p1, p2, p3}
+					dAction.Handle().Sorter().Sort(pods)
+					sorted = podNames(pods)
+					return true, false, nil
+				}
+				return false, false, nil
+			})
+
+			pluginregistry.PluginRegistry = pluginregistry.NewRegistry()
+			pluginregistry.Register(
+				"FakePlugin",
+				fakeplugin.NewPluginFncFromFake(&fakePlugin),
+				&fakeplugin.FakePlugin{},
+				&fakeplugin.FakePluginArgs{},
+				fakeplugin.ValidateFakePluginArgs,
+				fakeplugin.SetDefaults_FakePluginArgs,
+				pluginregistry.PluginRegistry,
+			)
+			pluginregistry.Register(podsorting.SortByPriorityPluginName, podsorting.NewSortByPriority, &podsorting.SortByPriority{}, &podsorting.PodSortingArgs{}, nil, podsorting.SetDefaults_PodSortingArgs, pluginregistry.PluginRegistry)
+			pluginregistry.Register(podsorting.SortByAgePluginName, podsorting.NewSortByAge, &podsorting.SortByAge{}, &podsorting.PodSortingArgs{}, nil, podsorting.SetDefaults_PodSortingArgs, pluginregistry.PluginRegistry)
+
+			client := fakeclientset.NewSimpleClientset(n1, p1, p2, p3)
+			sharedInformerFactory := informers.NewSharedInformerFactory(client, 0)
+			eventBroadcaster, eventRecorder := utils.GetRecorderAndBroadcaster(ctx, client)
+			defer eventBroadcaster.Shutdown()
+
+			prfl, err := NewProfile(
+				api.DeschedulerProfile{
+					Name: "strategy-test-profile",
+					PluginConfigs: []api.PluginConfig{
+						{Name: "FakePlugin", Args: &fakeplugin.FakePluginArgs{}},
+						{Name: podsorting.SortByPriorityPluginName, Args: &podsorting.PodSortingArgs{}},
+						{Name: podsorting.SortByAgePluginName, Args: &podsorting.PodSortingArgs{}},
+					},
+					Plugins: api.Plugins{
+						PreSort:    api.PluginSet{Enabled: test.preSort},
+						Sort:       api.PluginSet{Enabled: test.sort},
+						Deschedule: api.PluginSet{Enabled: []string{"FakePlugin"}},
+					},
+				},
+				pluginregistry.PluginRegistry,
+				WithClientSet(client),
+				WithSharedInformerFactory(sharedInformerFactory),
+				WithPodEvictor(evictions.NewPodEvictor(client, eventRecorder, nil)),
+			)
+			if test.expectedErr {
+				if err == nil {
+					t.Fatalf("expected the profile creation to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unable to create profile: %v", err)
+			}
+
+			prfl.RunDeschedulePlugins(ctx, nodes)
+
+			if diff := cmp.Diff(test.expectedPreSort, preSorted); diff != "" {
+				t.Errorf("unexpected pre-sort order (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expectedSort, sorted); diff != "" {
+				t.Errorf("unexpected sort order (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func podNames(pods []*v1.Pod) []string {
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	return names
+}
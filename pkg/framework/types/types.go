@@ -18,10 +18,12 @@ package types
 
 import (
 	"context"
+	"sync"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
 	clientset "k8s.io/client-go/kubernetes"
+	metricsclient "k8s.io/metrics/pkg/client/clientset/versioned"
 
 	"github.com/amit3512/descheduler_policy_master/pkg/descheduler/evictions"
 	podutil "github.com/amit3512/descheduler_policy_master/pkg/descheduler/pod"
@@ -33,9 +35,57 @@ import (
 type Handle interface {
 	// ClientSet returns a kubernetes clientSet.
 	ClientSet() clientset.Interface
+	// MetricsClientSet returns a client of the metrics.k8s.io API, nil when not available.
+	MetricsClientSet() metricsclient.Interface
 	Evictor() Evictor
 	GetPodsAssignedToNodeFunc() podutil.GetPodsAssignedToNodeFunc
 	SharedInformerFactory() informers.SharedInformerFactory
+	// Sorter returns the sorter composed from the PreSort and Sort plugins of the profile.
+	Sorter() Sorter
+	// PluginState returns the state the plugins of the profile keep across descheduling cycles.
+	PluginState() *PluginState
+	// Context returns the context of the descheduling cycle, cancelled when the descheduler stops,
+	// for the calls of the extension points not given a context, e.g. the evictor filters.
+	Context() context.Context
+}
+
+// PluginState keeps the state plugins carry over from one descheduling cycle to the next,
+// the plugins being built anew every cycle. The descheduler keeps one per profile, holding
+// the state of every plugin under its name. A nil PluginState keeps no state, every plugin
+// then starts from a new state.
+type PluginState struct {
+	mu     sync.Mutex
+	states map[string]interface{}
+}
+
+// NewPluginState returns an empty PluginState
+func NewPluginState() *PluginState {
+	return &PluginState{states: make(map[string]interface{})}
+}
+
+// LoadOrStore returns the state of the plugin, the state built by newState when the plugin has none yet
+func (ps *PluginState) LoadOrStore(pluginName string, newState func() interface{}) interface{} {
+	if ps == nil {
+		return newState()
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	state, ok := ps.states[pluginName]
+	if !ok {
+		state = newState()
+		ps.states[pluginName] = state
+	}
+	return state
+}
+
+// Delete forgets the state of the plugin
+func (ps *PluginState) Delete(pluginName string) {
+	if ps == nil {
+		return
+	}
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.states, pluginName)
 }
 
 // Evictor defines an interface for filtering and evicting pods
@@ -47,6 +97,23 @@ type Evictor interface {
 	PreEvictionFilter(*v1.Pod) bool
 	// Evict evicts a pod (no pre-check performed)
 	Evict(context.Context, *v1.Pod, evictions.EvictOptions) error
+	// CanEvict returns the limit error evicting all the pods would run into, nil when none
+	CanEvict([]*v1.Pod) error
+	// Planning tells whether the pods are only proposed to an eviction plan, the proposed pods
+	// are evicted once all the plugins ran, if they rank high enough
+	Planning() bool
+}
+
+// Sorter defines an interface for ordering pods
+// while abstracting away the specific sort plugins enabled in a profile.
+// Both methods sort in place and are stable, so plugins can apply their own
+// ordering first and have it preserved as a tie-break. With no plugin
+// enabled for an extension point the order of the pods is left untouched.
+type Sorter interface {
+	// PreSort sorts pods before they are processed
+	PreSort([]*v1.Pod)
+	// Sort sorts pods right before they are evicted
+	Sort([]*v1.Pod)
 }
 
 // Status describes result of an extension point invocation
@@ -71,6 +138,18 @@ type BalancePlugin interface {
 	Balance(ctx context.Context, nodes []*v1.Node) *Status
 }
 
+// PreSortPlugin defines an extension point for sorting pods before they are processed
+type PreSortPlugin interface {
+	Plugin
+	PreLess(*v1.Pod, *v1.Pod) bool
+}
+
+// SortPlugin defines an extension point for sorting pods right before they are evicted
+type SortPlugin interface {
+	Plugin
+	Less(*v1.Pod, *v1.Pod) bool
+}
+
 // EvictorPlugin defines extension points for a general evictor behavior
 // Even though we name this plugin interface EvictorPlugin, it does not actually evict anything,
 // This plugin is only meant to customize other actions (extension points) of the evictor,
@@ -81,9 +160,28 @@ type EvictorPlugin interface {
 	PreEvictionFilter(pod *v1.Pod) bool
 }
 
+// Check is the outcome of a single named check of an evictor plugin
+type Check struct {
+	Name string
+	// Err is the reason the pod failed the check, nil when it passed
+	Err error
+}
+
+// ExplainableEvictorPlugin is an EvictorPlugin able to report the outcome
+// of each of its checks, e.g. to tell why a pod is not evictable
+type ExplainableEvictorPlugin interface {
+	EvictorPlugin
+	// FilterChecks returns the checks behind the Filter extension point
+	FilterChecks(pod *v1.Pod) []Check
+	// PreEvictionFilterChecks returns the checks behind the PreEvictionFilter extension point
+	PreEvictionFilterChecks(pod *v1.Pod) []Check
+}
+
 type ExtensionPoint string
 
 const (
+	PreSortExtensionPoint           ExtensionPoint = "PreSort"
+	SortExtensionPoint              ExtensionPoint = "Sort"
 	DescheduleExtensionPoint        ExtensionPoint = "Deschedule"
 	BalanceExtensionPoint           ExtensionPoint = "Balance"
 	FilterExtensionPoint            ExtensionPoint = "Filter"